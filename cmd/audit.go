@@ -0,0 +1,52 @@
+package main
+
+import (
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/storage"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// runAudit implements "audit verify [flags]", which checks the hash chain
+// of the audit log and reports the first entry that breaks it. It only
+// reads, so it is safe to run while the server is up.
+func runAudit(args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		fmt.Fprintf(os.Stderr, "usage: %s audit verify [flags]\n", os.Args[0])
+		return fmt.Errorf("unknown or missing subcommand")
+	}
+
+	fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	loader := config.NewLoader(fs)
+	if err := fs.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(cfg))
+
+	result, err := storage.NewAuditLog(cfg.DataDir).Verify()
+	if err != nil {
+		return err
+	}
+	if !result.OK {
+		fmt.Printf("%d entries check out; line %d breaks the chain: %s\n", result.Entries, result.BrokenAt, result.Reason)
+		if result.Head != "" {
+			fmt.Printf("last good hash: %s\n", result.Head)
+		}
+		return errors.New("audit log chain is broken")
+	}
+	fmt.Printf("%d entries, chain intact\n", result.Entries)
+	if result.Head != "" {
+		fmt.Printf("head: %s\n", result.Head)
+	}
+	return nil
+}
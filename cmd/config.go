@@ -0,0 +1,37 @@
+package main
+
+import (
+	"LotterySystem/internal/config"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runConfig implements "config print [flags]", which shows the effective
+// configuration after the config file, environment and flags are applied.
+// Secrets are masked.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintf(os.Stderr, "usage: %s config print [flags]\n", os.Args[0])
+		return fmt.Errorf("unknown or missing subcommand")
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	loader := config.NewLoader(fs)
+	if err := fs.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cfg.Redacted())
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/export"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// runExport implements "export <dataset> [flags]": it reads the data files
+// directly and writes the dataset to stdout or -o. The config flags, such as
+// -data-dir, are accepted too.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	loader := config.NewLoader(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s export <%s> [flags]\n", os.Args[0], strings.Join(export.Datasets, "|"))
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "csv", "output format: csv or jsonl")
+	drawID := fs.String("draw", "", "only records of this draw")
+	from := fs.String("from", "", "start date (2006-01-02) or RFC 3339 timestamp")
+	to := fs.String("to", "", "end date, inclusive, or RFC 3339 timestamp")
+	out := fs.String("o", "", "write to this file instead of stdout")
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fs.Usage()
+		return fmt.Errorf("missing dataset")
+	}
+	dataset := args[0]
+	if !export.IsDataset(dataset) {
+		return fmt.Errorf("unknown dataset %q", dataset)
+	}
+	if err := fs.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	f, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	filter := models.ExportFilter{DrawID: *drawID}
+	if filter.From, err = export.ParseTime(*from, false); err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	if filter.To, err = export.ParseTime(*to, true); err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(cfg))
+
+	service, err := services.Open(cfg)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	buf := bufio.NewWriter(w)
+
+	if err := export.Write(buf, service, dataset, f, filter); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
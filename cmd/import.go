@@ -0,0 +1,106 @@
+package main
+
+import (
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/importer"
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// runImport implements "import <dataset> [flags]": it validates a CSV file
+// against the data files and, with -commit, writes it. Committing takes the
+// data directory's lock, so it refuses to run while the server is up.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	loader := config.NewLoader(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s import <%s> [flags]\n", os.Args[0], strings.Join(importer.Datasets, "|"))
+		fs.PrintDefaults()
+	}
+	commit := fs.Bool("commit", false, "write the rows; without it the file is only checked")
+	file := fs.String("f", "", "read from this file instead of stdin")
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fs.Usage()
+		return fmt.Errorf("missing dataset")
+	}
+	dataset := args[0]
+	if !importer.IsDataset(dataset) {
+		return fmt.Errorf("unknown dataset %q", dataset)
+	}
+	if err := fs.Parse(args[1:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(cfg))
+	if *commit {
+		lock, err := storage.LockDataDir(cfg.DataDir)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
+	}
+	service, err := services.Open(cfg)
+	if err != nil {
+		return err
+	}
+	report, err := importer.Run(service, cliActor(), dataset, r, *commit)
+	if err != nil {
+		var serr *services.Error
+		if errors.As(err, &serr) {
+			for field, problem := range serr.Fields {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", field, problem)
+			}
+		}
+		return err
+	}
+
+	for _, e := range report.Errors {
+		if e.Field != "" {
+			fmt.Printf("line %d: %s: %s\n", e.Line, e.Field, e.Message)
+		} else {
+			fmt.Printf("line %d: %s\n", e.Line, e.Message)
+		}
+	}
+	switch {
+	case len(report.Errors) > 0:
+		return fmt.Errorf("%d of %d rows have errors, nothing was imported", report.Rows-report.Valid, report.Rows)
+	case report.Committed:
+		fmt.Printf("imported %d %s\n", len(report.Created), dataset)
+	default:
+		fmt.Printf("all %d rows are valid; run again with -commit to import them\n", report.Rows)
+	}
+	return nil
+}
+
+// cliActor names whoever runs a command, for the audit log.
+func cliActor() string {
+	if u, err := user.Current(); err == nil {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/export"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"LotterySystem/internal/utils"
+	"errors"
+	"fmt"
+	"io"
+	"os/user"
+	"strings"
+)
+
+// backend is what the commands run against: the data directory or a
+// server's API.
+type backend interface {
+	ListDraws(f models.DrawFilter) (models.Page[models.Draw], error)
+	CreateDraw() (models.Draw, error)
+	ExecuteDraw(id string) (models.Draw, error)
+	CancelDraw(id string) (models.Draw, error)
+	ResettleDraw(id string) (models.Settlement, error)
+	GetSettlement(id string) (models.Settlement, error)
+	ReconcileDraw(id string) (models.Reconciliation, error)
+
+	ListTickets(f models.TicketFilter) (models.Page[models.Ticket], error)
+	// GetTicket takes a ticket ID or a serial number.
+	GetTicket(ref string) (models.Ticket, error)
+
+	ListUsers(f models.UserFilter) (models.Page[models.User], error)
+	GetUser(id string) (models.User, error)
+	CreateUser(username, password string) (models.User, error)
+	AdjustBalance(id string, delta int, reason string) (models.User, error)
+	SetRole(id, role string) (models.User, error)
+
+	ListAudit(f models.AuditFilter) (models.Page[models.AuditEntry], error)
+	VerifyAudit() (models.AuditVerification, error)
+
+	Export(w io.Writer, dataset string, format export.Format, f models.ExportFilter) error
+
+	CheckIntegrity() (models.IntegrityReport, error)
+	RepairIntegrity(commit bool) (models.IntegrityReport, error)
+
+	// CreateBackup returns the new archive and the old ones pruned after it.
+	CreateBackup() (backup.Info, []backup.Info, error)
+	ListBackups() ([]backup.Info, error)
+
+	Close() error
+}
+
+// local works on the data files through the same service the server uses,
+// holding the data directory's lock so the server cannot start meanwhile.
+// Changes are recorded in the audit log as the operating system user's.
+type local struct {
+	service   *services.LotteryService
+	lock      *storage.DirLock
+	actor     string
+	backupDir string
+	retention backup.Retention
+}
+
+func openLocal(cfg config.Config) (*local, error) {
+	lock, err := storage.LockDataDir(cfg.DataDir)
+	if errors.Is(err, storage.ErrLocked) {
+		return nil, fmt.Errorf("%w; use -server to go through the running server", err)
+	} else if err != nil {
+		return nil, err
+	}
+	service, err := services.Open(cfg)
+	if err != nil {
+		lock.Unlock()
+		var serr *storage.SchemaError
+		if errors.As(err, &serr) {
+			err = fmt.Errorf("%w (starting the server or its migrate command does that)", err)
+		}
+		return nil, err
+	}
+
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor = "cli:" + u.Username
+	}
+	return &local{service: service, lock: lock, actor: actor, backupDir: cfg.BackupDir, retention: backup.ConfigRetention(cfg)}, nil
+}
+
+func (l *local) ListDraws(f models.DrawFilter) (models.Page[models.Draw], error) {
+	return l.service.QueryDraws(f)
+}
+
+func (l *local) CreateDraw() (models.Draw, error) {
+	return l.service.CreateDraw(l.actor)
+}
+
+func (l *local) ExecuteDraw(id string) (models.Draw, error) {
+	return l.service.ExecuteDraw(l.actor, id)
+}
+
+func (l *local) CancelDraw(id string) (models.Draw, error) {
+	return l.service.CancelDraw(l.actor, id)
+}
+
+func (l *local) ResettleDraw(id string) (models.Settlement, error) {
+	return l.service.ResettleDraw(l.actor, id)
+}
+
+func (l *local) GetSettlement(id string) (models.Settlement, error) {
+	return l.service.GetSettlement(id)
+}
+
+func (l *local) ReconcileDraw(id string) (models.Reconciliation, error) {
+	return l.service.ReconcileDraw(id)
+}
+
+func (l *local) ListTickets(f models.TicketFilter) (models.Page[models.Ticket], error) {
+	return l.service.QueryTickets(f)
+}
+
+func (l *local) GetTicket(ref string) (models.Ticket, error) {
+	if isTicketID(ref) {
+		return l.service.GetTicket(ref)
+	}
+	return l.service.GetTicketBySerial(ref)
+}
+
+func (l *local) ListUsers(f models.UserFilter) (models.Page[models.User], error) {
+	return l.service.QueryUsers(f)
+}
+
+func (l *local) GetUser(id string) (models.User, error) {
+	return l.service.GetUser(id)
+}
+
+func (l *local) CreateUser(username, password string) (models.User, error) {
+	return l.service.RegisterUser(username, password)
+}
+
+func (l *local) AdjustBalance(id string, delta int, reason string) (models.User, error) {
+	return l.service.AdjustBalance(l.actor, id, delta, reason)
+}
+
+func (l *local) SetRole(id, role string) (models.User, error) {
+	return l.service.SetUserRole(l.actor, id, role)
+}
+
+func (l *local) ListAudit(f models.AuditFilter) (models.Page[models.AuditEntry], error) {
+	return l.service.QueryAudit(f)
+}
+
+func (l *local) VerifyAudit() (models.AuditVerification, error) {
+	return l.service.VerifyAudit()
+}
+
+func (l *local) Export(w io.Writer, dataset string, format export.Format, f models.ExportFilter) error {
+	return export.Write(w, l.service, dataset, format, f)
+}
+
+func (l *local) CheckIntegrity() (models.IntegrityReport, error) {
+	return l.service.CheckIntegrity(), nil
+}
+
+func (l *local) RepairIntegrity(commit bool) (models.IntegrityReport, error) {
+	return l.service.RepairIntegrity(l.actor, !commit)
+}
+
+// CreateBackup snapshots the files as this process loaded them. Each file
+// is consistent on its own, but a server changing them meanwhile can leave
+// them out of step, so back up a running server through -server.
+func (l *local) CreateBackup() (backup.Info, []backup.Info, error) {
+	return l.service.CreateBackup(l.actor, l.backupDir, l.retention)
+}
+
+func (l *local) ListBackups() ([]backup.Info, error) {
+	return backup.List(l.backupDir)
+}
+
+// Close reports changes that did not reach the disk and releases the data
+// directory. Every change is written as it is made, so there is nothing
+// left to flush.
+func (l *local) Close() error {
+	defer l.lock.Unlock()
+	var errs []error
+	for _, st := range l.service.StorageStatus() {
+		if st.WriteError != "" {
+			errs = append(errs, fmt.Errorf("%s not written: %s", st.File, st.WriteError))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isTicketID(ref string) bool {
+	return strings.HasPrefix(ref, utils.TicketIDPrefix+"_")
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/models"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	serverEnv = "LOTTERYCTL_SERVER"
+	tokenEnv  = "LOTTERYCTL_TOKEN"
+)
+
+// cli holds what every command shares: the flags, the positional arguments
+// and, once opened, the backend.
+type cli struct {
+	name    string
+	verb    string
+	params  []string
+	fs      *flag.FlagSet
+	loader  *config.Loader
+	server  *string
+	token   *string
+	output  *string
+	args    []string
+	cfg     config.Config
+	backend backend
+	out     io.Writer
+}
+
+func newCLI(group, verb, params string) *cli {
+	name := group + " " + verb
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	c := &cli{
+		name:   name,
+		verb:   verb,
+		params: strings.Fields(params),
+		fs:     fs,
+		loader: config.NewLoader(fs),
+		server: fs.String("server", os.Getenv(serverEnv), "base URL of a running server, e.g. http://localhost:8080 ($"+serverEnv+"); without it the data directory is used"),
+		token:  fs.String("token", os.Getenv(tokenEnv), "bearer token for -server, the admin token or an admin's session token ($"+tokenEnv+"; default the configured admin token)"),
+		output: fs.String("o", "table", "output format: table or json"),
+		out:    os.Stdout,
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: lotteryctl %s [flags]\n", strings.TrimSpace(name+" "+params))
+		fs.PrintDefaults()
+	}
+	return c
+}
+
+// parse takes the positional arguments, which come first, and then the
+// flags. A negative number is an argument, not a flag.
+func (c *cli) parse(args []string) error {
+	for len(args) > 0 && !isFlag(args[0]) {
+		c.args = append(c.args, args[0])
+		args = args[1:]
+	}
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	c.args = append(c.args, c.fs.Args()...)
+	if len(c.args) != len(c.params) {
+		c.fs.Usage()
+		return fmt.Errorf("%s takes %d argument(s), got %d", c.name, len(c.params), len(c.args))
+	}
+	if *c.output != "table" && *c.output != "json" {
+		return fmt.Errorf("-o must be table or json")
+	}
+	return nil
+}
+
+func isFlag(arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	_, err := strconv.Atoi(arg)
+	return err != nil
+}
+
+func (c *cli) open() error {
+	cfg, err := c.loader.Load()
+	if err != nil {
+		return err
+	}
+	c.cfg = cfg
+	level, _ := cfg.SlogLevel()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	if *c.server != "" {
+		token := *c.token
+		if token == "" {
+			token = cfg.AdminToken
+		}
+		c.backend = newRemote(*c.server, token)
+		return nil
+	}
+	c.backend, err = openLocal(cfg)
+	return err
+}
+
+func (c *cli) arg(i int) string {
+	return c.args[i]
+}
+
+// listFlags adds the paging flags and returns a function building the
+// options from them.
+func (c *cli) listFlags() func() models.ListOptions {
+	sortBy := c.fs.String("sort", "", "field to sort by")
+	asc := c.fs.Bool("asc", false, "sort in ascending order (default newest or largest first)")
+	limit := c.fs.Int("limit", 50, "how many to show, 0 for all")
+	cursor := c.fs.String("cursor", "", "continue from a previous listing's next cursor")
+	return func() models.ListOptions {
+		return models.ListOptions{Sort: *sortBy, Desc: !*asc, Limit: *limit, Cursor: *cursor}
+	}
+}
+
+// timeFlags adds -from and -to and returns a function parsing them.
+func (c *cli) timeFlags(what string) func() (from, to time.Time, err error) {
+	from := c.fs.String("from", "", "earliest "+what+", a date (2006-01-02) or RFC 3339 timestamp")
+	to := c.fs.String("to", "", "latest "+what+", inclusive")
+	return func() (time.Time, time.Time, error) {
+		f, err := parseTime(*from, false)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("-from: %w", err)
+		}
+		t, err := parseTime(*to, true)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("-to: %w", err)
+		}
+		return f, t, nil
+	}
+}
+
+func parseTime(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, errors.New("must be a date (2006-01-02) or RFC 3339 timestamp")
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// show prints v as JSON, or as a table of the header and rows.
+func (c *cli) show(v any, header []string, rows ...[]string) error {
+	if *c.output == "json" {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// showPage prints a page with one row per item. In table mode the cursor
+// for the next page goes to stderr so the table itself stays clean.
+func showPage[T any](c *cli, page models.Page[T], header []string, row func(T) []string) error {
+	rows := make([][]string, 0, len(page.Items))
+	for _, it := range page.Items {
+		rows = append(rows, row(it))
+	}
+	if err := c.show(page, header, rows...); err != nil {
+		return err
+	}
+	if *c.output == "table" && page.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "more results: -cursor %s\n", page.NextCursor)
+	}
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func formatNumbers(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = fmt.Sprint(n)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
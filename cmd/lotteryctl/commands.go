@@ -0,0 +1,538 @@
+package main
+
+import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/export"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	drawHeader   = []string{"ID", "STATUS", "WINNING NUMBERS", "DRAW DATE"}
+	ticketHeader = []string{"ID", "SERIAL", "USER", "DRAW", "NUMBERS", "MATCHES", "PRICE", "STATUS", "BOUGHT"}
+	userHeader   = []string{"ID", "USERNAME", "ROLE", "BALANCE", "CREATED"}
+	auditHeader  = []string{"SEQ", "TIME", "ACTOR", "ACTION", "TARGET"}
+)
+
+func drawRow(d models.Draw) []string {
+	return []string{d.ID, d.Status, formatNumbers(d.WinningNumbers), formatTime(d.DrawDate)}
+}
+
+func ticketRow(t models.Ticket) []string {
+	status := t.Status
+	if status == "" {
+		status = "active"
+	}
+	return []string{t.ID, t.Serial, t.UserID, t.DrawID, formatNumbers(t.Numbers),
+		strconv.Itoa(t.Matches), strconv.Itoa(t.Price), status, formatTime(t.CreatedAt)}
+}
+
+func userRow(u models.User) []string {
+	role := u.Role
+	if role == "" {
+		role = models.RolePlayer
+	}
+	return []string{u.ID, u.Username, role, strconv.Itoa(u.Balance), formatTime(u.CreatedAt)}
+}
+
+func auditRow(e models.AuditEntry) []string {
+	return []string{strconv.Itoa(e.Seq), formatTime(e.Time), e.Actor, e.Action, orDash(e.Target)}
+}
+
+func listDraws(c *cli) func() error {
+	status := c.fs.String("status", "", "only draws with this status: pending, drawing, completed or cancelled")
+	times := c.timeFlags("draw date")
+	opts := c.listFlags()
+	return func() error {
+		f := models.DrawFilter{ListOptions: opts(), Status: *status}
+		var err error
+		if f.From, f.To, err = times(); err != nil {
+			return err
+		}
+		page, err := c.backend.ListDraws(f)
+		if err != nil {
+			return err
+		}
+		return showPage(c, page, drawHeader, drawRow)
+	}
+}
+
+func createDraw(c *cli) func() error {
+	return func() error {
+		draw, err := c.backend.CreateDraw()
+		if err != nil {
+			return err
+		}
+		return c.show(draw, drawHeader, drawRow(draw))
+	}
+}
+
+func executeDraw(c *cli) func() error {
+	return func() error {
+		draw, err := c.backend.ExecuteDraw(c.arg(0))
+		if err != nil {
+			return err
+		}
+		return c.show(draw, drawHeader, drawRow(draw))
+	}
+}
+
+func cancelDraw(c *cli) func() error {
+	return func() error {
+		draw, err := c.backend.CancelDraw(c.arg(0))
+		if err != nil {
+			return err
+		}
+		return c.show(draw, drawHeader, drawRow(draw))
+	}
+}
+
+var settlementHeader = []string{"DRAW", "RUN", "STATUS", "PROCESSED", "WINNERS", "AWARDED", "CREDITED", "FAILED", "LAST ERROR"}
+
+func settlementRow(st models.Settlement) []string {
+	return []string{st.DrawID, strconv.Itoa(st.Run), st.Status, fmt.Sprintf("%d/%d", st.Processed, st.Tickets),
+		strconv.Itoa(st.Winners), strconv.Itoa(st.Awarded), strconv.Itoa(st.Credited), strconv.Itoa(st.Failed), orDash(st.LastError)}
+}
+
+func resettleDraw(c *cli) func() error {
+	return func() error {
+		st, err := c.backend.ResettleDraw(c.arg(0))
+		if err != nil {
+			return err
+		}
+		return c.show(st, settlementHeader, settlementRow(st))
+	}
+}
+
+func getSettlement(c *cli) func() error {
+	return func() error {
+		st, err := c.backend.GetSettlement(c.arg(0))
+		if err != nil {
+			return err
+		}
+		return c.show(st, settlementHeader, settlementRow(st))
+	}
+}
+
+var discrepancyHeader = []string{"TICKET", "PRIZE", "KIND", "DETAIL"}
+
+// reconcileDraw fails when the draw does not balance, so scripts can rely
+// on the exit status.
+func reconcileDraw(c *cli) func() error {
+	return func() error {
+		rec, err := c.backend.ReconcileDraw(c.arg(0))
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(rec.Discrepancies))
+		for i, d := range rec.Discrepancies {
+			rows[i] = []string{d.TicketID, orDash(d.PrizeID), d.Kind, d.Detail}
+		}
+		if err := c.show(rec, discrepancyHeader, rows...); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d ticket(s): %d/%d winners, payout expected %d, awarded %d, credited %d\n",
+			rec.Tickets, rec.ActualWinners, rec.ExpectedWinners, rec.ExpectedPayout, rec.AwardedPayout, rec.CreditedPayout)
+		if !rec.Balanced {
+			return fmt.Errorf("%d discrepancy(ies) found", len(rec.Discrepancies))
+		}
+		return nil
+	}
+}
+
+func listTickets(c *cli) func() error {
+	userID := c.fs.String("user", "", "only tickets of this user ID")
+	drawID := c.fs.String("draw", "", "only tickets in this draw")
+	status := c.fs.String("status", "", "only active or cancelled tickets")
+	minMatches := c.fs.Int("min-matches", 0, "only tickets matching at least this many numbers")
+	times := c.timeFlags("purchase time")
+	opts := c.listFlags()
+	return func() error {
+		f := models.TicketFilter{
+			ListOptions: opts(),
+			UserID:      *userID,
+			DrawID:      *drawID,
+			Status:      *status,
+			MinMatches:  *minMatches,
+		}
+		var err error
+		if f.From, f.To, err = times(); err != nil {
+			return err
+		}
+		page, err := c.backend.ListTickets(f)
+		if err != nil {
+			return err
+		}
+		return showPage(c, page, ticketHeader, ticketRow)
+	}
+}
+
+func getTicket(c *cli) func() error {
+	return func() error {
+		ticket, err := c.backend.GetTicket(c.arg(0))
+		if err != nil {
+			return err
+		}
+		return c.show(ticket, ticketHeader, ticketRow(ticket))
+	}
+}
+
+func listUsers(c *cli) func() error {
+	username := c.fs.String("username", "", "only users whose name contains this")
+	role := c.fs.String("role", "", "only users with this role: player or admin")
+	opts := c.listFlags()
+	return func() error {
+		page, err := c.backend.ListUsers(models.UserFilter{ListOptions: opts(), Username: *username, Role: *role})
+		if err != nil {
+			return err
+		}
+		return showPage(c, page, userHeader, userRow)
+	}
+}
+
+func getUser(c *cli) func() error {
+	return func() error {
+		user, err := c.backend.GetUser(c.arg(0))
+		if err != nil {
+			return err
+		}
+		return c.show(user, userHeader, userRow(user))
+	}
+}
+
+func createUser(c *cli) func() error {
+	password := c.fs.String("password", "", "the new user's password; read from stdin when empty")
+	return func() error {
+		pw := *password
+		if pw == "" {
+			fmt.Fprint(os.Stderr, "password: ")
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			pw = strings.TrimRight(line, "\r\n")
+		}
+		user, err := c.backend.CreateUser(c.arg(0), pw)
+		if err != nil {
+			return err
+		}
+		return c.show(user, userHeader, userRow(user))
+	}
+}
+
+func adjustBalance(c *cli) func() error {
+	reason := c.fs.String("reason", "", "why the balance is changed, kept in the audit log (required)")
+	return func() error {
+		delta, err := strconv.Atoi(c.arg(1))
+		if err != nil {
+			return fmt.Errorf("delta %q is not a whole number", c.arg(1))
+		}
+		user, err := c.backend.AdjustBalance(c.arg(0), delta, *reason)
+		if err != nil {
+			return err
+		}
+		return c.show(user, userHeader, userRow(user))
+	}
+}
+
+func setRole(c *cli) func() error {
+	return func() error {
+		user, err := c.backend.SetRole(c.arg(0), c.arg(1))
+		if err != nil {
+			return err
+		}
+		return c.show(user, userHeader, userRow(user))
+	}
+}
+
+func listAudit(c *cli) func() error {
+	actor := c.fs.String("actor", "", "only entries by this actor, e.g. admin or user:<id>")
+	action := c.fs.String("action", "", "only entries of this action, e.g. draw.execute")
+	target := c.fs.String("target", "", "only entries about this record ID")
+	opts := c.listFlags()
+	return func() error {
+		page, err := c.backend.ListAudit(models.AuditFilter{ListOptions: opts(), Actor: *actor, Action: *action, Target: *target})
+		if err != nil {
+			return err
+		}
+		return showPage(c, page, auditHeader, auditRow)
+	}
+}
+
+// verifyAudit fails when the chain is broken, so scripts can rely on the
+// exit status.
+func verifyAudit(c *cli) func() error {
+	return func() error {
+		v, err := c.backend.VerifyAudit()
+		if err != nil {
+			return err
+		}
+		status := "intact"
+		if !v.OK {
+			status = "broken"
+		}
+		err = c.show(v, []string{"CHAIN", "ENTRIES", "BROKEN AT LINE", "REASON", "HEAD"},
+			[]string{status, strconv.Itoa(v.Entries), orDash(intOrEmpty(v.BrokenAt)), orDash(v.Reason), orDash(v.Head)})
+		if err == nil && !v.OK {
+			err = errors.New("audit log chain is broken")
+		}
+		return err
+	}
+}
+
+func intOrEmpty(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// exportDataset ignores -o: the export is CSV or JSON Lines as -format says.
+func exportDataset(c *cli) func() error {
+	format := c.fs.String("format", "csv", "export format: csv or jsonl")
+	drawID := c.fs.String("draw", "", "only records of this draw")
+	times := c.timeFlags("record time")
+	out := c.fs.String("out", "", "write to this file instead of stdout")
+	return func() error {
+		ef, err := export.ParseFormat(*format)
+		if err != nil {
+			return err
+		}
+		f := models.ExportFilter{DrawID: *drawID}
+		if f.From, f.To, err = times(); err != nil {
+			return err
+		}
+
+		w := io.Writer(os.Stdout)
+		if *out != "" {
+			file, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		buf := bufio.NewWriter(w)
+		if err := c.backend.Export(buf, c.verb, ef, f); err != nil {
+			return err
+		}
+		return buf.Flush()
+	}
+}
+
+var (
+	backupHeader   = []string{"NAME", "CREATED", "SIZE"}
+	manifestHeader = []string{"FILE", "RECORDS", "SIZE", "SHA256"}
+)
+
+func backupRow(b backup.Info) []string {
+	return []string{b.Name, formatTime(b.CreatedAt), strconv.FormatInt(b.Size, 10)}
+}
+
+func manifestRow(e backup.FileEntry) []string {
+	return []string{e.Name, strconv.Itoa(e.Records), strconv.FormatInt(e.Size, 10), e.SHA256[:min(12, len(e.SHA256))]}
+}
+
+// createBackup prints the new archive and, on stderr, the ones pruned.
+func createBackup(c *cli) func() error {
+	return func() error {
+		info, pruned, err := c.backend.CreateBackup()
+		if err != nil {
+			return err
+		}
+		for _, p := range pruned {
+			fmt.Fprintf(os.Stderr, "pruned %s\n", p.Name)
+		}
+		return c.show(info, backupHeader, backupRow(info))
+	}
+}
+
+func listBackups(c *cli) func() error {
+	return func() error {
+		infos, err := c.backend.ListBackups()
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(infos))
+		for i, b := range infos {
+			rows[i] = backupRow(b)
+		}
+		return c.show(infos, backupHeader, rows...)
+	}
+}
+
+// archiveCheck is what verify and restore print.
+type archiveCheck struct {
+	Manifest backup.Manifest `json:"manifest"`
+	Problems []string        `json:"problems"`
+}
+
+// showArchive prints the archive's manifest and then any problems.
+func showArchive(c *cli, a *backup.Archive, problems []string) error {
+	rows := make([][]string, len(a.Manifest.Files))
+	for i, e := range a.Manifest.Files {
+		rows[i] = manifestRow(e)
+	}
+	if problems == nil {
+		problems = []string{}
+	}
+	if err := c.show(archiveCheck{Manifest: a.Manifest, Problems: problems}, manifestHeader, rows...); err != nil {
+		return err
+	}
+	if *c.output == "table" {
+		for _, p := range problems {
+			fmt.Fprintf(c.out, "problem: %s\n", p)
+		}
+	}
+	return nil
+}
+
+// archivePath takes a path or the name of an archive in the backup
+// directory.
+func archivePath(c *cli) string {
+	path := c.arg(0)
+	if _, err := os.Stat(path); err != nil && !strings.ContainsRune(path, filepath.Separator) {
+		return filepath.Join(c.cfg.BackupDir, path)
+	}
+	return path
+}
+
+// verifyBackup reads a local archive, even with -server, and fails when it
+// could not be restored.
+func verifyBackup(c *cli) func() error {
+	return func() error {
+		path := archivePath(c)
+		a, err := backup.Read(path)
+		if err != nil {
+			return err
+		}
+		problems := a.Check()
+		if err := showArchive(c, a, problems); err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s failed %d check(s)", filepath.Base(path), len(problems))
+		}
+		return nil
+	}
+}
+
+// restoreBackup only checks the archive unless -commit is given. The files
+// it replaces are kept in a pre-restore directory next to the backups, and
+// the restore is recorded at the end of the restored audit log.
+func restoreBackup(c *cli) func() error {
+	commit := c.fs.Bool("commit", false, "replace the data; without it the archive is only checked")
+	return func() error {
+		if *c.server != "" {
+			return errors.New("restore works on the data directory; stop the server and run it without -server")
+		}
+		// The data directory's lock, taken when it was opened, keeps the
+		// server from running while the files are replaced.
+
+		path := archivePath(c)
+		if !*commit {
+			a, err := backup.Read(path)
+			if err != nil {
+				return err
+			}
+			problems := a.Check()
+			if err := showArchive(c, a, problems); err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%s failed %d check(s)", filepath.Base(path), len(problems))
+			}
+			fmt.Fprintln(os.Stderr, "archive is valid; run again with -commit to restore it")
+			return nil
+		}
+
+		keepDir := filepath.Join(c.cfg.BackupDir, "pre-restore-"+time.Now().UTC().Format("20060102T150405Z"))
+		a, problems, err := backup.Restore(path, c.cfg.DataDir, keepDir)
+		if a != nil {
+			if serr := showArchive(c, a, problems); serr != nil && err == nil {
+				err = serr
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		l, ok := c.backend.(*local)
+		if !ok {
+			return nil
+		}
+		_, err = storage.NewAuditLog(c.cfg.DataDir).Append(l.actor, "backup.restore", filepath.Base(path), nil,
+			map[string]any{"created_at": a.Manifest.CreatedAt, "previous_files": keepDir})
+		if err != nil {
+			return fmt.Errorf("restored, but not recorded in the audit log: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "restored %s; the replaced files are in %s\n", filepath.Base(path), keepDir)
+		return nil
+	}
+}
+
+var issueHeader = []string{"KIND", "RECORD", "DETAIL", "FIX"}
+
+func issueRow(i models.IntegrityIssue) []string {
+	fix := orDash(i.Fix)
+	if i.Repaired {
+		fix = "repaired: " + i.Fix
+	}
+	return []string{i.Kind, i.Record, i.Detail, fix}
+}
+
+func showIntegrity(c *cli, report models.IntegrityReport) error {
+	rows := make([][]string, len(report.Issues))
+	for i, issue := range report.Issues {
+		rows[i] = issueRow(issue)
+	}
+	if err := c.show(report, issueHeader, rows...); err != nil {
+		return err
+	}
+	fixable := 0
+	for _, issue := range report.Issues {
+		if issue.Fix != "" && !issue.Repaired {
+			fixable++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d issue(s), %d repaired, %d more with a fix\n", len(report.Issues), report.Repaired, fixable)
+	return nil
+}
+
+// checkIntegrity fails when there are issues, so scripts can rely on the
+// exit status.
+func checkIntegrity(c *cli) func() error {
+	return func() error {
+		report, err := c.backend.CheckIntegrity()
+		if err != nil {
+			return err
+		}
+		if err := showIntegrity(c, report); err != nil {
+			return err
+		}
+		if len(report.Issues) > 0 {
+			return errors.New("integrity issues found")
+		}
+		return nil
+	}
+}
+
+func repairIntegrity(c *cli) func() error {
+	commit := c.fs.Bool("commit", false, "apply the fixes; without it they are only listed")
+	return func() error {
+		report, err := c.backend.RepairIntegrity(*commit)
+		if err != nil {
+			return err
+		}
+		return showIntegrity(c, report)
+	}
+}
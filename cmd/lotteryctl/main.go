@@ -0,0 +1,141 @@
+// Command lotteryctl runs operations tasks against the lottery: opening,
+// drawing and cancelling draws, searching tickets and users, adjusting
+// balances and roles, checking the audit log and the records' integrity,
+// exporting data and backing it up and restoring it.
+//
+// With -server it talks to a running server's API and needs the admin token
+// or an admin's session token. Without it, it opens the data directory
+// directly, like the server's own import and export commands, taking the
+// lock the server holds on it: that only works while the server is
+// stopped, since the server would not see the changes and would overwrite
+// them.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command is one "<group> <verb>" pair. setup registers the command's own
+// flags and returns the function that runs it once they are parsed.
+type command struct {
+	args  string
+	about string
+	setup func(c *cli) func() error
+}
+
+var commands = map[string]map[string]command{
+	"draws": {
+		"list":       {"", "list draws", listDraws},
+		"create":     {"", "open a new draw", createDraw},
+		"execute":    {"<draw-id>", "draw the winning numbers and settle", executeDraw},
+		"cancel":     {"<draw-id>", "cancel an open draw and refund its tickets", cancelDraw},
+		"resettle":   {"<draw-id>", "settle a completed draw again, applying only what is missing", resettleDraw},
+		"settlement": {"<draw-id>", "show the progress of a draw's latest settlement", getSettlement},
+		"reconcile":  {"<draw-id>", "compare a draw's expected and actual payouts", reconcileDraw},
+	},
+	"tickets": {
+		"list": {"", "search tickets", listTickets},
+		"get":  {"<ticket-id|serial>", "show one ticket", getTicket},
+	},
+	"users": {
+		"list":    {"", "search users", listUsers},
+		"get":     {"<user-id>", "show one user", getUser},
+		"create":  {"<username>", "register a user", createUser},
+		"balance": {"<user-id> <delta>", "credit (positive) or debit (negative) a balance", adjustBalance},
+		"role":    {"<user-id> <player|admin>", "set a user's role", setRole},
+	},
+	"audit": {
+		"list":   {"", "list audit log entries", listAudit},
+		"verify": {"", "check the audit log's hash chain", verifyAudit},
+	},
+	"backup": {
+		"create":  {"", "back up the data and prune old backups", createBackup},
+		"list":    {"", "list backups, newest first", listBackups},
+		"verify":  {"<archive>", "check a backup's checksums and cross-references", verifyBackup},
+		"restore": {"<archive>", "replace the data with a backup; the server must be stopped", restoreBackup},
+	},
+	"integrity": {
+		"check":  {"", "look for dangling references and inconsistent records", checkIntegrity},
+		"repair": {"", "fix the integrity issues that have a safe fix", repairIntegrity},
+	},
+	"export": {
+		"tickets": {"", "export tickets", exportDataset},
+		"prizes":  {"", "export prizes", exportDataset},
+		"draws":   {"", "export draws", exportDataset},
+		"ledger":  {"", "export ledger entries", exportDataset},
+	},
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "lotteryctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 2 || strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[1], "-") {
+		usage()
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "help") {
+			return nil
+		}
+		return errors.New("missing command")
+	}
+	verbs, ok := commands[args[0]]
+	if !ok {
+		usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	cmd, ok := verbs[args[1]]
+	if !ok {
+		usage()
+		return fmt.Errorf("unknown command %q", args[0]+" "+args[1])
+	}
+
+	c := newCLI(args[0], args[1], cmd.args)
+	runCmd := cmd.setup(c)
+	if err := c.parse(args[2:]); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := c.open(); err != nil {
+		return err
+	}
+	err := runCmd()
+	if cerr := c.backend.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: lotteryctl <command> [arguments] [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	groups := make([]string, 0, len(commands))
+	for g := range commands {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	for _, g := range groups {
+		verbs := make([]string, 0, len(commands[g]))
+		for v := range commands[g] {
+			verbs = append(verbs, v)
+		}
+		sort.Strings(verbs)
+		for _, v := range verbs {
+			cmd := commands[g][v]
+			fmt.Fprintf(os.Stderr, "  %-40s %s\n", strings.TrimSpace(g+" "+v+" "+cmd.args), cmd.about)
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run a command with -h for its flags. With -server (or $LOTTERYCTL_SERVER) the")
+	fmt.Fprintln(os.Stderr, "running server is used; otherwise the data directory is opened directly, which")
+	fmt.Fprintln(os.Stderr, "needs the server to be stopped.")
+}
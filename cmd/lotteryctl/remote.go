@@ -0,0 +1,348 @@
+package main
+
+import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/export"
+	"LotterySystem/internal/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const apiV1 = "/api/v1"
+
+// remote calls a running server's API. Changes are recorded in the audit
+// log as whoever the token belongs to.
+type remote struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+func newRemote(server, token string) *remote {
+	return &remote{
+		base:  strings.TrimRight(server, "/") + apiV1,
+		token: token,
+		// Executing a draw with a live reveal or exporting everything can
+		// take a while.
+		client: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// apiError is the server's error body.
+type apiError struct {
+	Status  int               `json:"-"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields"`
+}
+
+func (e *apiError) Error() string {
+	msg := fmt.Sprintf("%s (%d %s)", e.Message, e.Status, e.Code)
+	if len(e.Fields) > 0 {
+		fields := make([]string, 0, len(e.Fields))
+		for f, problem := range e.Fields {
+			fields = append(fields, f+": "+problem)
+		}
+		sort.Strings(fields)
+		msg += ": " + strings.Join(fields, "; ")
+	}
+	return msg
+}
+
+// request sends the call and returns the response once it has a success
+// status; the caller closes the body.
+func (r *remote) request(method, path string, query url.Values, body any) (*http.Response, error) {
+	u := r.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, rd)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		apiErr := &apiError{Status: resp.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Code = strings.ToLower(strings.ReplaceAll(http.StatusText(resp.StatusCode), " ", "_"))
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+func (r *remote) call(method, path string, query url.Values, body, out any) (http.Header, error) {
+	resp, err := r.request(method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("%s %s: reading response: %w", method, path, err)
+	}
+	return resp.Header, nil
+}
+
+// list fetches a page. A zero limit means everything, which the API does
+// not offer, so the pages are followed to the end.
+func list[T any](r *remote, path string, query url.Values, opts models.ListOptions) (models.Page[T], error) {
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	if !opts.Desc {
+		query.Set("order", "asc")
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	cursor := opts.Cursor
+
+	page := models.Page[T]{Items: []T{}}
+	for {
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		var items []T
+		header, err := r.call(http.MethodGet, path, query, nil, &items)
+		if err != nil {
+			return models.Page[T]{}, err
+		}
+		page.Items = append(page.Items, items...)
+		cursor = header.Get("X-Next-Cursor")
+		if opts.Limit > 0 || cursor == "" {
+			page.NextCursor = cursor
+			return page, nil
+		}
+	}
+}
+
+func setTime(q url.Values, name string, t time.Time) {
+	if !t.IsZero() {
+		q.Set(name, t.Format(time.RFC3339Nano))
+	}
+}
+
+func setInt(q url.Values, name string, n int) {
+	if n != 0 {
+		q.Set(name, strconv.Itoa(n))
+	}
+}
+
+func setString(q url.Values, name, v string) {
+	if v != "" {
+		q.Set(name, v)
+	}
+}
+
+func (r *remote) ListDraws(f models.DrawFilter) (models.Page[models.Draw], error) {
+	q := url.Values{}
+	setString(q, "status", f.Status)
+	setTime(q, "from", f.From)
+	setTime(q, "to", f.To)
+	return list[models.Draw](r, "/draws", q, f.ListOptions)
+}
+
+func (r *remote) CreateDraw() (models.Draw, error) {
+	var draw models.Draw
+	_, err := r.call(http.MethodPost, "/draws", nil, nil, &draw)
+	return draw, err
+}
+
+func (r *remote) ExecuteDraw(id string) (models.Draw, error) {
+	var draw models.Draw
+	_, err := r.call(http.MethodPost, "/draws/"+url.PathEscape(id)+"/execute", nil, nil, &draw)
+	return draw, err
+}
+
+func (r *remote) CancelDraw(id string) (models.Draw, error) {
+	var draw models.Draw
+	_, err := r.call(http.MethodPost, "/draws/"+url.PathEscape(id)+"/cancel", nil, nil, &draw)
+	return draw, err
+}
+
+func (r *remote) ResettleDraw(id string) (models.Settlement, error) {
+	var st models.Settlement
+	_, err := r.call(http.MethodPost, "/draws/"+url.PathEscape(id)+"/resettle", nil, nil, &st)
+	return st, err
+}
+
+func (r *remote) GetSettlement(id string) (models.Settlement, error) {
+	var st models.Settlement
+	_, err := r.call(http.MethodGet, "/draws/"+url.PathEscape(id)+"/settlement", nil, nil, &st)
+	return st, err
+}
+
+func (r *remote) ReconcileDraw(id string) (models.Reconciliation, error) {
+	var rec models.Reconciliation
+	_, err := r.call(http.MethodGet, "/draws/"+url.PathEscape(id)+"/reconciliation", nil, nil, &rec)
+	return rec, err
+}
+
+func (r *remote) ListTickets(f models.TicketFilter) (models.Page[models.Ticket], error) {
+	q := url.Values{}
+	setString(q, "user_id", f.UserID)
+	setString(q, "draw_id", f.DrawID)
+	setString(q, "status", f.Status)
+	setInt(q, "min_matches", f.MinMatches)
+	setTime(q, "from", f.From)
+	setTime(q, "to", f.To)
+	views, err := list[ticketView](r, "/tickets", q, f.ListOptions)
+	if err != nil {
+		return models.Page[models.Ticket]{}, err
+	}
+	page := models.Page[models.Ticket]{Items: make([]models.Ticket, len(views.Items)), NextCursor: views.NextCursor}
+	for i, v := range views.Items {
+		page.Items[i] = v.Ticket
+	}
+	return page, nil
+}
+
+// ticketView is how the API returns tickets, with their result.
+type ticketView struct {
+	Ticket models.Ticket `json:"ticket"`
+}
+
+func (r *remote) GetTicket(ref string) (models.Ticket, error) {
+	if isTicketID(ref) {
+		var view ticketView
+		_, err := r.call(http.MethodGet, "/tickets/"+url.PathEscape(ref), nil, nil, &view)
+		return view.Ticket, err
+	}
+
+	// Serial lookups only include the ticket for an admin.
+	var view struct {
+		Ticket *ticketView `json:"ticket"`
+	}
+	if _, err := r.call(http.MethodGet, "/serials/"+url.PathEscape(ref), nil, nil, &view); err != nil {
+		return models.Ticket{}, err
+	}
+	if view.Ticket == nil {
+		return models.Ticket{}, errors.New("the server only shows the ticket for a serial to an admin; pass -token")
+	}
+	return view.Ticket.Ticket, nil
+}
+
+func (r *remote) ListUsers(f models.UserFilter) (models.Page[models.User], error) {
+	q := url.Values{}
+	setString(q, "username", f.Username)
+	setString(q, "role", f.Role)
+	return list[models.User](r, "/users", q, f.ListOptions)
+}
+
+func (r *remote) GetUser(id string) (models.User, error) {
+	var user models.User
+	_, err := r.call(http.MethodGet, "/users/"+url.PathEscape(id), nil, nil, &user)
+	return user, err
+}
+
+func (r *remote) CreateUser(username, password string) (models.User, error) {
+	var user models.User
+	_, err := r.call(http.MethodPost, "/users", nil, map[string]string{"username": username, "password": password}, &user)
+	return user, err
+}
+
+func (r *remote) AdjustBalance(id string, delta int, reason string) (models.User, error) {
+	var user models.User
+	_, err := r.call(http.MethodPost, "/users/"+url.PathEscape(id)+"/balance-adjustments", nil,
+		map[string]any{"delta": delta, "reason": reason}, &user)
+	return user, err
+}
+
+func (r *remote) SetRole(id, role string) (models.User, error) {
+	var user models.User
+	_, err := r.call(http.MethodPut, "/users/"+url.PathEscape(id)+"/role", nil, map[string]string{"role": role}, &user)
+	return user, err
+}
+
+func (r *remote) ListAudit(f models.AuditFilter) (models.Page[models.AuditEntry], error) {
+	q := url.Values{}
+	setString(q, "actor", f.Actor)
+	setString(q, "action", f.Action)
+	setString(q, "target", f.Target)
+	return list[models.AuditEntry](r, "/audit", q, f.ListOptions)
+}
+
+func (r *remote) VerifyAudit() (models.AuditVerification, error) {
+	var v models.AuditVerification
+	_, err := r.call(http.MethodGet, "/audit/verify", nil, nil, &v)
+	return v, err
+}
+
+func (r *remote) Export(w io.Writer, dataset string, format export.Format, f models.ExportFilter) error {
+	q := url.Values{"format": {string(format)}}
+	setString(q, "draw_id", f.DrawID)
+	setTime(q, "from", f.From)
+	setTime(q, "to", f.To)
+	resp, err := r.request(http.MethodGet, "/exports/"+url.PathEscape(dataset), q, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (r *remote) CheckIntegrity() (models.IntegrityReport, error) {
+	var report models.IntegrityReport
+	_, err := r.call(http.MethodGet, "/integrity", nil, nil, &report)
+	return report, err
+}
+
+func (r *remote) RepairIntegrity(commit bool) (models.IntegrityReport, error) {
+	mode := "dry-run"
+	if commit {
+		mode = "commit"
+	}
+	var report models.IntegrityReport
+	_, err := r.call(http.MethodPost, "/integrity/repairs", url.Values{"mode": {mode}}, nil, &report)
+	return report, err
+}
+
+func (r *remote) CreateBackup() (backup.Info, []backup.Info, error) {
+	var resp struct {
+		Backup backup.Info   `json:"backup"`
+		Pruned []backup.Info `json:"pruned"`
+	}
+	_, err := r.call(http.MethodPost, "/backups", nil, nil, &resp)
+	return resp.Backup, resp.Pruned, err
+}
+
+func (r *remote) ListBackups() ([]backup.Info, error) {
+	var infos []backup.Info
+	_, err := r.call(http.MethodGet, "/backups", nil, nil, &infos)
+	return infos, err
+}
+
+func (r *remote) Close() error {
+	return nil
+}
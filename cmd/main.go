@@ -1,41 +1,287 @@
 package main
 
 import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/grpcapi"
 	"LotterySystem/internal/handlers"
 	"LotterySystem/internal/services"
 	"LotterySystem/internal/storage"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
 )
 
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// readHeaderTimeout bounds reading request headers even when ReadTimeout is
+// turned off, so idle clients cannot hold connections open.
+const readHeaderTimeout = 5 * time.Second
+
 func main() {
-	userRepo := storage.NewUserRepository()
-	drawRepo := storage.NewDrawRepository()
-	ticketRepo := storage.NewTicketRepository()
-	prizeRepo := storage.NewPrizeRepository()
-
-	service := services.NewLotteryService(
-		userRepo,
-		drawRepo,
-		ticketRepo,
-		prizeRepo,
-	)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			if err := runExport(os.Args[2:]); err != nil {
+				log.Fatalf("export: %v", err)
+			}
+			return
+		case "import":
+			if err := runImport(os.Args[2:]); err != nil {
+				log.Fatalf("import: %v", err)
+			}
+			return
+		case "config":
+			if err := runConfig(os.Args[2:]); err != nil {
+				log.Fatalf("config: %v", err)
+			}
+			return
+		case "audit":
+			if err := runAudit(os.Args[2:]); err != nil {
+				log.Fatalf("audit: %v", err)
+			}
+			return
+		case "migrate":
+			if err := runMigrate(os.Args[2:]); err != nil {
+				log.Fatalf("migrate: %v", err)
+			}
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	loader := config.NewLoader(fs)
+	fs.Parse(os.Args[1:])
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(newLogger(cfg))
+
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		slog.Error("data dir not created", "dir", cfg.DataDir, "err", err)
+		os.Exit(1)
+	}
+	// The lock is held until the process exits.
+	if _, err := storage.LockDataDir(cfg.DataDir); err != nil {
+		slog.Error("data dir not locked", "dir", cfg.DataDir, "err", err)
+		os.Exit(1)
+	}
+	if _, err := migrateData(cfg, services.SystemActor, false, true); err != nil {
+		slog.Error("data not migrated", "dir", cfg.DataDir, "err", err)
+		os.Exit(1)
+	}
+
+	if err := serve(cfg); err != nil {
+		slog.Error("stopped with errors", "err", err)
+		os.Exit(1)
+	}
+}
+
+// newLogger builds the logger from the log settings. It writes to stderr,
+// and the standard log package is routed through it once it is the default.
+func newLogger(cfg config.Config) *slog.Logger {
+	level, _ := cfg.SlogLevel()
+	opts := &slog.HandlerOptions{Level: level}
+	if cfg.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// serve runs the HTTP and gRPC servers until SIGINT or SIGTERM, then shuts
+// down in order: stop accepting requests and drain the ones in flight, stop
+// the background workers, and flush the data files. Everything after the
+// signal shares cfg.ShutdownTimeout.
+func serve(cfg config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	service, err := services.Open(cfg)
+	if err != nil {
+		return err
+	}
+	service.RegisterMetrics()
+	service.ReopenInterruptedDraws(services.SystemActor)
+	service.ResumeSettlements(services.SystemActor)
+
+	if cfg.ReceiptKey == "" {
+		slog.Warn("receipt key not set, receipts will not verify after restart")
+	}
+	if cfg.AuthKey == "" {
+		slog.Warn("auth key not set, sessions will not survive a restart")
+	}
+
+	webhookRepo := storage.NewWebhookRepository(cfg.DataDir)
+	deliveryRepo := storage.NewDeliveryRepository(cfg.DataDir)
+	dispatcher := services.NewWebhookDispatcher(webhookRepo, deliveryRepo, service.Events())
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(workerCtx)
+	}()
 
 	userHandler := handlers.NewUserHandler(service)
 	ticketHandler := handlers.NewTicketHandler(service)
 	adminHandler := handlers.NewAdminHandler(service)
+	eventHandler := handlers.NewEventHandler(service)
+	exportHandler := handlers.NewExportHandler(service)
+	importHandler := handlers.NewImportHandler(service)
+	notificationHandler := handlers.NewNotificationHandler(service)
+	webhookHandler := handlers.NewWebhookHandler(service, dispatcher)
+	auditHandler := handlers.NewAuditHandler(service)
+	backupHandler := handlers.NewBackupHandler(service, cfg.BackupDir, backup.ConfigRetention(cfg))
+	integrityHandler := handlers.NewIntegrityHandler(service)
 
 	mux := http.NewServeMux()
 	userHandler.Register(mux)
 	ticketHandler.Register(mux)
 	adminHandler.Register(mux)
+	eventHandler.Register(mux)
+	exportHandler.Register(mux)
+	importHandler.Register(mux)
+	notificationHandler.Register(mux)
+	webhookHandler.Register(mux)
+	auditHandler.Register(mux)
+	backupHandler.Register(mux)
+	integrityHandler.Register(mux)
+	handlers.NewOpenAPIHandler(userHandler, ticketHandler, adminHandler, eventHandler, exportHandler, importHandler, notificationHandler, webhookHandler, auditHandler, backupHandler, integrityHandler).Register(mux)
+
+	handlers.RegisterMetrics(mux)
+	healthHandler := handlers.NewHealthHandler(service, dispatcher, cfg.DataDir, version)
+	healthHandler.Register(mux)
+
+	fileServer := http.FileServer(http.Dir(cfg.FrontendDir))
+	mux.Handle("/", fileServer)
+
+	idempotencyRepo := storage.NewIdempotencyRepository(cfg.DataDir)
+	idempotency := handlers.NewIdempotencyStore(service, idempotencyRepo, time.Duration(cfg.IdempotencyTTL))
+
+	httpServer := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           handlers.RequestLogger(handlers.MetricsMiddleware(mux, idempotency.Middleware(mux))),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       time.Duration(cfg.ReadTimeout),
+		WriteTimeout:      time.Duration(cfg.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.IdleTimeout),
+	}
+	// Shutdown waits for connections to go idle, which event streams never
+	// do, so they are ended as soon as it starts.
+	httpServer.RegisterOnShutdown(eventHandler.Shutdown)
+	httpServer.RegisterOnShutdown(notificationHandler.Shutdown)
+
+	httpLis, err := net.Listen("tcp", cfg.HTTPAddr)
+	if err != nil {
+		return fmt.Errorf("http listen on %s: %w", cfg.HTTPAddr, err)
+	}
+	grpcLis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		httpLis.Close()
+		return fmt.Errorf("grpc listen on %s: %w", cfg.GRPCAddr, err)
+	}
+	grpcAPI := grpcapi.NewServer(service)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.UnaryLogger, grpcAPI.UnaryAuth),
+		grpc.ChainStreamInterceptor(grpcapi.StreamLogger),
+	)
+	grpcAPI.Register(grpcServer)
+
+	// A server that fails on its own takes the process down the same way a
+	// signal does.
+	serveErr := make(chan error, 2)
+	go func() {
+		if err := httpServer.Serve(httpLis); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("http: %w", err)
+		}
+	}()
+	go func() {
+		if err := grpcServer.Serve(grpcLis); err != nil {
+			serveErr <- fmt.Errorf("grpc: %w", err)
+		}
+	}()
+
+	baseURL := "http://" + cfg.HTTPAddr
+	if strings.HasPrefix(cfg.HTTPAddr, ":") {
+		baseURL = "http://localhost" + cfg.HTTPAddr
+	}
+
+	slog.Info("Lottery Imitation System started",
+		"http", cfg.HTTPAddr,
+		"grpc", cfg.GRPCAddr,
+		"user_panel", baseURL+"/index.html",
+		"admin_panel", baseURL+"/admin.html",
+		"data_dir", cfg.DataDir,
+		"version", version)
+
+	var failed error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	case failed = <-serveErr:
+		slog.Error("shutting down after server failure", "err", failed)
+	}
+	// A second signal kills the process instead of waiting for shutdown.
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
 
-	fs := http.FileServer(http.Dir("./internal/frontend"))
-	mux.Handle("/", fs)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("http requests still running, closing them", "timeout", cfg.ShutdownTimeout, "err", err)
+			httpServer.Close()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		grpcAPI.Shutdown()
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			slog.Warn("grpc calls still running, closing them", "timeout", cfg.ShutdownTimeout)
+			grpcServer.Stop()
+		}
+	}()
+	wg.Wait()
 
-	log.Println("Lottery Imitation System")
-	log.Println("User panel:  http://localhost:8080/index.html")
-	log.Println("Admin panel: http://localhost:8080/admin.html")
+	// Workers abort their outbound requests when cancelled, so this only
+	// waits for the save in progress.
+	stopWorkers()
+	workers.Wait()
 
-	log.Fatal(http.ListenAndServe(":8080", mux))
+	flushErr := errors.Join(service.Flush(), webhookRepo.Flush(), deliveryRepo.Flush(), idempotencyRepo.Flush())
+	if flushErr != nil {
+		flushErr = fmt.Errorf("flush data files: %w", flushErr)
+	}
+	if failed == nil && flushErr == nil {
+		slog.Info("stopped")
+	}
+	return errors.Join(failed, flushErr)
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/storage"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+)
+
+// runMigrate implements "migrate [flags]", which brings the data files to
+// the schema version this build writes. The server does the same when it
+// starts; this is for seeing what would change first, with -dry-run. It
+// refuses to migrate while the server is running.
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing anything")
+	noBackup := fs.Bool("no-backup", false, "do not back up the data files before migrating them")
+	loader := config.NewLoader(fs)
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(cfg))
+	if !*dryRun {
+		lock, err := storage.LockDataDir(cfg.DataDir)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
+	}
+
+	report, err := migrateData(cfg, cliActor(), *dryRun, !*noBackup)
+	for _, f := range report.Files {
+		fmt.Printf("%-26s version %d, current %d\n", f.File, f.Version, f.Current)
+	}
+	for _, s := range report.Steps {
+		verb := "changed"
+		if report.DryRun {
+			verb = "would change"
+		}
+		fmt.Printf("%s v%d: %s (%s %d)\n", s.File, s.Version, s.About, verb, s.Changed)
+	}
+	if err == nil && !report.Pending() {
+		fmt.Println("nothing to migrate")
+	}
+	return err
+}
+
+// migrateData migrates the data directory if any file is behind, backing
+// it up first unless backupFirst is false, and records the migration in
+// the audit log as actor's.
+func migrateData(cfg config.Config, actor string, dryRun, backupFirst bool) (storage.MigrationReport, error) {
+	report, err := storage.Migrate(cfg.DataDir, true)
+	if err != nil || dryRun || !report.Pending() {
+		return report, err
+	}
+
+	if backupFirst {
+		files, err := storage.ReadFiles(cfg.DataDir)
+		if err != nil {
+			return report, fmt.Errorf("backing up before migrating: %w", err)
+		}
+		info, err := backup.Create(cfg.BackupDir, files)
+		if err != nil {
+			return report, fmt.Errorf("backing up before migrating: %w", err)
+		}
+		slog.Info("data backed up before migrating", "archive", info.Path)
+	}
+
+	if report, err = storage.Migrate(cfg.DataDir, false); err != nil {
+		return report, err
+	}
+	for _, s := range report.Steps {
+		slog.Info("data migrated", "file", s.File, "version", s.Version, "changed", s.Changed, "about", s.About)
+	}
+	if _, err := storage.NewAuditLog(cfg.DataDir).Append(actor, "data.migrate", "", report.Files, report.Steps); err != nil {
+		slog.Error("migration not recorded in the audit log", "err", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixtureConfig copies the data files in the storage package's
+// testdata/name to a scratch data directory and returns a config using it.
+func fixtureConfig(t *testing.T, name string) config.Config {
+	t.Helper()
+	cfg := config.Default()
+	cfg.DataDir = t.TempDir()
+	cfg.BackupDir = t.TempDir()
+
+	src := filepath.Join("..", "internal", "storage", "testdata", name)
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cfg.DataDir, e.Name()), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cfg
+}
+
+func TestMigrateData(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		dryRun      bool
+		backupFirst bool
+		wantBackup  bool
+		wantMigrate bool
+	}{
+		{name: "v0 with backup", fixture: "v0", backupFirst: true, wantBackup: true, wantMigrate: true},
+		{name: "v1 with backup", fixture: "v1", backupFirst: true, wantBackup: true, wantMigrate: true},
+		{name: "v0 without backup", fixture: "v0", wantMigrate: true},
+		{name: "v0 dry run", fixture: "v0", dryRun: true, backupFirst: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fixtureConfig(t, tt.fixture)
+			files := []string{storage.UsersFile, storage.DrawsFile, storage.TicketsFile, storage.PrizesFile}
+			if tt.wantBackup {
+				// Settlements are backed up too when there are some.
+				settlements := []byte(`{"schema_version": 1, "records": {}}`)
+				if err := os.WriteFile(filepath.Join(cfg.DataDir, storage.SettlementsFile), settlements, 0644); err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, storage.SettlementsFile)
+			}
+			before := map[string][]byte{}
+			for _, name := range files {
+				data, err := os.ReadFile(filepath.Join(cfg.DataDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				before[name] = data
+			}
+
+			report, err := migrateData(cfg, "test", tt.dryRun, tt.backupFirst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Steps) == 0 {
+				t.Fatal("no migration steps reported")
+			}
+
+			archives, err := backup.List(cfg.BackupDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(archives) == 1; got != tt.wantBackup {
+				t.Fatalf("%d backups taken, want backup %v", len(archives), tt.wantBackup)
+			}
+			if tt.wantBackup {
+				archive, err := backup.Read(archives[0].Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for name, data := range before {
+					if !bytes.Equal(archive.File(name), data) {
+						t.Errorf("backup of %s is not the file as it was before migrating", name)
+					}
+				}
+			}
+
+			versions, err := storage.SchemaVersions(cfg.DataDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range versions {
+				if migrated := f.Version == f.Current; migrated != tt.wantMigrate {
+					t.Errorf("%s at version %d of %d, want migrated %v", f.File, f.Version, f.Current, tt.wantMigrate)
+				}
+			}
+
+			entries, err := storage.NewAuditLog(cfg.DataDir).Query(models.AuditFilter{Action: "data.migrate"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(entries.Items) == 1; got != tt.wantMigrate {
+				t.Errorf("%d data.migrate audit entries, want recorded %v", len(entries.Items), tt.wantMigrate)
+			}
+			if !tt.wantMigrate {
+				return
+			}
+
+			// Prizes paid before credits were recorded are not paid again.
+			service, err := services.Open(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			user, err := service.GetUser("u1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			st, err := service.ResettleDraw("test", "d1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.Credited != 0 {
+				t.Errorf("resettling credited %d prizes, want none", st.Credited)
+			}
+			if after, _ := service.GetUser("u1"); after.Balance != user.Balance {
+				t.Errorf("balance after resettling = %d, want %d", after.Balance, user.Balance)
+			}
+		})
+	}
+}
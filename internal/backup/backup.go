@@ -0,0 +1,347 @@
+// Package backup writes snapshots of the data directory to compressed,
+// timestamped archives, prunes old ones and restores them. An archive is a
+// gzipped tar holding manifest.json, which lists every file with its size,
+// record count and SHA-256, followed by the files themselves.
+package backup
+
+import (
+	"LotterySystem/internal/config"
+	"LotterySystem/internal/storage"
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatVersion is written to every manifest; Read refuses others.
+	FormatVersion = 1
+
+	manifestName = "manifest.json"
+	prefix       = "backup-"
+	suffix       = ".tar.gz"
+	stampLayout  = "20060102T150405.000Z"
+
+	// maxFileSize bounds one file when reading an archive back.
+	maxFileSize = 1 << 30
+)
+
+// required are the files every archive must have. The audit log is added
+// when the service has one.
+var required = []string{storage.UsersFile, storage.DrawsFile, storage.TicketsFile, storage.PrizesFile}
+
+type Manifest struct {
+	Format    int         `json:"format"`
+	CreatedAt time.Time   `json:"created_at"`
+	Files     []FileEntry `json:"files"`
+}
+
+type FileEntry struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// Info describes an archive in the backup directory.
+type Info struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"-"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Create writes files to a new archive in dir, named after the current
+// time. The archive only appears once it is complete.
+func Create(dir string, files []storage.SnapshotFile) (Info, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Info{}, err
+	}
+	// The name only keeps milliseconds, so neither does CreatedAt.
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	name := prefix + now.Format(stampLayout) + suffix
+	path := filepath.Join(dir, name)
+
+	tmp, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return Info{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Write(tmp, now, files); err != nil {
+		tmp.Close()
+		return Info{}, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return Info{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return Info{}, err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return Info{}, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Info{}, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{Name: name, Path: path, Size: fi.Size(), CreatedAt: now}, nil
+}
+
+// Write encodes files as an archive taken at t.
+func Write(w io.Writer, t time.Time, files []storage.SnapshotFile) error {
+	m := Manifest{Format: FormatVersion, CreatedAt: t, Files: make([]FileEntry, len(files))}
+	for i, f := range files {
+		sum := sha256.Sum256(f.Data)
+		m.Files[i] = FileEntry{Name: f.Name, Size: int64(len(f.Data)), Records: f.Records, SHA256: hex.EncodeToString(sum[:])}
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: t, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := add(manifestName, manifest); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := add(f.Name, f.Data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Archive is an archive read back and checked against its manifest.
+type Archive struct {
+	Manifest Manifest
+	Files    []storage.SnapshotFile
+}
+
+// File returns the named file's contents, or nil if the archive has none.
+func (a *Archive) File(name string) []byte {
+	for _, f := range a.Files {
+		if f.Name == name {
+			return f.Data
+		}
+	}
+	return nil
+}
+
+func (a *Archive) has(name string) bool {
+	for _, f := range a.Files {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Read opens an archive and checks that it holds exactly the files its
+// manifest lists, with the listed sizes and checksums. It does not look
+// inside the files; Check does.
+func Read(path string) (*Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: not a backup archive: %w", filepath.Base(path), err)
+	}
+	tr := tar.NewReader(gz)
+
+	a := &Archive{}
+	contents := map[string][]byte{}
+	for first := true; ; first = false {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("%s: unexpected entry %q", filepath.Base(path), hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if first {
+			if hdr.Name != manifestName {
+				return nil, fmt.Errorf("%s: does not start with %s", filepath.Base(path), manifestName)
+			}
+			if err := json.Unmarshal(data, &a.Manifest); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", filepath.Base(path), manifestName, err)
+			}
+			continue
+		}
+		if _, dup := contents[hdr.Name]; dup {
+			return nil, fmt.Errorf("%s: %q appears twice", filepath.Base(path), hdr.Name)
+		}
+		contents[hdr.Name] = data
+	}
+
+	m := a.Manifest
+	if m.Format != FormatVersion {
+		return nil, fmt.Errorf("%s: format %d, this version reads %d", filepath.Base(path), m.Format, FormatVersion)
+	}
+	for _, e := range m.Files {
+		data, ok := contents[e.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: %s is listed but missing", filepath.Base(path), e.Name)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != e.Size || hex.EncodeToString(sum[:]) != e.SHA256 {
+			return nil, fmt.Errorf("%s: %s does not match its checksum", filepath.Base(path), e.Name)
+		}
+		a.Files = append(a.Files, storage.SnapshotFile{Name: e.Name, Data: data, Records: e.Records})
+		delete(contents, e.Name)
+	}
+	for name := range contents {
+		return nil, fmt.Errorf("%s: %s is not listed in the manifest", filepath.Base(path), name)
+	}
+	for _, name := range required {
+		if !a.has(name) {
+			return nil, fmt.Errorf("%s: %s is missing", filepath.Base(path), name)
+		}
+	}
+	return a, nil
+}
+
+// List returns the archives in dir, newest first. A missing directory has
+// none.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Info{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	infos := []Info{}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		t, err := time.Parse(stampLayout, strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix))
+		if err != nil {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, Info{Name: name, Path: filepath.Join(dir, name), Size: fi.Size(), CreatedAt: t})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.After(infos[j].CreatedAt) })
+	return infos, nil
+}
+
+// Retention says which archives Prune keeps: the KeepLast newest, the
+// newest of each of the KeepDaily most recent days and the newest of each
+// of the KeepWeekly most recent ISO weeks that have one. Days and weeks are
+// in UTC. An archive kept by any rule stays. All zero keeps everything.
+type Retention struct {
+	KeepLast   int `json:"keep_last"`
+	KeepDaily  int `json:"keep_daily"`
+	KeepWeekly int `json:"keep_weekly"`
+}
+
+// ConfigRetention is the retention cfg asks for.
+func ConfigRetention(cfg config.Config) Retention {
+	return Retention{KeepLast: cfg.BackupKeepLast, KeepDaily: cfg.BackupKeepDaily, KeepWeekly: cfg.BackupKeepWeekly}
+}
+
+// Prune deletes the archives in dir that r does not keep and returns them.
+func Prune(dir string, r Retention) ([]Info, error) {
+	infos, err := List(dir)
+	if err != nil || r == (Retention{}) {
+		return nil, err
+	}
+
+	keep := make([]bool, len(infos))
+	for i := range infos {
+		if i < r.KeepLast {
+			keep[i] = true
+		}
+	}
+	keepNewest := func(n int, period func(time.Time) string) {
+		seen := map[string]bool{}
+		for i, info := range infos {
+			p := period(info.CreatedAt)
+			if seen[p] {
+				continue
+			}
+			if len(seen) == n {
+				return
+			}
+			seen[p] = true
+			keep[i] = true
+		}
+	}
+	keepNewest(r.KeepDaily, func(t time.Time) string { return t.Format(time.DateOnly) })
+	keepNewest(r.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+
+	var removed []Info
+	var errs []error
+	for i, info := range infos {
+		if keep[i] {
+			continue
+		}
+		if err := os.Remove(info.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, info)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// Restore reads the archive at path and, if it passes Check, replaces the
+// data files in dataDir with its contents, clearing what depended on the
+// data it replaces as storage.RestoreFiles does. The files it replaces are
+// copied to keepDir first. The problems Check found are returned either
+// way; the data is only replaced when there are none.
+func Restore(path, dataDir, keepDir string) (*Archive, []string, error) {
+	a, err := Read(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if problems := a.Check(); len(problems) > 0 {
+		return a, problems, fmt.Errorf("%s failed %d check(s), nothing was restored", filepath.Base(path), len(problems))
+	}
+	return a, nil, storage.RestoreFiles(dataDir, a.Files, keepDir)
+}
@@ -0,0 +1,169 @@
+package backup
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seed writes a user and a draw to a scratch data directory and returns
+// the directory and a snapshot of it.
+func seed(t *testing.T) (string, []storage.SnapshotFile) {
+	t.Helper()
+	dir := t.TempDir()
+	users := storage.NewUserRepository(dir)
+	draws := storage.NewDrawRepository(dir)
+	now := time.Now()
+	if err := users.Save(models.User{ID: "usr_1", Username: "alice", Balance: 1000, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := draws.Save(models.Draw{ID: "drw_1", WinningNumbers: []int{}, Status: "pending", DrawDate: now, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	audit := storage.NewAuditLog(dir)
+	if _, err := audit.Append("admin", "draw.create", "drw_1", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	files, err := storage.Snapshot(users, draws, storage.NewTicketRepository(dir), storage.NewPrizeRepository(dir), audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, files
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	dataDir, files := seed(t)
+	info, err := Create(t.TempDir(), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes after the backup, including a queued webhook delivery and an
+	// idempotent response that belong to them.
+	if err := storage.NewUserRepository(dataDir).Save(models.User{ID: "usr_2", Username: "bob", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.NewDeliveryRepository(dataDir).Save(models.WebhookDelivery{ID: "dlv_1", Status: "pending"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.NewIdempotencyRepository(dataDir).Put(models.IdempotentResponse{Key: "usr_2 k1", Status: 201, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	keepDir := t.TempDir()
+	a, problems, err := Restore(info.Path, dataDir, keepDir)
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("Restore = %v, %v", problems, err)
+	}
+	if len(a.Files) != len(files) {
+		t.Fatalf("archive has %d files, want %d", len(a.Files), len(files))
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(dataDir, f.Name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, f.Data) {
+			t.Errorf("%s is not as it was backed up", f.Name)
+		}
+	}
+	if _, err := storage.NewUserRepository(dataDir).GetByID("usr_2"); err == nil {
+		t.Error("a user created after the backup survived the restore")
+	}
+
+	if st := storage.NewDeliveryRepository(dataDir).Status(); st.Records != 0 {
+		t.Errorf("%d webhook deliveries after the restore, want none", st.Records)
+	}
+	if kept, err := os.ReadFile(filepath.Join(keepDir, "webhook_deliveries.json")); err != nil || !strings.Contains(string(kept), "dlv_1") {
+		t.Errorf("webhook deliveries were not kept aside (%v)", err)
+	}
+	if n := len(storage.NewIdempotencyRepository(dataDir).List()); n != 0 {
+		t.Errorf("%d idempotent responses after the restore, want none", n)
+	}
+	kept, err := os.ReadFile(filepath.Join(keepDir, storage.UsersFile))
+	if err != nil || !strings.Contains(string(kept), "usr_2") {
+		t.Errorf("the replaced users file was not kept (%v)", err)
+	}
+}
+
+// rewrite copies the archive at path with edit applied to each file's
+// contents, leaving the manifest as it was.
+func rewrite(t *testing.T, path string, edit func(name string, data []byte) []byte) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	out := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(out)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name != manifestName {
+			data = edit(hdr.Name, data)
+		}
+		hdr.Size = int64(len(data))
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(data)
+	}
+	f.Close()
+	tw.Close()
+	out.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadRejectsATamperedArchive(t *testing.T) {
+	_, files := seed(t)
+	info, err := Create(t.TempDir(), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(info.Path); err != nil {
+		t.Fatalf("untouched archive: %v", err)
+	}
+
+	rewrite(t, info.Path, func(name string, data []byte) []byte {
+		if name == storage.UsersFile {
+			return bytes.Replace(data, []byte("1000"), []byte("9000"), 1)
+		}
+		return data
+	})
+	_, err = Read(info.Path)
+	if err == nil || !strings.Contains(err.Error(), "does not match its checksum") {
+		t.Fatalf("Read of a tampered archive = %v, want a checksum error", err)
+	}
+
+	dataDir := t.TempDir()
+	if _, _, err := Restore(info.Path, dataDir, t.TempDir()); err == nil {
+		t.Fatal("a tampered archive was restored")
+	}
+	if entries, _ := os.ReadDir(dataDir); len(entries) != 0 {
+		t.Errorf("restoring a tampered archive wrote %d files", len(entries))
+	}
+}
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"LotterySystem/internal/integrity"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Check decodes the archive's files, runs the integrity checks on them and
+// verifies the audit log's hash chain. It returns one line per problem,
+// sorted.
+func (a *Archive) Check() []string {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	var (
+		users   map[string]models.User
+		draws   map[string]models.Draw
+		tickets map[string]models.Ticket
+		prizes  map[string]models.Prize
+	)
+	decode := func(name string, v any) bool {
+		data := a.File(name)
+		if len(data) == 0 {
+			return true
+		}
+		version, err := storage.DecodeFile(data, v)
+		if err != nil {
+			addf("%s: %v", name, err)
+			return false
+		}
+		// Older versions are migrated when the server starts.
+		if current := storage.SchemaVersion(name); version > current {
+			addf("%s has schema version %d, newer than the %d this build reads", name, version, current)
+			return false
+		}
+		return true
+	}
+	if !decode(storage.UsersFile, &users) || !decode(storage.DrawsFile, &draws) ||
+		!decode(storage.TicketsFile, &tickets) || !decode(storage.PrizesFile, &prizes) {
+		return problems
+	}
+
+	counts := map[string]int{
+		storage.UsersFile:   len(users),
+		storage.DrawsFile:   len(draws),
+		storage.TicketsFile: len(tickets),
+		storage.PrizesFile:  len(prizes),
+	}
+	for _, e := range a.Manifest.Files {
+		if n, ok := counts[e.Name]; ok && n != e.Records {
+			addf("%s: %d records, the manifest says %d", e.Name, n, e.Records)
+		}
+	}
+
+	for _, issue := range integrity.Check(integrity.Data{Users: users, Draws: draws, Tickets: tickets, Prizes: prizes}) {
+		addf("%s %s: %s", issue.Kind, issue.Record, issue.Detail)
+	}
+
+	if a.has(storage.AuditFile) {
+		v, err := storage.VerifyAudit(bytes.NewReader(a.File(storage.AuditFile)))
+		switch {
+		case err != nil:
+			addf("%s: %v", storage.AuditFile, err)
+		case !v.OK:
+			addf("%s: chain broken at line %d: %s", storage.AuditFile, v.BrokenAt, v.Reason)
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
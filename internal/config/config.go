@@ -0,0 +1,354 @@
+// Package config holds the server settings. Each setting is resolved from,
+// in increasing priority: its default, a JSON config file, a LOTTERY_*
+// environment variable and a command-line flag.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigEnv names the environment variable that points at the config file
+// when -config is not given.
+const ConfigEnv = "LOTTERY_CONFIG"
+
+type Config struct {
+	HTTPAddr    string `json:"http_addr"`
+	GRPCAddr    string `json:"grpc_addr"`
+	DataDir     string `json:"data_dir"`
+	FrontendDir string `json:"frontend_dir"`
+
+	// BackupDir is where backup archives are written. After each backup,
+	// all but the newest BackupKeepLast archives are deleted, except the
+	// newest of each of the last BackupKeepDaily days and BackupKeepWeekly
+	// weeks; all three zero keeps every archive.
+	BackupDir        string `json:"backup_dir"`
+	BackupKeepLast   int    `json:"backup_keep_last"`
+	BackupKeepDaily  int    `json:"backup_keep_daily"`
+	BackupKeepWeekly int    `json:"backup_keep_weekly"`
+
+	StartingBalance int      `json:"starting_balance"`
+	TicketCost      int      `json:"ticket_cost"`
+	CancelWindow    Duration `json:"cancel_window"`
+	LiveDrawDelay   Duration `json:"live_draw_delay"`
+	IdempotencyTTL  Duration `json:"idempotency_ttl"`
+
+	// HTTP server timeouts, and how long shutdown waits for requests and
+	// background work to finish before giving up on them.
+	ReadTimeout     Duration `json:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout"`
+	IdleTimeout     Duration `json:"idle_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+
+	// LogLevel is debug, info, warn or error; LogFormat is text or json.
+	LogLevel  string `json:"log_level"`
+	LogFormat string `json:"log_format"`
+
+	// Secrets. Random keys are generated when these are empty, which means
+	// receipts and sessions do not survive a restart.
+	ReceiptKey string `json:"receipt_key"`
+	AuthKey    string `json:"auth_key"`
+
+	// AdminToken is a bearer token for admin-only endpoints such as
+	// /debug/info. Users with the admin role can use their session token
+	// instead, so it may be left empty once one exists.
+	AdminToken string `json:"admin_token"`
+}
+
+func Default() Config {
+	return Config{
+		HTTPAddr:         ":8080",
+		GRPCAddr:         ":9090",
+		DataDir:          "data",
+		FrontendDir:      "./internal/frontend",
+		BackupDir:        "backups",
+		BackupKeepLast:   10,
+		BackupKeepDaily:  7,
+		BackupKeepWeekly: 4,
+		StartingBalance:  10000,
+		TicketCost:       100,
+		CancelWindow:     Duration(30 * time.Minute),
+		LiveDrawDelay:    0,
+		IdempotencyTTL:   Duration(24 * time.Hour),
+		ReadTimeout:      Duration(15 * time.Second),
+		WriteTimeout:     Duration(30 * time.Second),
+		IdleTimeout:      Duration(2 * time.Minute),
+		ShutdownTimeout:  Duration(15 * time.Second),
+		LogLevel:         "info",
+		LogFormat:        "text",
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var problems []string
+	if c.HTTPAddr == "" {
+		problems = append(problems, "http_addr is required")
+	}
+	if c.GRPCAddr == "" {
+		problems = append(problems, "grpc_addr is required")
+	}
+	if c.HTTPAddr != "" && c.HTTPAddr == c.GRPCAddr {
+		problems = append(problems, "http_addr and grpc_addr must differ")
+	}
+	if c.DataDir == "" {
+		problems = append(problems, "data_dir is required")
+	} else if fi, err := os.Stat(c.DataDir); err == nil && !fi.IsDir() {
+		problems = append(problems, "data_dir is not a directory")
+	}
+	if c.FrontendDir == "" {
+		problems = append(problems, "frontend_dir is required")
+	}
+	if c.BackupDir == "" {
+		problems = append(problems, "backup_dir is required")
+	} else if c.BackupDir == c.DataDir {
+		problems = append(problems, "backup_dir and data_dir must differ")
+	}
+	if c.BackupKeepLast < 0 || c.BackupKeepDaily < 0 || c.BackupKeepWeekly < 0 {
+		problems = append(problems, "backup_keep_last, backup_keep_daily and backup_keep_weekly must not be negative")
+	}
+	if c.StartingBalance < 0 {
+		problems = append(problems, "starting_balance must not be negative")
+	}
+	if c.TicketCost <= 0 {
+		problems = append(problems, "ticket_cost must be positive")
+	}
+	if c.CancelWindow < 0 {
+		problems = append(problems, "cancel_window must not be negative")
+	}
+	if c.LiveDrawDelay < 0 {
+		problems = append(problems, "live_draw_delay must not be negative")
+	}
+	if c.IdempotencyTTL <= 0 {
+		problems = append(problems, "idempotency_ttl must be positive")
+	}
+	if c.ReadTimeout < 0 {
+		problems = append(problems, "read_timeout must not be negative")
+	}
+	if c.WriteTimeout < 0 {
+		problems = append(problems, "write_timeout must not be negative")
+	}
+	if c.IdleTimeout < 0 {
+		problems = append(problems, "idle_timeout must not be negative")
+	}
+	if c.ShutdownTimeout <= 0 {
+		problems = append(problems, "shutdown_timeout must be positive")
+	}
+	if _, err := c.SlogLevel(); err != nil {
+		problems = append(problems, "log_level must be debug, info, warn or error")
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		problems = append(problems, "log_format must be text or json")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// SlogLevel parses LogLevel.
+func (c Config) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.LogLevel))
+	return level, err
+}
+
+// Redacted returns a copy that is safe to print, with secrets masked.
+func (c Config) Redacted() Config {
+	if c.ReceiptKey != "" {
+		c.ReceiptKey = "********"
+	}
+	if c.AuthKey != "" {
+		c.AuthKey = "********"
+	}
+	if c.AdminToken != "" {
+		c.AdminToken = "********"
+	}
+	return c
+}
+
+// Duration is a time.Duration written as a string such as "30m" in config
+// files and output.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30m\"")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// setting ties one Config field to its flag and environment variable.
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	field func(*Config) any
+}
+
+var settings = []setting{
+	{"http-addr", "LOTTERY_HTTP_ADDR", "HTTP listen address", func(c *Config) any { return &c.HTTPAddr }},
+	{"grpc-addr", "LOTTERY_GRPC_ADDR", "gRPC listen address", func(c *Config) any { return &c.GRPCAddr }},
+	{"data-dir", "LOTTERY_DATA_DIR", "directory holding the data files", func(c *Config) any { return &c.DataDir }},
+	{"frontend-dir", "LOTTERY_FRONTEND_DIR", "directory served at /", func(c *Config) any { return &c.FrontendDir }},
+	{"backup-dir", "LOTTERY_BACKUP_DIR", "directory backup archives are written to", func(c *Config) any { return &c.BackupDir }},
+	{"backup-keep-last", "LOTTERY_BACKUP_KEEP_LAST", "how many of the newest backups to keep", func(c *Config) any { return &c.BackupKeepLast }},
+	{"backup-keep-daily", "LOTTERY_BACKUP_KEEP_DAILY", "for how many days to keep each day's newest backup", func(c *Config) any { return &c.BackupKeepDaily }},
+	{"backup-keep-weekly", "LOTTERY_BACKUP_KEEP_WEEKLY", "for how many weeks to keep each week's newest backup", func(c *Config) any { return &c.BackupKeepWeekly }},
+	{"starting-balance", "LOTTERY_STARTING_BALANCE", "balance of newly registered users", func(c *Config) any { return &c.StartingBalance }},
+	{"ticket-cost", "LOTTERY_TICKET_COST", "price of a ticket", func(c *Config) any { return &c.TicketCost }},
+	{"cancel-window", "LOTTERY_CANCEL_WINDOW", "how long after purchase a ticket can be cancelled, 0 for until the draw closes", func(c *Config) any { return &c.CancelWindow }},
+	{"live-draw-delay", "LOTTERY_LIVE_DRAW_DELAY", "pause before each winning number is revealed", func(c *Config) any { return &c.LiveDrawDelay }},
+	{"idempotency-ttl", "LOTTERY_IDEMPOTENCY_TTL", "how long Idempotency-Key responses are kept", func(c *Config) any { return &c.IdempotencyTTL }},
+	{"read-timeout", "LOTTERY_READ_TIMEOUT", "longest time to read a request, 0 for no limit", func(c *Config) any { return &c.ReadTimeout }},
+	{"write-timeout", "LOTTERY_WRITE_TIMEOUT", "longest time to write a response, 0 for no limit; streams are exempt", func(c *Config) any { return &c.WriteTimeout }},
+	{"idle-timeout", "LOTTERY_IDLE_TIMEOUT", "how long an idle keep-alive connection stays open", func(c *Config) any { return &c.IdleTimeout }},
+	{"shutdown-timeout", "LOTTERY_SHUTDOWN_TIMEOUT", "how long shutdown waits for requests and workers", func(c *Config) any { return &c.ShutdownTimeout }},
+	{"log-level", "LOTTERY_LOG_LEVEL", "minimum level logged: debug, info, warn or error", func(c *Config) any { return &c.LogLevel }},
+	{"log-format", "LOTTERY_LOG_FORMAT", "log output: text or json", func(c *Config) any { return &c.LogFormat }},
+	{"receipt-key", "LOTTERY_RECEIPT_KEY", "secret for signing ticket receipts", func(c *Config) any { return &c.ReceiptKey }},
+	{"auth-key", "LOTTERY_AUTH_KEY", "secret for signing session tokens", func(c *Config) any { return &c.AuthKey }},
+	{"admin-token", "LOTTERY_ADMIN_TOKEN", "bearer token for admin-only endpoints, besides admin users' sessions", func(c *Config) any { return &c.AdminToken }},
+}
+
+func set(field any, value string) error {
+	switch p := field.(type) {
+	case *string:
+		*p = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*p = n
+	case *Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30m", value)
+		}
+		*p = Duration(d)
+	}
+	return nil
+}
+
+// Loader registers the config flags on a FlagSet and builds the Config once
+// the flags are parsed, so subcommands can accept them next to their own.
+type Loader struct {
+	file  string
+	flags map[string]string
+}
+
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{flags: map[string]string{}}
+	fs.StringVar(&l.file, "config", "", "JSON config file (default $"+ConfigEnv+")")
+
+	defaults := Default()
+	for _, s := range settings {
+		name := s.flag
+		usage := s.usage
+		if v := fmt.Sprint(value(s.field(&defaults))); v != "" {
+			usage += " (default " + v + ")"
+		}
+		fs.Func(name, usage+", $"+s.env, func(v string) error {
+			if err := set(s.field(&Config{}), v); err != nil {
+				return err
+			}
+			l.flags[name] = v
+			return nil
+		})
+	}
+	return l
+}
+
+// Load resolves the configuration from the defaults, the config file, the
+// environment and the parsed flags, and validates it.
+func (l *Loader) Load() (Config, error) {
+	cfg := Default()
+
+	path := l.file
+	if path == "" {
+		path = os.Getenv(ConfigEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var problems []string
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok && v != "" {
+			if err := set(s.field(&cfg), v); err != nil {
+				problems = append(problems, s.env+": "+err.Error())
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return Config{}, fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+
+	// Flag values were checked when they were parsed.
+	for _, s := range settings {
+		if v, ok := l.flags[s.flag]; ok {
+			set(s.field(&cfg), v)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			return fmt.Errorf("config file %s: invalid JSON at offset %d: %w", path, syntax.Offset, err)
+		}
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func value(field any) any {
+	switch p := field.(type) {
+	case *string:
+		return *p
+	case *int:
+		return *p
+	case *Duration:
+		return *p
+	}
+	return nil
+}
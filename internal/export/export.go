@@ -0,0 +1,218 @@
+// Package export writes tickets, prizes, draws and ledger entries as CSV or
+// JSON Lines. Rows are encoded as the service produces them, so the HTTP
+// endpoint and the export command never hold a whole dataset in memory.
+package export
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+// Datasets lists what can be exported, in the order the CLI shows them.
+var Datasets = []string{"tickets", "prizes", "draws", "ledger"}
+
+// flushEvery is how many rows are buffered before they are pushed to the
+// underlying writer.
+const flushEvery = 100
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", CSV:
+		return CSV, nil
+	case JSONL, "ndjson":
+		return JSONL, nil
+	}
+	return "", fmt.Errorf("unknown format %q, want csv or jsonl", s)
+}
+
+func (f Format) ContentType() string {
+	if f == JSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func IsDataset(name string) bool {
+	for _, d := range Datasets {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTime accepts RFC 3339 timestamps or plain dates. A plain date used as
+// an upper bound covers the whole day.
+func ParseTime(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date (2006-01-02) or RFC 3339 timestamp", v)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// Write streams the named dataset to w. If w has a Flush method, such as an
+// http.ResponseWriter, it is called as rows are written.
+func Write(w io.Writer, s *services.LotteryService, dataset string, format Format, f models.ExportFilter) error {
+	switch dataset {
+	case "tickets":
+		enc := newEncoder(w, format, ticketColumns)
+		return enc.close(s.ExportTickets(f, func(t models.Ticket) error {
+			return enc.write(t, ticketRecord(t))
+		}))
+	case "prizes":
+		enc := newEncoder(w, format, prizeColumns)
+		return enc.close(s.ExportPrizes(f, func(p models.Prize) error {
+			return enc.write(p, prizeRecord(p))
+		}))
+	case "draws":
+		enc := newEncoder(w, format, drawColumns)
+		return enc.close(s.ExportDraws(f, func(d models.Draw) error {
+			return enc.write(d, drawRecord(d))
+		}))
+	case "ledger":
+		enc := newEncoder(w, format, ledgerColumns)
+		return enc.close(s.ExportLedger(f, func(e models.LedgerEntry) error {
+			return enc.write(e, ledgerRecord(e))
+		}))
+	}
+	return fmt.Errorf("unknown dataset %q", dataset)
+}
+
+type encoder struct {
+	w       io.Writer
+	columns []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	rows    int
+}
+
+func newEncoder(w io.Writer, format Format, columns []string) *encoder {
+	e := &encoder{w: w, columns: columns}
+	if format == JSONL {
+		e.json = json.NewEncoder(w)
+	} else {
+		e.csv = csv.NewWriter(w)
+	}
+	return e
+}
+
+func (e *encoder) write(v any, record []string) error {
+	if e.csv != nil {
+		if e.rows == 0 {
+			if err := e.csv.Write(e.columns); err != nil {
+				return err
+			}
+		}
+		if err := e.csv.Write(record); err != nil {
+			return err
+		}
+	} else if err := e.json.Encode(v); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%flushEvery == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// close writes the CSV header of an empty export and flushes what is left.
+func (e *encoder) close(err error) error {
+	if err != nil {
+		return err
+	}
+	if e.csv != nil && e.rows == 0 {
+		if err := e.csv.Write(e.columns); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+func (e *encoder) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if f, ok := e.w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+var ticketColumns = []string{"id", "serial", "user_id", "draw_id", "numbers", "matches", "prize_id", "price", "status", "cancelled_at", "created_at"}
+
+func ticketRecord(t models.Ticket) []string {
+	cancelledAt := ""
+	if t.CancelledAt != nil {
+		cancelledAt = formatTime(*t.CancelledAt)
+	}
+	return []string{
+		t.ID, t.Serial, t.UserID, t.DrawID, formatNumbers(t.Numbers), strconv.Itoa(t.Matches),
+		t.PrizeID, strconv.Itoa(t.Price), t.Status, cancelledAt, formatTime(t.CreatedAt),
+	}
+}
+
+var prizeColumns = []string{"id", "ticket_id", "user_id", "draw_id", "type", "name", "value", "matches_count", "created_at"}
+
+func prizeRecord(p models.Prize) []string {
+	return []string{
+		p.ID, p.TicketID, p.UserID, p.DrawID, string(p.Type), p.Name,
+		strconv.Itoa(p.Value), strconv.Itoa(p.MatchesCount), formatTime(p.CreatedAt),
+	}
+}
+
+var drawColumns = []string{"id", "status", "winning_numbers", "draw_date", "created_at"}
+
+func drawRecord(d models.Draw) []string {
+	return []string{d.ID, d.Status, formatNumbers(d.WinningNumbers), formatTime(d.DrawDate), formatTime(d.CreatedAt)}
+}
+
+var ledgerColumns = []string{"time", "user_id", "kind", "amount", "ticket_id", "draw_id", "prize_id", "reason"}
+
+func ledgerRecord(e models.LedgerEntry) []string {
+	return []string{formatTime(e.Time), e.UserID, e.Kind, strconv.Itoa(e.Amount), e.TicketID, e.DrawID, e.PrizeID, e.Reason}
+}
+
+// formatNumbers joins numbers with spaces so they stay in one CSV cell.
+func formatNumbers(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,82 @@
+package grpcapi
+
+import (
+	"LotterySystem/internal/grpcapi/lotteryv1"
+	"LotterySystem/internal/models"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// access says who may call a method, given the caller's bearer token and
+// the request.
+type access func(s *Server, token string, req any) bool
+
+// methodAccess holds the methods that are not open to everyone. They take
+// the same tokens as the HTTP API: the admin token, or a session token.
+var methodAccess = map[string]access{
+	lotteryv1.LotteryService_CreateDraw_FullMethodName:  adminOnly,
+	lotteryv1.LotteryService_ExecuteDraw_FullMethodName: adminOnly,
+	lotteryv1.LotteryService_ListTickets_FullMethodName: ownTicketsOrAdmin,
+}
+
+func adminOnly(s *Server, token string, _ any) bool {
+	return s.service.IsAdmin(token)
+}
+
+// ownTicketsOrAdmin lets an admin search every ticket and a user list
+// their own.
+func ownTicketsOrAdmin(s *Server, token string, req any) bool {
+	if s.service.IsAdmin(token) {
+		return true
+	}
+	userID := req.(*lotteryv1.ListTicketsRequest).GetUserId()
+	user, err := s.service.Authenticate(token)
+	return err == nil && userID != "" && user.ID == userID
+}
+
+// ownsTicket reports whether token is the ticket owner's session or an
+// admin's.
+func (s *Server) ownsTicket(token string, ticket models.Ticket) bool {
+	if s.service.IsAdmin(token) {
+		return true
+	}
+	user, err := s.service.Authenticate(token)
+	return err == nil && user.ID == ticket.UserID
+}
+
+// UnaryAuth refuses calls to the methods in methodAccess that the caller's
+// token does not allow, the counterpart of the HTTP handlers' requireAdmin.
+func (s *Server) UnaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	allowed, ok := methodAccess[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+	token := bearerToken(ctx)
+	if !allowed(s, token, req) {
+		if _, err := s.service.Authenticate(token); err == nil {
+			return nil, status.Error(codes.PermissionDenied, "not allowed for this session")
+		}
+		return nil, status.Error(codes.Unauthenticated, "admin token required")
+	}
+	return handler(ctx, req)
+}
+
+// bearerToken reads the "authorization: Bearer" metadata, as the HTTP API
+// reads the header.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, v := range md.Get("authorization") {
+		if t, ok := strings.CutPrefix(v, "Bearer "); ok {
+			return t
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package grpcapi
+
+import (
+	"LotterySystem/internal/grpcapi/lotteryv1"
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryAuth(t *testing.T) {
+	dir := t.TempDir()
+	svc := services.NewLotteryService(
+		storage.NewUserRepository(dir),
+		storage.NewDrawRepository(dir),
+		storage.NewTicketRepository(dir),
+		storage.NewPrizeRepository(dir),
+	)
+	svc.SetAdminToken("admin-secret")
+	alice, err := svc.RegisterUser("alice", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := svc.IssueSession(alice.ID).Token
+	s := NewServer(svc)
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		req    any
+		want   codes.Code
+	}{
+		{"open method", lotteryv1.LotteryService_GetPendingDraw_FullMethodName, "", &lotteryv1.GetPendingDrawRequest{}, codes.OK},
+		{"create draw without token", lotteryv1.LotteryService_CreateDraw_FullMethodName, "", &lotteryv1.CreateDrawRequest{}, codes.Unauthenticated},
+		{"create draw as player", lotteryv1.LotteryService_CreateDraw_FullMethodName, session, &lotteryv1.CreateDrawRequest{}, codes.PermissionDenied},
+		{"create draw as admin", lotteryv1.LotteryService_CreateDraw_FullMethodName, "admin-secret", &lotteryv1.CreateDrawRequest{}, codes.OK},
+		{"execute draw with a wrong token", lotteryv1.LotteryService_ExecuteDraw_FullMethodName, "nope", &lotteryv1.ExecuteDrawRequest{}, codes.Unauthenticated},
+		{"all tickets without token", lotteryv1.LotteryService_ListTickets_FullMethodName, "", &lotteryv1.ListTicketsRequest{}, codes.Unauthenticated},
+		{"all tickets as player", lotteryv1.LotteryService_ListTickets_FullMethodName, session, &lotteryv1.ListTicketsRequest{}, codes.PermissionDenied},
+		{"own tickets", lotteryv1.LotteryService_ListTickets_FullMethodName, session, &lotteryv1.ListTicketsRequest{UserId: alice.ID}, codes.OK},
+		{"someone else's tickets", lotteryv1.LotteryService_ListTickets_FullMethodName, session, &lotteryv1.ListTicketsRequest{UserId: "usr_other"}, codes.PermissionDenied},
+		{"all tickets as admin", lotteryv1.LotteryService_ListTickets_FullMethodName, "admin-secret", &lotteryv1.ListTicketsRequest{}, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+			called := false
+			handler := func(context.Context, any) (any, error) {
+				called = true
+				return nil, nil
+			}
+			_, err := s.UnaryAuth(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s (%v)", got, tt.want, err)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Fatalf("handler called = %v, want %v", called, tt.want == codes.OK)
+			}
+		})
+	}
+}
+
+func TestGetTicketBySerial(t *testing.T) {
+	dir := t.TempDir()
+	svc := services.NewLotteryService(
+		storage.NewUserRepository(dir),
+		storage.NewDrawRepository(dir),
+		storage.NewTicketRepository(dir),
+		storage.NewPrizeRepository(dir),
+	)
+	svc.SetAdminToken("admin-secret")
+	svc.SetStartingBalance(1000)
+	alice, err := svc.RegisterUser("alice", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := svc.RegisterUser("bob", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draw, err := svc.CreateDraw(services.SystemActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticket, err := svc.CreateTicket(alice.ID, draw.ID, []int{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(svc)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantTicket bool
+	}{
+		{"anonymous", "", false},
+		{"someone else", svc.IssueSession(bob.ID).Token, false},
+		{"owner", svc.IssueSession(alice.ID).Token, true},
+		{"admin", "admin-secret", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+			check, err := s.GetTicketBySerial(ctx, &lotteryv1.GetTicketBySerialRequest{Serial: ticket.Serial})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !check.GetValid() || check.GetSerial() != ticket.Serial || check.GetDrawId() != draw.ID ||
+				check.GetDrawStatus() != "pending" || check.GetStatus() != "active" {
+				t.Errorf("check = %v, want the serial's ticket and draw status", check)
+			}
+			if got := check.GetTicket() != nil; got != tt.wantTicket {
+				t.Errorf("ticket included = %v, want %v", got, tt.wantTicket)
+			}
+			if tt.wantTicket && check.GetTicket().GetUserId() != alice.ID {
+				t.Errorf("ticket owner = %q, want %q", check.GetTicket().GetUserId(), alice.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package grpcapi
+
+import (
+	"LotterySystem/internal/grpcapi/lotteryv1"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toUser(u models.User) *lotteryv1.User {
+	return &lotteryv1.User{
+		Id:        u.ID,
+		Username:  u.Username,
+		Balance:   int64(u.Balance),
+		CreatedAt: toTimestamp(u.CreatedAt),
+	}
+}
+
+func toSession(s models.Session) *lotteryv1.Session {
+	return &lotteryv1.Session{
+		Token:     s.Token,
+		UserId:    s.UserID,
+		ExpiresAt: toTimestamp(s.ExpiresAt),
+	}
+}
+
+func toDraw(d models.Draw) *lotteryv1.Draw {
+	return &lotteryv1.Draw{
+		Id:             d.ID,
+		WinningNumbers: toInt32s(d.WinningNumbers),
+		Status:         d.Status,
+		DrawDate:       toTimestamp(d.DrawDate),
+		CreatedAt:      toTimestamp(d.CreatedAt),
+	}
+}
+
+func toTicket(t models.Ticket) *lotteryv1.Ticket {
+	status := t.Status
+	if status == "" {
+		status = "active"
+	}
+
+	ticket := &lotteryv1.Ticket{
+		Id:        t.ID,
+		Serial:    t.Serial,
+		UserId:    t.UserID,
+		DrawId:    t.DrawID,
+		Numbers:   toInt32s(t.Numbers),
+		Matches:   int32(t.Matches),
+		PrizeId:   t.PrizeID,
+		Price:     int64(t.Price),
+		Status:    status,
+		CreatedAt: toTimestamp(t.CreatedAt),
+	}
+	if t.CancelledAt != nil {
+		ticket.CancelledAt = toTimestamp(*t.CancelledAt)
+	}
+	return ticket
+}
+
+func toPrize(p models.Prize) *lotteryv1.Prize {
+	return &lotteryv1.Prize{
+		Id:           p.ID,
+		TicketId:     p.TicketID,
+		UserId:       p.UserID,
+		DrawId:       p.DrawID,
+		Type:         string(p.Type),
+		Name:         p.Name,
+		Value:        int64(p.Value),
+		MatchesCount: int32(p.MatchesCount),
+		CreatedAt:    toTimestamp(p.CreatedAt),
+	}
+}
+
+// toDrawEvent carries the event data over as a Struct, built from its JSON
+// form so it matches what SSE clients receive.
+func toDrawEvent(e services.Event) (*lotteryv1.DrawEvent, error) {
+	event := &lotteryv1.DrawEvent{
+		Id:     e.ID,
+		Type:   string(e.Type),
+		DrawId: e.DrawID,
+		Time:   toTimestamp(e.Time),
+	}
+	if e.Data == nil {
+		return event, nil
+	}
+
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	data, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+	event.Data = data
+	return event, nil
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toInt32s(ns []int) []int32 {
+	res := make([]int32, len(ns))
+	for i, n := range ns {
+		res[i] = int32(n)
+	}
+	return res
+}
@@ -0,0 +1,81 @@
+package grpcapi
+
+import (
+	"LotterySystem/internal/services"
+	"errors"
+	"sort"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps service errors onto gRPC codes, the counterpart of the HTTP
+// handlers' writeError. Unexpected errors are reported as Internal without
+// their message.
+func toStatus(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, services.ErrAlreadyExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, services.ErrValidation):
+		code = codes.InvalidArgument
+	case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrDrawClosed):
+		code = codes.FailedPrecondition
+	case errors.Is(err, services.ErrConflict):
+		code = codes.Aborted
+	case errors.Is(err, services.ErrUnauthorized):
+		code = codes.Unauthenticated
+	default:
+		return &internalError{cause: err}
+	}
+
+	message := err.Error()
+	var svcErr *services.Error
+	if errors.As(err, &svcErr) {
+		message = svcErr.Message
+	}
+
+	st := status.New(code, message)
+	if svcErr == nil || len(svcErr.Fields) == 0 {
+		return st.Err()
+	}
+
+	fields := make([]string, 0, len(svcErr.Fields))
+	for f := range svcErr.Fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	detail := &errdetails.BadRequest{}
+	for _, f := range fields {
+		detail.FieldViolations = append(detail.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       f,
+			Description: svcErr.Fields[f],
+		})
+	}
+	if withDetails, err := st.WithDetails(detail); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// internalError reaches the client as a bare Internal status while keeping
+// the cause for the call log.
+type internalError struct {
+	cause error
+}
+
+func (e *internalError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *internalError) Unwrap() error {
+	return e.cause
+}
+
+func (e *internalError) GRPCStatus() *status.Status {
+	return status.New(codes.Internal, "internal error")
+}
@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"LotterySystem/internal/utils"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key carrying the request ID, the gRPC
+// counterpart of the X-Request-ID header.
+const requestIDKey = "x-request-id"
+
+var requestIDs = utils.NewULIDGenerator()
+
+// UnaryLogger logs every call with its request ID, code and duration.
+func UnaryLogger(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := requestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(ctx, id, info.FullMethod, start, err)
+	return resp, err
+}
+
+// StreamLogger is UnaryLogger for streams, which are logged when they end.
+func StreamLogger(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := requestID(ss.Context())
+	ss.SetHeader(metadata.Pairs(requestIDKey, id))
+
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(ss.Context(), id, info.FullMethod, start, err)
+	return err
+}
+
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 && ids[0] != "" && len(ids[0]) <= 128 {
+			return ids[0]
+		}
+	}
+	return requestIDs.NewID("req")
+}
+
+func logCall(ctx context.Context, id, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	attrs := []slog.Attr{
+		slog.String("request_id", id),
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("duration", time.Since(start)),
+	}
+	var internal *internalError
+	switch {
+	case errors.As(err, &internal):
+		attrs = append(attrs, slog.String("err", internal.cause.Error()))
+		level = slog.LevelError
+	case err != nil:
+		attrs = append(attrs, slog.String("err", status.Convert(err).Message()))
+		if code == codes.Internal || code == codes.Unknown || code == codes.DataLoss {
+			level = slog.LevelError
+		}
+	}
+	slog.LogAttrs(ctx, level, "grpc call", attrs...)
+}
@@ -0,0 +1,1976 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.28.3
+// source: lottery/v1/lottery.proto
+
+package lotteryv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Balance       int64                  `protobuf:"varint,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *User) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Session struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Session) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Session) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Session) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type Draw struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	WinningNumbers []int32                `protobuf:"varint,2,rep,packed,name=winning_numbers,json=winningNumbers,proto3" json:"winning_numbers,omitempty"`
+	// "pending", "drawing" or "completed".
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	DrawDate      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=draw_date,json=drawDate,proto3" json:"draw_date,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Draw) Reset() {
+	*x = Draw{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Draw) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Draw) ProtoMessage() {}
+
+func (x *Draw) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Draw.ProtoReflect.Descriptor instead.
+func (*Draw) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Draw) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Draw) GetWinningNumbers() []int32 {
+	if x != nil {
+		return x.WinningNumbers
+	}
+	return nil
+}
+
+func (x *Draw) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Draw) GetDrawDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DrawDate
+	}
+	return nil
+}
+
+func (x *Draw) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Ticket struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Serial  string                 `protobuf:"bytes,2,opt,name=serial,proto3" json:"serial,omitempty"`
+	UserId  string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DrawId  string                 `protobuf:"bytes,4,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	Numbers []int32                `protobuf:"varint,5,rep,packed,name=numbers,proto3" json:"numbers,omitempty"`
+	Matches int32                  `protobuf:"varint,6,opt,name=matches,proto3" json:"matches,omitempty"`
+	PrizeId string                 `protobuf:"bytes,7,opt,name=prize_id,json=prizeId,proto3" json:"prize_id,omitempty"`
+	Price   int64                  `protobuf:"varint,8,opt,name=price,proto3" json:"price,omitempty"`
+	// "active" or "cancelled".
+	Status        string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	CancelledAt   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ticket) Reset() {
+	*x = Ticket{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ticket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ticket) ProtoMessage() {}
+
+func (x *Ticket) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ticket.ProtoReflect.Descriptor instead.
+func (*Ticket) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Ticket) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Ticket) GetSerial() string {
+	if x != nil {
+		return x.Serial
+	}
+	return ""
+}
+
+func (x *Ticket) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Ticket) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *Ticket) GetNumbers() []int32 {
+	if x != nil {
+		return x.Numbers
+	}
+	return nil
+}
+
+func (x *Ticket) GetMatches() int32 {
+	if x != nil {
+		return x.Matches
+	}
+	return 0
+}
+
+func (x *Ticket) GetPrizeId() string {
+	if x != nil {
+		return x.PrizeId
+	}
+	return ""
+}
+
+func (x *Ticket) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Ticket) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Ticket) GetCancelledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return nil
+}
+
+func (x *Ticket) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// SerialCheck is what a serial lookup shows whoever holds the serial.
+type SerialCheck struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Valid  bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Serial string                 `protobuf:"bytes,2,opt,name=serial,proto3" json:"serial,omitempty"`
+	DrawId string                 `protobuf:"bytes,3,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	// The status of the ticket's draw.
+	DrawStatus string `protobuf:"bytes,4,opt,name=draw_status,json=drawStatus,proto3" json:"draw_status,omitempty"`
+	// The status of the ticket.
+	Status        string  `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Ticket        *Ticket `protobuf:"bytes,6,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SerialCheck) Reset() {
+	*x = SerialCheck{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SerialCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SerialCheck) ProtoMessage() {}
+
+func (x *SerialCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SerialCheck.ProtoReflect.Descriptor instead.
+func (*SerialCheck) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SerialCheck) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *SerialCheck) GetSerial() string {
+	if x != nil {
+		return x.Serial
+	}
+	return ""
+}
+
+func (x *SerialCheck) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *SerialCheck) GetDrawStatus() string {
+	if x != nil {
+		return x.DrawStatus
+	}
+	return ""
+}
+
+func (x *SerialCheck) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SerialCheck) GetTicket() *Ticket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+type Prize struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TicketId string                 `protobuf:"bytes,2,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	UserId   string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DrawId   string                 `protobuf:"bytes,4,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	// "money", "travel" or "gift".
+	Type          string                 `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	Value         int64                  `protobuf:"varint,7,opt,name=value,proto3" json:"value,omitempty"`
+	MatchesCount  int32                  `protobuf:"varint,8,opt,name=matches_count,json=matchesCount,proto3" json:"matches_count,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prize) Reset() {
+	*x = Prize{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prize) ProtoMessage() {}
+
+func (x *Prize) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prize.ProtoReflect.Descriptor instead.
+func (*Prize) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Prize) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Prize) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+func (x *Prize) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Prize) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *Prize) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Prize) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Prize) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Prize) GetMatchesCount() int32 {
+	if x != nil {
+		return x.MatchesCount
+	}
+	return 0
+}
+
+func (x *Prize) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type DrawEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// e.g. "draw.opened", "draw.number_drawn", "prize.awarded".
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	DrawId        string                 `protobuf:"bytes,3,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	Data          *structpb.Struct       `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrawEvent) Reset() {
+	*x = DrawEvent{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrawEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrawEvent) ProtoMessage() {}
+
+func (x *DrawEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrawEvent.ProtoReflect.Descriptor instead.
+func (*DrawEvent) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DrawEvent) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DrawEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DrawEvent) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *DrawEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *DrawEvent) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// ListOptions mirrors the sort, order, limit and cursor query parameters.
+type ListOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Sort  string                 `protobuf:"bytes,1,opt,name=sort,proto3" json:"sort,omitempty"`
+	// Ascending order when true; newest first otherwise.
+	Ascending bool `protobuf:"varint,2,opt,name=ascending,proto3" json:"ascending,omitempty"`
+	// Defaults to 100, at most 1000.
+	Limit int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// next_cursor of the previous page.
+	Cursor        string `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOptions) Reset() {
+	*x = ListOptions{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOptions) ProtoMessage() {}
+
+func (x *ListOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOptions.ProtoReflect.Descriptor instead.
+func (*ListOptions) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListOptions) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListOptions) GetAscending() bool {
+	if x != nil {
+		return x.Ascending
+	}
+	return false
+}
+
+func (x *ListOptions) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListOptions) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type RegisterUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterUserRequest) Reset() {
+	*x = RegisterUserRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterUserRequest) ProtoMessage() {}
+
+func (x *RegisterUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterUserRequest.ProtoReflect.Descriptor instead.
+func (*RegisterUserRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RegisterUserRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *RegisterUserRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LoginRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type CreateDrawRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDrawRequest) Reset() {
+	*x = CreateDrawRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDrawRequest) ProtoMessage() {}
+
+func (x *CreateDrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDrawRequest.ProtoReflect.Descriptor instead.
+func (*CreateDrawRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{11}
+}
+
+type GetDrawRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DrawId        string                 `protobuf:"bytes,1,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDrawRequest) Reset() {
+	*x = GetDrawRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDrawRequest) ProtoMessage() {}
+
+func (x *GetDrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDrawRequest.ProtoReflect.Descriptor instead.
+func (*GetDrawRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetDrawRequest) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+type GetPendingDrawRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPendingDrawRequest) Reset() {
+	*x = GetPendingDrawRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPendingDrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPendingDrawRequest) ProtoMessage() {}
+
+func (x *GetPendingDrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPendingDrawRequest.ProtoReflect.Descriptor instead.
+func (*GetPendingDrawRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{13}
+}
+
+type ListDrawsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       *ListOptions           `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDrawsRequest) Reset() {
+	*x = ListDrawsRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDrawsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDrawsRequest) ProtoMessage() {}
+
+func (x *ListDrawsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDrawsRequest.ProtoReflect.Descriptor instead.
+func (*ListDrawsRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListDrawsRequest) GetOptions() *ListOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ListDrawsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListDrawsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListDrawsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type ListDrawsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Draws         []*Draw                `protobuf:"bytes,1,rep,name=draws,proto3" json:"draws,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDrawsResponse) Reset() {
+	*x = ListDrawsResponse{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDrawsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDrawsResponse) ProtoMessage() {}
+
+func (x *ListDrawsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDrawsResponse.ProtoReflect.Descriptor instead.
+func (*ListDrawsResponse) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListDrawsResponse) GetDraws() []*Draw {
+	if x != nil {
+		return x.Draws
+	}
+	return nil
+}
+
+func (x *ListDrawsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type ExecuteDrawRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DrawId        string                 `protobuf:"bytes,1,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteDrawRequest) Reset() {
+	*x = ExecuteDrawRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteDrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteDrawRequest) ProtoMessage() {}
+
+func (x *ExecuteDrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteDrawRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteDrawRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExecuteDrawRequest) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+type CreateTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DrawId        string                 `protobuf:"bytes,2,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	Numbers       []int32                `protobuf:"varint,3,rep,packed,name=numbers,proto3" json:"numbers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTicketRequest) Reset() {
+	*x = CreateTicketRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTicketRequest) ProtoMessage() {}
+
+func (x *CreateTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTicketRequest.ProtoReflect.Descriptor instead.
+func (*CreateTicketRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreateTicketRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateTicketRequest) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *CreateTicketRequest) GetNumbers() []int32 {
+	if x != nil {
+		return x.Numbers
+	}
+	return nil
+}
+
+type GetTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TicketId      string                 `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTicketRequest) Reset() {
+	*x = GetTicketRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTicketRequest) ProtoMessage() {}
+
+func (x *GetTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTicketRequest.ProtoReflect.Descriptor instead.
+func (*GetTicketRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetTicketRequest) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+type GetTicketBySerialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Serial        string                 `protobuf:"bytes,1,opt,name=serial,proto3" json:"serial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTicketBySerialRequest) Reset() {
+	*x = GetTicketBySerialRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTicketBySerialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTicketBySerialRequest) ProtoMessage() {}
+
+func (x *GetTicketBySerialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTicketBySerialRequest.ProtoReflect.Descriptor instead.
+func (*GetTicketBySerialRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetTicketBySerialRequest) GetSerial() string {
+	if x != nil {
+		return x.Serial
+	}
+	return ""
+}
+
+type CancelTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TicketId      string                 `protobuf:"bytes,2,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTicketRequest) Reset() {
+	*x = CancelTicketRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTicketRequest) ProtoMessage() {}
+
+func (x *CancelTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTicketRequest.ProtoReflect.Descriptor instead.
+func (*CancelTicketRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CancelTicketRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CancelTicketRequest) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+type ListTicketsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       *ListOptions           `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DrawId        string                 `protobuf:"bytes,3,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	MinMatches    int32                  `protobuf:"varint,5,opt,name=min_matches,json=minMatches,proto3" json:"min_matches,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTicketsRequest) Reset() {
+	*x = ListTicketsRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTicketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTicketsRequest) ProtoMessage() {}
+
+func (x *ListTicketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTicketsRequest.ProtoReflect.Descriptor instead.
+func (*ListTicketsRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListTicketsRequest) GetOptions() *ListOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ListTicketsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListTicketsRequest) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *ListTicketsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListTicketsRequest) GetMinMatches() int32 {
+	if x != nil {
+		return x.MinMatches
+	}
+	return 0
+}
+
+func (x *ListTicketsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListTicketsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type ListTicketsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tickets       []*Ticket              `protobuf:"bytes,1,rep,name=tickets,proto3" json:"tickets,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTicketsResponse) Reset() {
+	*x = ListTicketsResponse{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTicketsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTicketsResponse) ProtoMessage() {}
+
+func (x *ListTicketsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTicketsResponse.ProtoReflect.Descriptor instead.
+func (*ListTicketsResponse) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListTicketsResponse) GetTickets() []*Ticket {
+	if x != nil {
+		return x.Tickets
+	}
+	return nil
+}
+
+func (x *ListTicketsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetPrizeByTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TicketId      string                 `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPrizeByTicketRequest) Reset() {
+	*x = GetPrizeByTicketRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPrizeByTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPrizeByTicketRequest) ProtoMessage() {}
+
+func (x *GetPrizeByTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPrizeByTicketRequest.ProtoReflect.Descriptor instead.
+func (*GetPrizeByTicketRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetPrizeByTicketRequest) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+type ListPrizesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       *ListOptions           `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	DrawId        string                 `protobuf:"bytes,3,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MinMatches    int32                  `protobuf:"varint,5,opt,name=min_matches,json=minMatches,proto3" json:"min_matches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPrizesRequest) Reset() {
+	*x = ListPrizesRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPrizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPrizesRequest) ProtoMessage() {}
+
+func (x *ListPrizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPrizesRequest.ProtoReflect.Descriptor instead.
+func (*ListPrizesRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListPrizesRequest) GetOptions() *ListOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ListPrizesRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListPrizesRequest) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *ListPrizesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListPrizesRequest) GetMinMatches() int32 {
+	if x != nil {
+		return x.MinMatches
+	}
+	return 0
+}
+
+type ListPrizesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prizes        []*Prize               `protobuf:"bytes,1,rep,name=prizes,proto3" json:"prizes,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPrizesResponse) Reset() {
+	*x = ListPrizesResponse{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPrizesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPrizesResponse) ProtoMessage() {}
+
+func (x *ListPrizesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPrizesResponse.ProtoReflect.Descriptor instead.
+func (*ListPrizesResponse) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListPrizesResponse) GetPrizes() []*Prize {
+	if x != nil {
+		return x.Prizes
+	}
+	return nil
+}
+
+func (x *ListPrizesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type WatchDrawEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only events of this draw when set.
+	DrawId string `protobuf:"bytes,1,opt,name=draw_id,json=drawId,proto3" json:"draw_id,omitempty"`
+	// Only these event types when set.
+	Types         []string `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchDrawEventsRequest) Reset() {
+	*x = WatchDrawEventsRequest{}
+	mi := &file_lottery_v1_lottery_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchDrawEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchDrawEventsRequest) ProtoMessage() {}
+
+func (x *WatchDrawEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lottery_v1_lottery_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchDrawEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchDrawEventsRequest) Descriptor() ([]byte, []int) {
+	return file_lottery_v1_lottery_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *WatchDrawEventsRequest) GetDrawId() string {
+	if x != nil {
+		return x.DrawId
+	}
+	return ""
+}
+
+func (x *WatchDrawEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+var File_lottery_v1_lottery_proto protoreflect.FileDescriptor
+
+const file_lottery_v1_lottery_proto_rawDesc = "" +
+	"\n" +
+	"\x18lottery/v1/lottery.proto\x12\n" +
+	"lottery.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x87\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x18\n" +
+	"\abalance\x18\x03 \x01(\x03R\abalance\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"s\n" +
+	"\aSession\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xcb\x01\n" +
+	"\x04Draw\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0fwinning_numbers\x18\x02 \x03(\x05R\x0ewinningNumbers\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x127\n" +
+	"\tdraw_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bdrawDate\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xd9\x02\n" +
+	"\x06Ticket\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06serial\x18\x02 \x01(\tR\x06serial\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x17\n" +
+	"\adraw_id\x18\x04 \x01(\tR\x06drawId\x12\x18\n" +
+	"\anumbers\x18\x05 \x03(\x05R\anumbers\x12\x18\n" +
+	"\amatches\x18\x06 \x01(\x05R\amatches\x12\x19\n" +
+	"\bprize_id\x18\a \x01(\tR\aprizeId\x12\x14\n" +
+	"\x05price\x18\b \x01(\x03R\x05price\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12=\n" +
+	"\fcancelled_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vcancelledAt\x129\n" +
+	"\n" +
+	"created_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb9\x01\n" +
+	"\vSerialCheck\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x16\n" +
+	"\x06serial\x18\x02 \x01(\tR\x06serial\x12\x17\n" +
+	"\adraw_id\x18\x03 \x01(\tR\x06drawId\x12\x1f\n" +
+	"\vdraw_status\x18\x04 \x01(\tR\n" +
+	"drawStatus\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12*\n" +
+	"\x06ticket\x18\x06 \x01(\v2\x12.lottery.v1.TicketR\x06ticket\"\x84\x02\n" +
+	"\x05Prize\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tticket_id\x18\x02 \x01(\tR\bticketId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x17\n" +
+	"\adraw_id\x18\x04 \x01(\tR\x06drawId\x12\x12\n" +
+	"\x04type\x18\x05 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\a \x01(\x03R\x05value\x12#\n" +
+	"\rmatches_count\x18\b \x01(\x05R\fmatchesCount\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa5\x01\n" +
+	"\tDrawEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x17\n" +
+	"\adraw_id\x18\x03 \x01(\tR\x06drawId\x12.\n" +
+	"\x04time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12+\n" +
+	"\x04data\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x04data\"m\n" +
+	"\vListOptions\x12\x12\n" +
+	"\x04sort\x18\x01 \x01(\tR\x04sort\x12\x1c\n" +
+	"\tascending\x18\x02 \x01(\bR\tascending\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\"M\n" +
+	"\x13RegisterUserRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"F\n" +
+	"\fLoginRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\")\n" +
+	"\x0eGetUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x13\n" +
+	"\x11CreateDrawRequest\")\n" +
+	"\x0eGetDrawRequest\x12\x17\n" +
+	"\adraw_id\x18\x01 \x01(\tR\x06drawId\"\x17\n" +
+	"\x15GetPendingDrawRequest\"\xb9\x01\n" +
+	"\x10ListDrawsRequest\x121\n" +
+	"\aoptions\x18\x01 \x01(\v2\x17.lottery.v1.ListOptionsR\aoptions\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"\\\n" +
+	"\x11ListDrawsResponse\x12&\n" +
+	"\x05draws\x18\x01 \x03(\v2\x10.lottery.v1.DrawR\x05draws\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"-\n" +
+	"\x12ExecuteDrawRequest\x12\x17\n" +
+	"\adraw_id\x18\x01 \x01(\tR\x06drawId\"a\n" +
+	"\x13CreateTicketRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\adraw_id\x18\x02 \x01(\tR\x06drawId\x12\x18\n" +
+	"\anumbers\x18\x03 \x03(\x05R\anumbers\"/\n" +
+	"\x10GetTicketRequest\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\tR\bticketId\"2\n" +
+	"\x18GetTicketBySerialRequest\x12\x16\n" +
+	"\x06serial\x18\x01 \x01(\tR\x06serial\"K\n" +
+	"\x13CancelTicketRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tticket_id\x18\x02 \x01(\tR\bticketId\"\x8e\x02\n" +
+	"\x12ListTicketsRequest\x121\n" +
+	"\aoptions\x18\x01 \x01(\v2\x17.lottery.v1.ListOptionsR\aoptions\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x17\n" +
+	"\adraw_id\x18\x03 \x01(\tR\x06drawId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1f\n" +
+	"\vmin_matches\x18\x05 \x01(\x05R\n" +
+	"minMatches\x12.\n" +
+	"\x04from\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"d\n" +
+	"\x13ListTicketsResponse\x12,\n" +
+	"\atickets\x18\x01 \x03(\v2\x12.lottery.v1.TicketR\atickets\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"6\n" +
+	"\x17GetPrizeByTicketRequest\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\tR\bticketId\"\xad\x01\n" +
+	"\x11ListPrizesRequest\x121\n" +
+	"\aoptions\x18\x01 \x01(\v2\x17.lottery.v1.ListOptionsR\aoptions\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x17\n" +
+	"\adraw_id\x18\x03 \x01(\tR\x06drawId\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vmin_matches\x18\x05 \x01(\x05R\n" +
+	"minMatches\"`\n" +
+	"\x12ListPrizesResponse\x12)\n" +
+	"\x06prizes\x18\x01 \x03(\v2\x11.lottery.v1.PrizeR\x06prizes\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"G\n" +
+	"\x16WatchDrawEventsRequest\x12\x17\n" +
+	"\adraw_id\x18\x01 \x01(\tR\x06drawId\x12\x14\n" +
+	"\x05types\x18\x02 \x03(\tR\x05types2\xe4\b\n" +
+	"\x0eLotteryService\x12A\n" +
+	"\fRegisterUser\x12\x1f.lottery.v1.RegisterUserRequest\x1a\x10.lottery.v1.User\x126\n" +
+	"\x05Login\x12\x18.lottery.v1.LoginRequest\x1a\x13.lottery.v1.Session\x127\n" +
+	"\aGetUser\x12\x1a.lottery.v1.GetUserRequest\x1a\x10.lottery.v1.User\x12=\n" +
+	"\n" +
+	"CreateDraw\x12\x1d.lottery.v1.CreateDrawRequest\x1a\x10.lottery.v1.Draw\x127\n" +
+	"\aGetDraw\x12\x1a.lottery.v1.GetDrawRequest\x1a\x10.lottery.v1.Draw\x12E\n" +
+	"\x0eGetPendingDraw\x12!.lottery.v1.GetPendingDrawRequest\x1a\x10.lottery.v1.Draw\x12H\n" +
+	"\tListDraws\x12\x1c.lottery.v1.ListDrawsRequest\x1a\x1d.lottery.v1.ListDrawsResponse\x12?\n" +
+	"\vExecuteDraw\x12\x1e.lottery.v1.ExecuteDrawRequest\x1a\x10.lottery.v1.Draw\x12C\n" +
+	"\fCreateTicket\x12\x1f.lottery.v1.CreateTicketRequest\x1a\x12.lottery.v1.Ticket\x12=\n" +
+	"\tGetTicket\x12\x1c.lottery.v1.GetTicketRequest\x1a\x12.lottery.v1.Ticket\x12R\n" +
+	"\x11GetTicketBySerial\x12$.lottery.v1.GetTicketBySerialRequest\x1a\x17.lottery.v1.SerialCheck\x12C\n" +
+	"\fCancelTicket\x12\x1f.lottery.v1.CancelTicketRequest\x1a\x12.lottery.v1.Ticket\x12N\n" +
+	"\vListTickets\x12\x1e.lottery.v1.ListTicketsRequest\x1a\x1f.lottery.v1.ListTicketsResponse\x12J\n" +
+	"\x10GetPrizeByTicket\x12#.lottery.v1.GetPrizeByTicketRequest\x1a\x11.lottery.v1.Prize\x12K\n" +
+	"\n" +
+	"ListPrizes\x12\x1d.lottery.v1.ListPrizesRequest\x1a\x1e.lottery.v1.ListPrizesResponse\x12N\n" +
+	"\x0fWatchDrawEvents\x12\".lottery.v1.WatchDrawEventsRequest\x1a\x15.lottery.v1.DrawEvent0\x01B4Z2LotterySystem/internal/grpcapi/lotteryv1;lotteryv1b\x06proto3"
+
+var (
+	file_lottery_v1_lottery_proto_rawDescOnce sync.Once
+	file_lottery_v1_lottery_proto_rawDescData []byte
+)
+
+func file_lottery_v1_lottery_proto_rawDescGZIP() []byte {
+	file_lottery_v1_lottery_proto_rawDescOnce.Do(func() {
+		file_lottery_v1_lottery_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_lottery_v1_lottery_proto_rawDesc), len(file_lottery_v1_lottery_proto_rawDesc)))
+	})
+	return file_lottery_v1_lottery_proto_rawDescData
+}
+
+var file_lottery_v1_lottery_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_lottery_v1_lottery_proto_goTypes = []any{
+	(*User)(nil),                     // 0: lottery.v1.User
+	(*Session)(nil),                  // 1: lottery.v1.Session
+	(*Draw)(nil),                     // 2: lottery.v1.Draw
+	(*Ticket)(nil),                   // 3: lottery.v1.Ticket
+	(*SerialCheck)(nil),              // 4: lottery.v1.SerialCheck
+	(*Prize)(nil),                    // 5: lottery.v1.Prize
+	(*DrawEvent)(nil),                // 6: lottery.v1.DrawEvent
+	(*ListOptions)(nil),              // 7: lottery.v1.ListOptions
+	(*RegisterUserRequest)(nil),      // 8: lottery.v1.RegisterUserRequest
+	(*LoginRequest)(nil),             // 9: lottery.v1.LoginRequest
+	(*GetUserRequest)(nil),           // 10: lottery.v1.GetUserRequest
+	(*CreateDrawRequest)(nil),        // 11: lottery.v1.CreateDrawRequest
+	(*GetDrawRequest)(nil),           // 12: lottery.v1.GetDrawRequest
+	(*GetPendingDrawRequest)(nil),    // 13: lottery.v1.GetPendingDrawRequest
+	(*ListDrawsRequest)(nil),         // 14: lottery.v1.ListDrawsRequest
+	(*ListDrawsResponse)(nil),        // 15: lottery.v1.ListDrawsResponse
+	(*ExecuteDrawRequest)(nil),       // 16: lottery.v1.ExecuteDrawRequest
+	(*CreateTicketRequest)(nil),      // 17: lottery.v1.CreateTicketRequest
+	(*GetTicketRequest)(nil),         // 18: lottery.v1.GetTicketRequest
+	(*GetTicketBySerialRequest)(nil), // 19: lottery.v1.GetTicketBySerialRequest
+	(*CancelTicketRequest)(nil),      // 20: lottery.v1.CancelTicketRequest
+	(*ListTicketsRequest)(nil),       // 21: lottery.v1.ListTicketsRequest
+	(*ListTicketsResponse)(nil),      // 22: lottery.v1.ListTicketsResponse
+	(*GetPrizeByTicketRequest)(nil),  // 23: lottery.v1.GetPrizeByTicketRequest
+	(*ListPrizesRequest)(nil),        // 24: lottery.v1.ListPrizesRequest
+	(*ListPrizesResponse)(nil),       // 25: lottery.v1.ListPrizesResponse
+	(*WatchDrawEventsRequest)(nil),   // 26: lottery.v1.WatchDrawEventsRequest
+	(*timestamppb.Timestamp)(nil),    // 27: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),          // 28: google.protobuf.Struct
+}
+var file_lottery_v1_lottery_proto_depIdxs = []int32{
+	27, // 0: lottery.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	27, // 1: lottery.v1.Session.expires_at:type_name -> google.protobuf.Timestamp
+	27, // 2: lottery.v1.Draw.draw_date:type_name -> google.protobuf.Timestamp
+	27, // 3: lottery.v1.Draw.created_at:type_name -> google.protobuf.Timestamp
+	27, // 4: lottery.v1.Ticket.cancelled_at:type_name -> google.protobuf.Timestamp
+	27, // 5: lottery.v1.Ticket.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 6: lottery.v1.SerialCheck.ticket:type_name -> lottery.v1.Ticket
+	27, // 7: lottery.v1.Prize.created_at:type_name -> google.protobuf.Timestamp
+	27, // 8: lottery.v1.DrawEvent.time:type_name -> google.protobuf.Timestamp
+	28, // 9: lottery.v1.DrawEvent.data:type_name -> google.protobuf.Struct
+	7,  // 10: lottery.v1.ListDrawsRequest.options:type_name -> lottery.v1.ListOptions
+	27, // 11: lottery.v1.ListDrawsRequest.from:type_name -> google.protobuf.Timestamp
+	27, // 12: lottery.v1.ListDrawsRequest.to:type_name -> google.protobuf.Timestamp
+	2,  // 13: lottery.v1.ListDrawsResponse.draws:type_name -> lottery.v1.Draw
+	7,  // 14: lottery.v1.ListTicketsRequest.options:type_name -> lottery.v1.ListOptions
+	27, // 15: lottery.v1.ListTicketsRequest.from:type_name -> google.protobuf.Timestamp
+	27, // 16: lottery.v1.ListTicketsRequest.to:type_name -> google.protobuf.Timestamp
+	3,  // 17: lottery.v1.ListTicketsResponse.tickets:type_name -> lottery.v1.Ticket
+	7,  // 18: lottery.v1.ListPrizesRequest.options:type_name -> lottery.v1.ListOptions
+	5,  // 19: lottery.v1.ListPrizesResponse.prizes:type_name -> lottery.v1.Prize
+	8,  // 20: lottery.v1.LotteryService.RegisterUser:input_type -> lottery.v1.RegisterUserRequest
+	9,  // 21: lottery.v1.LotteryService.Login:input_type -> lottery.v1.LoginRequest
+	10, // 22: lottery.v1.LotteryService.GetUser:input_type -> lottery.v1.GetUserRequest
+	11, // 23: lottery.v1.LotteryService.CreateDraw:input_type -> lottery.v1.CreateDrawRequest
+	12, // 24: lottery.v1.LotteryService.GetDraw:input_type -> lottery.v1.GetDrawRequest
+	13, // 25: lottery.v1.LotteryService.GetPendingDraw:input_type -> lottery.v1.GetPendingDrawRequest
+	14, // 26: lottery.v1.LotteryService.ListDraws:input_type -> lottery.v1.ListDrawsRequest
+	16, // 27: lottery.v1.LotteryService.ExecuteDraw:input_type -> lottery.v1.ExecuteDrawRequest
+	17, // 28: lottery.v1.LotteryService.CreateTicket:input_type -> lottery.v1.CreateTicketRequest
+	18, // 29: lottery.v1.LotteryService.GetTicket:input_type -> lottery.v1.GetTicketRequest
+	19, // 30: lottery.v1.LotteryService.GetTicketBySerial:input_type -> lottery.v1.GetTicketBySerialRequest
+	20, // 31: lottery.v1.LotteryService.CancelTicket:input_type -> lottery.v1.CancelTicketRequest
+	21, // 32: lottery.v1.LotteryService.ListTickets:input_type -> lottery.v1.ListTicketsRequest
+	23, // 33: lottery.v1.LotteryService.GetPrizeByTicket:input_type -> lottery.v1.GetPrizeByTicketRequest
+	24, // 34: lottery.v1.LotteryService.ListPrizes:input_type -> lottery.v1.ListPrizesRequest
+	26, // 35: lottery.v1.LotteryService.WatchDrawEvents:input_type -> lottery.v1.WatchDrawEventsRequest
+	0,  // 36: lottery.v1.LotteryService.RegisterUser:output_type -> lottery.v1.User
+	1,  // 37: lottery.v1.LotteryService.Login:output_type -> lottery.v1.Session
+	0,  // 38: lottery.v1.LotteryService.GetUser:output_type -> lottery.v1.User
+	2,  // 39: lottery.v1.LotteryService.CreateDraw:output_type -> lottery.v1.Draw
+	2,  // 40: lottery.v1.LotteryService.GetDraw:output_type -> lottery.v1.Draw
+	2,  // 41: lottery.v1.LotteryService.GetPendingDraw:output_type -> lottery.v1.Draw
+	15, // 42: lottery.v1.LotteryService.ListDraws:output_type -> lottery.v1.ListDrawsResponse
+	2,  // 43: lottery.v1.LotteryService.ExecuteDraw:output_type -> lottery.v1.Draw
+	3,  // 44: lottery.v1.LotteryService.CreateTicket:output_type -> lottery.v1.Ticket
+	3,  // 45: lottery.v1.LotteryService.GetTicket:output_type -> lottery.v1.Ticket
+	4,  // 46: lottery.v1.LotteryService.GetTicketBySerial:output_type -> lottery.v1.SerialCheck
+	3,  // 47: lottery.v1.LotteryService.CancelTicket:output_type -> lottery.v1.Ticket
+	22, // 48: lottery.v1.LotteryService.ListTickets:output_type -> lottery.v1.ListTicketsResponse
+	5,  // 49: lottery.v1.LotteryService.GetPrizeByTicket:output_type -> lottery.v1.Prize
+	25, // 50: lottery.v1.LotteryService.ListPrizes:output_type -> lottery.v1.ListPrizesResponse
+	6,  // 51: lottery.v1.LotteryService.WatchDrawEvents:output_type -> lottery.v1.DrawEvent
+	36, // [36:52] is the sub-list for method output_type
+	20, // [20:36] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_lottery_v1_lottery_proto_init() }
+func file_lottery_v1_lottery_proto_init() {
+	if File_lottery_v1_lottery_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_lottery_v1_lottery_proto_rawDesc), len(file_lottery_v1_lottery_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lottery_v1_lottery_proto_goTypes,
+		DependencyIndexes: file_lottery_v1_lottery_proto_depIdxs,
+		MessageInfos:      file_lottery_v1_lottery_proto_msgTypes,
+	}.Build()
+	File_lottery_v1_lottery_proto = out.File
+	file_lottery_v1_lottery_proto_goTypes = nil
+	file_lottery_v1_lottery_proto_depIdxs = nil
+}
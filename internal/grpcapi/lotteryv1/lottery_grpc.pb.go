@@ -0,0 +1,727 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.28.3
+// source: lottery/v1/lottery.proto
+
+package lotteryv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LotteryService_RegisterUser_FullMethodName      = "/lottery.v1.LotteryService/RegisterUser"
+	LotteryService_Login_FullMethodName             = "/lottery.v1.LotteryService/Login"
+	LotteryService_GetUser_FullMethodName           = "/lottery.v1.LotteryService/GetUser"
+	LotteryService_CreateDraw_FullMethodName        = "/lottery.v1.LotteryService/CreateDraw"
+	LotteryService_GetDraw_FullMethodName           = "/lottery.v1.LotteryService/GetDraw"
+	LotteryService_GetPendingDraw_FullMethodName    = "/lottery.v1.LotteryService/GetPendingDraw"
+	LotteryService_ListDraws_FullMethodName         = "/lottery.v1.LotteryService/ListDraws"
+	LotteryService_ExecuteDraw_FullMethodName       = "/lottery.v1.LotteryService/ExecuteDraw"
+	LotteryService_CreateTicket_FullMethodName      = "/lottery.v1.LotteryService/CreateTicket"
+	LotteryService_GetTicket_FullMethodName         = "/lottery.v1.LotteryService/GetTicket"
+	LotteryService_GetTicketBySerial_FullMethodName = "/lottery.v1.LotteryService/GetTicketBySerial"
+	LotteryService_CancelTicket_FullMethodName      = "/lottery.v1.LotteryService/CancelTicket"
+	LotteryService_ListTickets_FullMethodName       = "/lottery.v1.LotteryService/ListTickets"
+	LotteryService_GetPrizeByTicket_FullMethodName  = "/lottery.v1.LotteryService/GetPrizeByTicket"
+	LotteryService_ListPrizes_FullMethodName        = "/lottery.v1.LotteryService/ListPrizes"
+	LotteryService_WatchDrawEvents_FullMethodName   = "/lottery.v1.LotteryService/WatchDrawEvents"
+)
+
+// LotteryServiceClient is the client API for LotteryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LotteryService exposes the same operations as the /api/v1 HTTP routes.
+// Errors use the standard gRPC codes: NOT_FOUND, INVALID_ARGUMENT (with the
+// offending fields in a BadRequest detail), FAILED_PRECONDITION for
+// insufficient balance or a closed draw, ALREADY_EXISTS, ABORTED for other
+// conflicts, UNAUTHENTICATED and PERMISSION_DENIED.
+//
+// Calls authenticate like the HTTP API, with "authorization: Bearer <token>"
+// metadata holding the admin token or a session token. CreateDraw and
+// ExecuteDraw need an admin; ListTickets needs an admin, or the session of
+// the user whose tickets it lists. GetTicketBySerial is open to anyone but
+// only includes the ticket for its owner or an admin.
+type LotteryServiceClient interface {
+	RegisterUser(ctx context.Context, in *RegisterUserRequest, opts ...grpc.CallOption) (*User, error)
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*Session, error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	CreateDraw(ctx context.Context, in *CreateDrawRequest, opts ...grpc.CallOption) (*Draw, error)
+	GetDraw(ctx context.Context, in *GetDrawRequest, opts ...grpc.CallOption) (*Draw, error)
+	GetPendingDraw(ctx context.Context, in *GetPendingDrawRequest, opts ...grpc.CallOption) (*Draw, error)
+	ListDraws(ctx context.Context, in *ListDrawsRequest, opts ...grpc.CallOption) (*ListDrawsResponse, error)
+	ExecuteDraw(ctx context.Context, in *ExecuteDrawRequest, opts ...grpc.CallOption) (*Draw, error)
+	CreateTicket(ctx context.Context, in *CreateTicketRequest, opts ...grpc.CallOption) (*Ticket, error)
+	GetTicket(ctx context.Context, in *GetTicketRequest, opts ...grpc.CallOption) (*Ticket, error)
+	// GetTicketBySerial checks a serial, like GET /api/v1/serials/{serial}.
+	// The ticket is only included for its owner's session or an admin.
+	GetTicketBySerial(ctx context.Context, in *GetTicketBySerialRequest, opts ...grpc.CallOption) (*SerialCheck, error)
+	CancelTicket(ctx context.Context, in *CancelTicketRequest, opts ...grpc.CallOption) (*Ticket, error)
+	ListTickets(ctx context.Context, in *ListTicketsRequest, opts ...grpc.CallOption) (*ListTicketsResponse, error)
+	GetPrizeByTicket(ctx context.Context, in *GetPrizeByTicketRequest, opts ...grpc.CallOption) (*Prize, error)
+	ListPrizes(ctx context.Context, in *ListPrizesRequest, opts ...grpc.CallOption) (*ListPrizesResponse, error)
+	// WatchDrawEvents streams draw events as they are published, like
+	// GET /api/v1/events. Events published before the call are not replayed.
+	WatchDrawEvents(ctx context.Context, in *WatchDrawEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DrawEvent], error)
+}
+
+type lotteryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLotteryServiceClient(cc grpc.ClientConnInterface) LotteryServiceClient {
+	return &lotteryServiceClient{cc}
+}
+
+func (c *lotteryServiceClient) RegisterUser(ctx context.Context, in *RegisterUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, LotteryService_RegisterUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, LotteryService_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, LotteryService_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) CreateDraw(ctx context.Context, in *CreateDrawRequest, opts ...grpc.CallOption) (*Draw, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Draw)
+	err := c.cc.Invoke(ctx, LotteryService_CreateDraw_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) GetDraw(ctx context.Context, in *GetDrawRequest, opts ...grpc.CallOption) (*Draw, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Draw)
+	err := c.cc.Invoke(ctx, LotteryService_GetDraw_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) GetPendingDraw(ctx context.Context, in *GetPendingDrawRequest, opts ...grpc.CallOption) (*Draw, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Draw)
+	err := c.cc.Invoke(ctx, LotteryService_GetPendingDraw_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) ListDraws(ctx context.Context, in *ListDrawsRequest, opts ...grpc.CallOption) (*ListDrawsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDrawsResponse)
+	err := c.cc.Invoke(ctx, LotteryService_ListDraws_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) ExecuteDraw(ctx context.Context, in *ExecuteDrawRequest, opts ...grpc.CallOption) (*Draw, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Draw)
+	err := c.cc.Invoke(ctx, LotteryService_ExecuteDraw_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) CreateTicket(ctx context.Context, in *CreateTicketRequest, opts ...grpc.CallOption) (*Ticket, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ticket)
+	err := c.cc.Invoke(ctx, LotteryService_CreateTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) GetTicket(ctx context.Context, in *GetTicketRequest, opts ...grpc.CallOption) (*Ticket, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ticket)
+	err := c.cc.Invoke(ctx, LotteryService_GetTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) GetTicketBySerial(ctx context.Context, in *GetTicketBySerialRequest, opts ...grpc.CallOption) (*SerialCheck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SerialCheck)
+	err := c.cc.Invoke(ctx, LotteryService_GetTicketBySerial_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) CancelTicket(ctx context.Context, in *CancelTicketRequest, opts ...grpc.CallOption) (*Ticket, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ticket)
+	err := c.cc.Invoke(ctx, LotteryService_CancelTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) ListTickets(ctx context.Context, in *ListTicketsRequest, opts ...grpc.CallOption) (*ListTicketsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTicketsResponse)
+	err := c.cc.Invoke(ctx, LotteryService_ListTickets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) GetPrizeByTicket(ctx context.Context, in *GetPrizeByTicketRequest, opts ...grpc.CallOption) (*Prize, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Prize)
+	err := c.cc.Invoke(ctx, LotteryService_GetPrizeByTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) ListPrizes(ctx context.Context, in *ListPrizesRequest, opts ...grpc.CallOption) (*ListPrizesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPrizesResponse)
+	err := c.cc.Invoke(ctx, LotteryService_ListPrizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lotteryServiceClient) WatchDrawEvents(ctx context.Context, in *WatchDrawEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DrawEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LotteryService_ServiceDesc.Streams[0], LotteryService_WatchDrawEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchDrawEventsRequest, DrawEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LotteryService_WatchDrawEventsClient = grpc.ServerStreamingClient[DrawEvent]
+
+// LotteryServiceServer is the server API for LotteryService service.
+// All implementations must embed UnimplementedLotteryServiceServer
+// for forward compatibility.
+//
+// LotteryService exposes the same operations as the /api/v1 HTTP routes.
+// Errors use the standard gRPC codes: NOT_FOUND, INVALID_ARGUMENT (with the
+// offending fields in a BadRequest detail), FAILED_PRECONDITION for
+// insufficient balance or a closed draw, ALREADY_EXISTS, ABORTED for other
+// conflicts, UNAUTHENTICATED and PERMISSION_DENIED.
+//
+// Calls authenticate like the HTTP API, with "authorization: Bearer <token>"
+// metadata holding the admin token or a session token. CreateDraw and
+// ExecuteDraw need an admin; ListTickets needs an admin, or the session of
+// the user whose tickets it lists. GetTicketBySerial is open to anyone but
+// only includes the ticket for its owner or an admin.
+type LotteryServiceServer interface {
+	RegisterUser(context.Context, *RegisterUserRequest) (*User, error)
+	Login(context.Context, *LoginRequest) (*Session, error)
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	CreateDraw(context.Context, *CreateDrawRequest) (*Draw, error)
+	GetDraw(context.Context, *GetDrawRequest) (*Draw, error)
+	GetPendingDraw(context.Context, *GetPendingDrawRequest) (*Draw, error)
+	ListDraws(context.Context, *ListDrawsRequest) (*ListDrawsResponse, error)
+	ExecuteDraw(context.Context, *ExecuteDrawRequest) (*Draw, error)
+	CreateTicket(context.Context, *CreateTicketRequest) (*Ticket, error)
+	GetTicket(context.Context, *GetTicketRequest) (*Ticket, error)
+	// GetTicketBySerial checks a serial, like GET /api/v1/serials/{serial}.
+	// The ticket is only included for its owner's session or an admin.
+	GetTicketBySerial(context.Context, *GetTicketBySerialRequest) (*SerialCheck, error)
+	CancelTicket(context.Context, *CancelTicketRequest) (*Ticket, error)
+	ListTickets(context.Context, *ListTicketsRequest) (*ListTicketsResponse, error)
+	GetPrizeByTicket(context.Context, *GetPrizeByTicketRequest) (*Prize, error)
+	ListPrizes(context.Context, *ListPrizesRequest) (*ListPrizesResponse, error)
+	// WatchDrawEvents streams draw events as they are published, like
+	// GET /api/v1/events. Events published before the call are not replayed.
+	WatchDrawEvents(*WatchDrawEventsRequest, grpc.ServerStreamingServer[DrawEvent]) error
+	mustEmbedUnimplementedLotteryServiceServer()
+}
+
+// UnimplementedLotteryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLotteryServiceServer struct{}
+
+func (UnimplementedLotteryServiceServer) RegisterUser(context.Context, *RegisterUserRequest) (*User, error) {
+	return nil, status.Error(codes.Unimplemented, "method RegisterUser not implemented")
+}
+func (UnimplementedLotteryServiceServer) Login(context.Context, *LoginRequest) (*Session, error) {
+	return nil, status.Error(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedLotteryServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedLotteryServiceServer) CreateDraw(context.Context, *CreateDrawRequest) (*Draw, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateDraw not implemented")
+}
+func (UnimplementedLotteryServiceServer) GetDraw(context.Context, *GetDrawRequest) (*Draw, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDraw not implemented")
+}
+func (UnimplementedLotteryServiceServer) GetPendingDraw(context.Context, *GetPendingDrawRequest) (*Draw, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPendingDraw not implemented")
+}
+func (UnimplementedLotteryServiceServer) ListDraws(context.Context, *ListDrawsRequest) (*ListDrawsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDraws not implemented")
+}
+func (UnimplementedLotteryServiceServer) ExecuteDraw(context.Context, *ExecuteDrawRequest) (*Draw, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExecuteDraw not implemented")
+}
+func (UnimplementedLotteryServiceServer) CreateTicket(context.Context, *CreateTicketRequest) (*Ticket, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateTicket not implemented")
+}
+func (UnimplementedLotteryServiceServer) GetTicket(context.Context, *GetTicketRequest) (*Ticket, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTicket not implemented")
+}
+func (UnimplementedLotteryServiceServer) GetTicketBySerial(context.Context, *GetTicketBySerialRequest) (*SerialCheck, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTicketBySerial not implemented")
+}
+func (UnimplementedLotteryServiceServer) CancelTicket(context.Context, *CancelTicketRequest) (*Ticket, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelTicket not implemented")
+}
+func (UnimplementedLotteryServiceServer) ListTickets(context.Context, *ListTicketsRequest) (*ListTicketsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTickets not implemented")
+}
+func (UnimplementedLotteryServiceServer) GetPrizeByTicket(context.Context, *GetPrizeByTicketRequest) (*Prize, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPrizeByTicket not implemented")
+}
+func (UnimplementedLotteryServiceServer) ListPrizes(context.Context, *ListPrizesRequest) (*ListPrizesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPrizes not implemented")
+}
+func (UnimplementedLotteryServiceServer) WatchDrawEvents(*WatchDrawEventsRequest, grpc.ServerStreamingServer[DrawEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchDrawEvents not implemented")
+}
+func (UnimplementedLotteryServiceServer) mustEmbedUnimplementedLotteryServiceServer() {}
+func (UnimplementedLotteryServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeLotteryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LotteryServiceServer will
+// result in compilation errors.
+type UnsafeLotteryServiceServer interface {
+	mustEmbedUnimplementedLotteryServiceServer()
+}
+
+func RegisterLotteryServiceServer(s grpc.ServiceRegistrar, srv LotteryServiceServer) {
+	// If the following call panics, it indicates UnimplementedLotteryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LotteryService_ServiceDesc, srv)
+}
+
+func _LotteryService_RegisterUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).RegisterUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_RegisterUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).RegisterUser(ctx, req.(*RegisterUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_CreateDraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).CreateDraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_CreateDraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).CreateDraw(ctx, req.(*CreateDrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_GetDraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).GetDraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_GetDraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).GetDraw(ctx, req.(*GetDrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_GetPendingDraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPendingDrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).GetPendingDraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_GetPendingDraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).GetPendingDraw(ctx, req.(*GetPendingDrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_ListDraws_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDrawsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).ListDraws(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_ListDraws_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).ListDraws(ctx, req.(*ListDrawsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_ExecuteDraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteDrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).ExecuteDraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_ExecuteDraw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).ExecuteDraw(ctx, req.(*ExecuteDrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_CreateTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).CreateTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_CreateTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).CreateTicket(ctx, req.(*CreateTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_GetTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).GetTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_GetTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).GetTicket(ctx, req.(*GetTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_GetTicketBySerial_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTicketBySerialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).GetTicketBySerial(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_GetTicketBySerial_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).GetTicketBySerial(ctx, req.(*GetTicketBySerialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_CancelTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).CancelTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_CancelTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).CancelTicket(ctx, req.(*CancelTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_ListTickets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTicketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).ListTickets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_ListTickets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).ListTickets(ctx, req.(*ListTicketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_GetPrizeByTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPrizeByTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).GetPrizeByTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_GetPrizeByTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).GetPrizeByTicket(ctx, req.(*GetPrizeByTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_ListPrizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPrizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LotteryServiceServer).ListPrizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LotteryService_ListPrizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LotteryServiceServer).ListPrizes(ctx, req.(*ListPrizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LotteryService_WatchDrawEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDrawEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LotteryServiceServer).WatchDrawEvents(m, &grpc.GenericServerStream[WatchDrawEventsRequest, DrawEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LotteryService_WatchDrawEventsServer = grpc.ServerStreamingServer[DrawEvent]
+
+// LotteryService_ServiceDesc is the grpc.ServiceDesc for LotteryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LotteryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lottery.v1.LotteryService",
+	HandlerType: (*LotteryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterUser",
+			Handler:    _LotteryService_RegisterUser_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _LotteryService_Login_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _LotteryService_GetUser_Handler,
+		},
+		{
+			MethodName: "CreateDraw",
+			Handler:    _LotteryService_CreateDraw_Handler,
+		},
+		{
+			MethodName: "GetDraw",
+			Handler:    _LotteryService_GetDraw_Handler,
+		},
+		{
+			MethodName: "GetPendingDraw",
+			Handler:    _LotteryService_GetPendingDraw_Handler,
+		},
+		{
+			MethodName: "ListDraws",
+			Handler:    _LotteryService_ListDraws_Handler,
+		},
+		{
+			MethodName: "ExecuteDraw",
+			Handler:    _LotteryService_ExecuteDraw_Handler,
+		},
+		{
+			MethodName: "CreateTicket",
+			Handler:    _LotteryService_CreateTicket_Handler,
+		},
+		{
+			MethodName: "GetTicket",
+			Handler:    _LotteryService_GetTicket_Handler,
+		},
+		{
+			MethodName: "GetTicketBySerial",
+			Handler:    _LotteryService_GetTicketBySerial_Handler,
+		},
+		{
+			MethodName: "CancelTicket",
+			Handler:    _LotteryService_CancelTicket_Handler,
+		},
+		{
+			MethodName: "ListTickets",
+			Handler:    _LotteryService_ListTickets_Handler,
+		},
+		{
+			MethodName: "GetPrizeByTicket",
+			Handler:    _LotteryService_GetPrizeByTicket_Handler,
+		},
+		{
+			MethodName: "ListPrizes",
+			Handler:    _LotteryService_ListPrizes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDrawEvents",
+			Handler:       _LotteryService_WatchDrawEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lottery/v1/lottery.proto",
+}
@@ -0,0 +1,273 @@
+// Package grpcapi serves the lottery over gRPC, on top of the same
+// LotteryService as the HTTP handlers. The protobuf definitions live in
+// proto/lottery/v1; regenerate lotteryv1 with go generate after editing them.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=LotterySystem --go-grpc_out=../.. --go-grpc_opt=module=LotterySystem lottery/v1/lottery.proto
+
+import (
+	"LotterySystem/internal/grpcapi/lotteryv1"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"context"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const eventBuffer = 64
+
+type Server struct {
+	lotteryv1.UnimplementedLotteryServiceServer
+	service  *services.LotteryService
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func NewServer(s *services.LotteryService) *Server {
+	return &Server{service: s, done: make(chan struct{})}
+}
+
+// Shutdown ends open WatchDrawEvents streams so GracefulStop does not wait
+// on them forever.
+func (s *Server) Shutdown() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// actor names the caller for the audit log from the "authorization: Bearer"
+// metadata, as the HTTP API does from the header.
+func (s *Server) actor(ctx context.Context) string {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+	}
+	return s.service.ResolveActor(bearerToken(ctx), addr)
+}
+
+func (s *Server) Register(g *grpc.Server) {
+	lotteryv1.RegisterLotteryServiceServer(g, s)
+}
+
+func (s *Server) RegisterUser(_ context.Context, req *lotteryv1.RegisterUserRequest) (*lotteryv1.User, error) {
+	user, err := s.service.RegisterUser(req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toUser(user), nil
+}
+
+func (s *Server) Login(_ context.Context, req *lotteryv1.LoginRequest) (*lotteryv1.Session, error) {
+	user, err := s.service.LoginUser(req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toSession(s.service.IssueSession(user.ID)), nil
+}
+
+func (s *Server) GetUser(_ context.Context, req *lotteryv1.GetUserRequest) (*lotteryv1.User, error) {
+	user, err := s.service.GetUser(req.GetUserId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toUser(user), nil
+}
+
+func (s *Server) CreateDraw(ctx context.Context, _ *lotteryv1.CreateDrawRequest) (*lotteryv1.Draw, error) {
+	draw, err := s.service.CreateDraw(s.actor(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toDraw(draw), nil
+}
+
+func (s *Server) GetDraw(_ context.Context, req *lotteryv1.GetDrawRequest) (*lotteryv1.Draw, error) {
+	draw, err := s.service.GetDraw(req.GetDrawId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toDraw(draw), nil
+}
+
+func (s *Server) GetPendingDraw(context.Context, *lotteryv1.GetPendingDrawRequest) (*lotteryv1.Draw, error) {
+	draw, err := s.service.GetPendingDraw()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toDraw(draw), nil
+}
+
+func (s *Server) ListDraws(_ context.Context, req *lotteryv1.ListDrawsRequest) (*lotteryv1.ListDrawsResponse, error) {
+	page, err := s.service.QueryDraws(models.DrawFilter{
+		ListOptions: listOptions(req.GetOptions()),
+		Status:      req.GetStatus(),
+		From:        fromTimestamp(req.GetFrom()),
+		To:          fromTimestamp(req.GetTo()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &lotteryv1.ListDrawsResponse{NextCursor: page.NextCursor}
+	for _, d := range page.Items {
+		resp.Draws = append(resp.Draws, toDraw(d))
+	}
+	return resp, nil
+}
+
+func (s *Server) ExecuteDraw(ctx context.Context, req *lotteryv1.ExecuteDrawRequest) (*lotteryv1.Draw, error) {
+	draw, err := s.service.ExecuteDraw(s.actor(ctx), req.GetDrawId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toDraw(draw), nil
+}
+
+func (s *Server) CreateTicket(_ context.Context, req *lotteryv1.CreateTicketRequest) (*lotteryv1.Ticket, error) {
+	numbers := make([]int, len(req.GetNumbers()))
+	for i, n := range req.GetNumbers() {
+		numbers[i] = int(n)
+	}
+
+	ticket, err := s.service.CreateTicket(req.GetUserId(), req.GetDrawId(), numbers)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTicket(ticket), nil
+}
+
+func (s *Server) GetTicket(_ context.Context, req *lotteryv1.GetTicketRequest) (*lotteryv1.Ticket, error) {
+	ticket, err := s.service.GetTicket(req.GetTicketId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTicket(ticket), nil
+}
+
+// GetTicketBySerial shows anyone holding a serial whether it is valid and
+// the status of its ticket and draw; only the owner or an admin gets the
+// ticket, as over HTTP.
+func (s *Server) GetTicketBySerial(ctx context.Context, req *lotteryv1.GetTicketBySerialRequest) (*lotteryv1.SerialCheck, error) {
+	ticket, err := s.service.GetTicketBySerial(req.GetSerial())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	check := &lotteryv1.SerialCheck{
+		Valid:  true,
+		Serial: ticket.Serial,
+		DrawId: ticket.DrawID,
+		Status: ticket.Status,
+	}
+	if draw, err := s.service.GetDraw(ticket.DrawID); err == nil {
+		check.DrawStatus = draw.Status
+	}
+	if s.ownsTicket(bearerToken(ctx), ticket) {
+		check.Ticket = toTicket(ticket)
+	}
+	return check, nil
+}
+
+func (s *Server) CancelTicket(_ context.Context, req *lotteryv1.CancelTicketRequest) (*lotteryv1.Ticket, error) {
+	ticket, err := s.service.CancelTicket(req.GetUserId(), req.GetTicketId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTicket(ticket), nil
+}
+
+func (s *Server) ListTickets(_ context.Context, req *lotteryv1.ListTicketsRequest) (*lotteryv1.ListTicketsResponse, error) {
+	page, err := s.service.QueryTickets(models.TicketFilter{
+		ListOptions: listOptions(req.GetOptions()),
+		UserID:      req.GetUserId(),
+		DrawID:      req.GetDrawId(),
+		Status:      req.GetStatus(),
+		MinMatches:  int(req.GetMinMatches()),
+		From:        fromTimestamp(req.GetFrom()),
+		To:          fromTimestamp(req.GetTo()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &lotteryv1.ListTicketsResponse{NextCursor: page.NextCursor}
+	for _, t := range page.Items {
+		resp.Tickets = append(resp.Tickets, toTicket(t))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetPrizeByTicket(_ context.Context, req *lotteryv1.GetPrizeByTicketRequest) (*lotteryv1.Prize, error) {
+	prize, err := s.service.GetPrizeByTicket(req.GetTicketId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPrize(prize), nil
+}
+
+func (s *Server) ListPrizes(_ context.Context, req *lotteryv1.ListPrizesRequest) (*lotteryv1.ListPrizesResponse, error) {
+	page, err := s.service.QueryPrizes(models.PrizeFilter{
+		ListOptions: listOptions(req.GetOptions()),
+		Type:        models.PrizeType(req.GetType()),
+		DrawID:      req.GetDrawId(),
+		UserID:      req.GetUserId(),
+		MinMatches:  int(req.GetMinMatches()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &lotteryv1.ListPrizesResponse{NextCursor: page.NextCursor}
+	for _, p := range page.Items {
+		resp.Prizes = append(resp.Prizes, toPrize(p))
+	}
+	return resp, nil
+}
+
+// WatchDrawEvents relays the service event bus until the client goes away.
+func (s *Server) WatchDrawEvents(req *lotteryv1.WatchDrawEventsRequest, stream grpc.ServerStreamingServer[lotteryv1.DrawEvent]) error {
+	types := map[services.EventType]bool{}
+	for _, t := range req.GetTypes() {
+		types[services.EventType(t)] = true
+	}
+
+	events, unsubscribe := s.service.Events().Subscribe(eventBuffer)
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if req.GetDrawId() != "" && e.DrawID != req.GetDrawId() {
+				continue
+			}
+			if len(types) > 0 && !types[e.Type] {
+				continue
+			}
+
+			event, err := toDrawEvent(e)
+			if err != nil {
+				return toStatus(err)
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func listOptions(o *lotteryv1.ListOptions) models.ListOptions {
+	return models.NewListOptions(o.GetSort(), o.GetAscending(), int(o.GetLimit()), o.GetCursor())
+}
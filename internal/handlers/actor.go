@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"LotterySystem/internal/services"
+	"net"
+	"net/http"
+	"strings"
+)
+
+func bearerToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return ""
+}
+
+// actor names who made the request, for the audit log.
+func actor(s *services.LotteryService, r *http.Request) string {
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+	return s.ResolveActor(bearerToken(r), addr)
+}
+
+// requireAdmin lets a request through only with the admin token or an
+// admin's session token as the bearer token.
+func requireAdmin(s *services.LotteryService, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.IsAdmin(bearerToken(r)) {
+			writeErrorResponse(w, http.StatusUnauthorized, errorResponse{Code: "unauthorized", Message: "Admin token required"})
+			return
+		}
+		next(w, r)
+	}
+}
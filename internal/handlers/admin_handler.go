@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"LotterySystem/internal/models"
 	"LotterySystem/internal/services"
 	"encoding/json"
 	"net/http"
@@ -15,11 +16,43 @@ func NewAdminHandler(s *services.LotteryService) *AdminHandler {
 }
 
 func (h *AdminHandler) Register(mux *http.ServeMux) {
-	mux.HandleFunc("/api/admin/draws", h.handleDraws)
-	mux.HandleFunc("/api/admin/draws/execute", h.executeDraw)
-	mux.HandleFunc("/api/admin/draws/pending", h.getPendingDraw)
-	mux.HandleFunc("/api/admin/stats", h.getStats)
-	mux.HandleFunc("/api/admin/prizes", h.getPrizes)
+	mux.HandleFunc("/api/admin/draws", deprecated(apiV1+"/draws", unpaged(h.handleDraws)))
+	mux.HandleFunc("/api/admin/draws/execute", deprecated(apiV1+"/draws/{id}/execute", requireAdmin(h.service, h.executeDraw)))
+	mux.HandleFunc("/api/admin/draws/pending", deprecated(apiV1+"/draws/pending", h.getPendingDraw))
+	mux.HandleFunc("/api/admin/stats", deprecated(apiV1+"/stats", h.getStats))
+	mux.HandleFunc("/api/admin/prizes", deprecated(apiV1+"/prizes", unpaged(h.getPrizes)))
+	mux.HandleFunc("/api/admin/tickets/cancelled", deprecated(apiV1+"/cancellations", h.getCancelledTickets))
+
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *AdminHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: apiV1 + "/draws", Summary: "List draws", Tag: "draws",
+			Query: append([]string{"status", "from", "to"}, listQuery...), Response: []models.Draw{}, Handler: h.listDraws},
+		{Method: http.MethodPost, Path: apiV1 + "/draws", Summary: "Open a new draw (admin token)", Tag: "draws",
+			Response: models.Draw{}, Status: http.StatusCreated, Handler: requireAdmin(h.service, h.createDrawV1)},
+		{Method: http.MethodGet, Path: apiV1 + "/draws/pending", Summary: "Get the draw accepting tickets", Tag: "draws",
+			Response: models.Draw{}, Handler: h.getPendingDraw},
+		{Method: http.MethodGet, Path: apiV1 + "/draws/{id}", Summary: "Get a draw", Tag: "draws",
+			Response: models.Draw{}, Handler: h.getDrawV1},
+		{Method: http.MethodPost, Path: apiV1 + "/draws/{id}/execute", Summary: "Draw the winning numbers and settle (admin token)", Tag: "draws",
+			Response: models.Draw{}, Handler: requireAdmin(h.service, h.executeDrawV1)},
+		{Method: http.MethodPost, Path: apiV1 + "/draws/{id}/cancel", Summary: "Cancel an open draw and refund its tickets (admin token)", Tag: "draws",
+			Response: models.Draw{}, Handler: requireAdmin(h.service, h.cancelDrawV1)},
+		{Method: http.MethodPost, Path: apiV1 + "/draws/{id}/resettle", Summary: "Settle a completed draw again, applying only what is missing (admin token)", Tag: "draws",
+			Response: models.Settlement{}, Handler: requireAdmin(h.service, h.resettleDraw)},
+		{Method: http.MethodGet, Path: apiV1 + "/draws/{id}/settlement", Summary: "Get the progress of a draw's latest settlement (admin token)", Tag: "draws",
+			Response: models.Settlement{}, Handler: requireAdmin(h.service, h.getSettlement)},
+		{Method: http.MethodGet, Path: apiV1 + "/draws/{id}/reconciliation", Summary: "Compare a draw's expected and actual payouts (admin token)", Tag: "draws",
+			Response: models.Reconciliation{}, Handler: requireAdmin(h.service, h.reconcileDraw)},
+		{Method: http.MethodGet, Path: apiV1 + "/prizes", Summary: "List awarded prizes", Tag: "prizes",
+			Query: append([]string{"type", "draw_id", "user_id", "min_matches"}, listQuery...), Response: []models.Prize{}, Handler: h.getPrizes},
+		{Method: http.MethodGet, Path: apiV1 + "/cancellations", Summary: "List cancelled tickets", Tag: "tickets",
+			Response: []models.Ticket{}, Handler: h.getCancelledTickets},
+		{Method: http.MethodGet, Path: apiV1 + "/stats", Summary: "Get system statistics", Tag: "admin",
+			Response: map[string]interface{}{}, Handler: h.getStats},
+	}
 }
 
 func (h *AdminHandler) handleDraws(w http.ResponseWriter, r *http.Request) {
@@ -27,16 +60,16 @@ func (h *AdminHandler) handleDraws(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		h.listDraws(w, r)
 	case http.MethodPost:
-		h.createDraw(w, r)
+		requireAdmin(h.service, h.createDraw)(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 	}
 }
 
-func (h *AdminHandler) createDraw(w http.ResponseWriter, _ *http.Request) {
-	draw, err := h.service.CreateDraw()
+func (h *AdminHandler) createDraw(w http.ResponseWriter, r *http.Request) {
+	draw, err := h.service.CreateDraw(actor(h.service, r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -45,28 +78,41 @@ func (h *AdminHandler) createDraw(w http.ResponseWriter, _ *http.Request) {
 		"success": true,
 		"draw":    draw,
 	}); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 	}
 }
 
-func (h *AdminHandler) listDraws(w http.ResponseWriter, _ *http.Request) {
-	draws := h.service.ListDraws()
+func (h *AdminHandler) listDraws(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParser(r)
+	filter := models.DrawFilter{
+		ListOptions: q.listOptions(),
+		Status:      q.string("status"),
+		From:        q.time("from", false),
+		To:          q.time("to", true),
+	}
+	if err := q.err(); err != nil {
+		writeError(w, err)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(draws); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	page, err := h.service.QueryDraws(filter)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
+
+	writePage(w, r, page)
 }
 
 func (h *AdminHandler) getPendingDraw(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	draw, err := h.service.GetPendingDraw()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -76,7 +122,7 @@ func (h *AdminHandler) getPendingDraw(w http.ResponseWriter, r *http.Request) {
 
 func (h *AdminHandler) executeDraw(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
@@ -85,13 +131,13 @@ func (h *AdminHandler) executeDraw(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid request body")
 		return
 	}
 
-	draw, err := h.service.ExecuteDraw(req.DrawID)
+	draw, err := h.service.ExecuteDraw(actor(h.service, r), req.DrawID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
@@ -105,7 +151,7 @@ func (h *AdminHandler) executeDraw(w http.ResponseWriter, r *http.Request) {
 
 func (h *AdminHandler) getStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
@@ -117,12 +163,110 @@ func (h *AdminHandler) getStats(w http.ResponseWriter, r *http.Request) {
 
 func (h *AdminHandler) getPrizes(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	q := newQueryParser(r)
+	filter := models.PrizeFilter{
+		ListOptions: q.listOptions(),
+		Type:        models.PrizeType(q.string("type")),
+		DrawID:      q.string("draw_id"),
+		UserID:      q.string("user_id"),
+		MinMatches:  q.int("min_matches"),
+	}
+	if err := q.err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := h.service.QueryPrizes(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writePage(w, r, page)
+}
+
+func (h *AdminHandler) getCancelledTickets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
 		return
 	}
 
-	prizes := h.service.GetAllPrizes()
+	tickets := h.service.ListCancelledTickets()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(prizes)
+	json.NewEncoder(w).Encode(tickets)
+}
+
+func (h *AdminHandler) createDrawV1(w http.ResponseWriter, r *http.Request) {
+	draw, err := h.service.CreateDraw(actor(h.service, r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, draw)
+}
+
+func (h *AdminHandler) getDrawV1(w http.ResponseWriter, r *http.Request) {
+	draw, err := h.service.GetDraw(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, draw)
+}
+
+func (h *AdminHandler) executeDrawV1(w http.ResponseWriter, r *http.Request) {
+	draw, err := h.service.ExecuteDraw(actor(h.service, r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, draw)
+}
+
+func (h *AdminHandler) cancelDrawV1(w http.ResponseWriter, r *http.Request) {
+	draw, err := h.service.CancelDraw(actor(h.service, r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, draw)
+}
+
+func (h *AdminHandler) resettleDraw(w http.ResponseWriter, r *http.Request) {
+	st, err := h.service.ResettleDraw(actor(h.service, r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (h *AdminHandler) getSettlement(w http.ResponseWriter, r *http.Request) {
+	st, err := h.service.GetSettlement(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (h *AdminHandler) reconcileDraw(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.service.ReconcileDraw(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rec)
 }
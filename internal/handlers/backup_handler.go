@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/services"
+	"net/http"
+)
+
+// BackupHandler lets administrators back up a running server. Restoring
+// needs the server stopped, so it is only offered by lotteryctl.
+type BackupHandler struct {
+	service   *services.LotteryService
+	dir       string
+	retention backup.Retention
+}
+
+func NewBackupHandler(s *services.LotteryService, dir string, retention backup.Retention) *BackupHandler {
+	return &BackupHandler{service: s, dir: dir, retention: retention}
+}
+
+type backupResponse struct {
+	Backup backup.Info   `json:"backup"`
+	Pruned []backup.Info `json:"pruned"`
+}
+
+func (h *BackupHandler) Register(mux *http.ServeMux) {
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *BackupHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: apiV1 + "/backups", Summary: "Back up the data and prune old backups (admin token)", Tag: "admin",
+			Response: backupResponse{}, Status: http.StatusCreated, Handler: requireAdmin(h.service, h.create)},
+		{Method: http.MethodGet, Path: apiV1 + "/backups", Summary: "List backups, newest first (admin token)", Tag: "admin",
+			Response: []backup.Info{}, Handler: requireAdmin(h.service, h.list)},
+	}
+}
+
+func (h *BackupHandler) create(w http.ResponseWriter, r *http.Request) {
+	info, pruned, err := h.service.CreateBackup(actor(h.service, r), h.dir, h.retention)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if pruned == nil {
+		pruned = []backup.Info{}
+	}
+
+	writeJSON(w, http.StatusCreated, backupResponse{Backup: info, Pruned: pruned})
+}
+
+func (h *BackupHandler) list(w http.ResponseWriter, _ *http.Request) {
+	infos, err := backup.List(h.dir)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, infos)
+}
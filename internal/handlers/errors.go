@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"LotterySystem/internal/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// errorResponse is the body of every non-2xx JSON reply.
+type errorResponse struct {
+	Success bool              `json:"success"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// writeError maps a service error to its status code and machine-readable
+// code. Errors that are not service errors are reported as internal errors
+// without leaking their text.
+func writeError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+
+	resp := errorResponse{Code: code, Message: err.Error()}
+	var serr *services.Error
+	if errors.As(err, &serr) {
+		resp.Fields = serr.Fields
+	}
+	if status == http.StatusInternalServerError {
+		resp.Message = "Internal server error"
+		noteError(w, err)
+	}
+
+	writeErrorResponse(w, status, resp)
+}
+
+func writeBadRequest(w http.ResponseWriter, message string) {
+	writeErrorResponse(w, http.StatusBadRequest, errorResponse{Code: "bad_request", Message: message})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeErrorResponse(w, http.StatusMethodNotAllowed, errorResponse{Code: "method_not_allowed", Message: "Method not allowed"})
+}
+
+func writeRequired(w http.ResponseWriter, field, message string) {
+	writeError(w, services.NewValidationError(message, map[string]string{field: "is required"}))
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, resp errorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, services.ErrValidation):
+		return http.StatusUnprocessableEntity, "validation_failed"
+	case errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusConflict, "insufficient_balance"
+	case errors.Is(err, services.ErrDrawClosed):
+		return http.StatusConflict, "draw_closed"
+	case errors.Is(err, services.ErrConflict), errors.Is(err, services.ErrAlreadyExists):
+		return http.StatusConflict, "conflict"
+	case errors.Is(err, services.ErrUnauthorized):
+		return http.StatusUnauthorized, "unauthorized"
+	}
+	return http.StatusInternalServerError, "internal_error"
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"LotterySystem/internal/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sseHeartbeat = 15 * time.Second
+	sseBuffer    = 64
+)
+
+type EventHandler struct {
+	service  *services.LotteryService
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func NewEventHandler(s *services.LotteryService) *EventHandler {
+	return &EventHandler{service: s, done: make(chan struct{})}
+}
+
+// Shutdown ends every open stream. The server cannot drain connections that
+// never go idle, so it is called when shutdown starts.
+func (h *EventHandler) Shutdown() {
+	h.doneOnce.Do(func() { close(h.done) })
+}
+
+func (h *EventHandler) Register(mux *http.ServeMux) {
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *EventHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: apiV1 + "/events", Summary: "Stream draw events (Server-Sent Events)", Tag: "events",
+			Query: []string{"draw_id", "types"}, ContentType: "text/event-stream", Handler: h.streamEvents},
+	}
+}
+
+// streamEvents relays the service event bus as Server-Sent Events. Clients
+// can narrow the stream with draw_id and a comma-separated list of types.
+func (h *EventHandler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("streaming unsupported"))
+		return
+	}
+	// The stream outlives the server write timeout.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	drawID := r.URL.Query().Get("draw_id")
+	types := map[services.EventType]bool{}
+	for _, t := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types[services.EventType(t)] = true
+		}
+	}
+
+	events, unsubscribe := h.service.Events().Subscribe(sseBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.done:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if drawID != "" && e.DrawID != drawID {
+				continue
+			}
+			if len(types) > 0 && !types[e.Type] {
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+			flusher.Flush()
+		}
+	}
+}
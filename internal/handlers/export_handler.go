@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"LotterySystem/internal/export"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type ExportHandler struct {
+	service *services.LotteryService
+}
+
+func NewExportHandler(s *services.LotteryService) *ExportHandler {
+	return &ExportHandler{service: s}
+}
+
+func (h *ExportHandler) Register(mux *http.ServeMux) {
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *ExportHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: apiV1 + "/exports/{dataset}", Summary: "Export tickets, prizes, draws or ledger entries as CSV or JSON Lines (admin token)", Tag: "exports",
+			Query: []string{"format", "draw_id", "from", "to"}, ContentType: "text/csv", Handler: requireAdmin(h.service, h.export)},
+	}
+}
+
+// export streams the dataset row by row. Once the first row is out the
+// status can no longer change, so a later failure only ends the response
+// early and is logged.
+func (h *ExportHandler) export(w http.ResponseWriter, r *http.Request) {
+	dataset := r.PathValue("dataset")
+	if !export.IsDataset(dataset) {
+		writeErrorResponse(w, http.StatusNotFound, errorResponse{Code: "not_found", Message: "unknown export " + dataset})
+		return
+	}
+
+	q := newQueryParser(r)
+	filter := models.ExportFilter{
+		DrawID: q.string("draw_id"),
+		From:   q.time("from", false),
+		To:     q.time("to", true),
+	}
+	format, err := export.ParseFormat(q.string("format"))
+	if err != nil {
+		q.fields["format"] = "must be csv or jsonl"
+	}
+	if err := q.err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Large exports can take longer than the server write timeout allows.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	filename := dataset + "-" + time.Now().UTC().Format("20060102") + "." + string(format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// The status line is already sent, so a failure can only cut the
+	// download short and be logged.
+	if err := export.Write(w, h.service, dataset, format, filter); err != nil {
+		noteError(w, fmt.Errorf("export %s: %w", dataset, err))
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// HealthHandler serves the probes for the orchestrator and the admin
+// diagnostics. None of it is under /api/v1.
+type HealthHandler struct {
+	service    *services.LotteryService
+	dispatcher *services.WebhookDispatcher
+	dataDir    string
+	version    string
+	started    time.Time
+}
+
+func NewHealthHandler(s *services.LotteryService, d *services.WebhookDispatcher, dataDir, version string) *HealthHandler {
+	return &HealthHandler{
+		service:    s,
+		dispatcher: d,
+		dataDir:    dataDir,
+		version:    version,
+		started:    time.Now(),
+	}
+}
+
+func (h *HealthHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.healthz)
+	mux.HandleFunc("GET /readyz", h.readyz)
+	mux.HandleFunc("GET /debug/info", requireAdmin(h.service, h.debugInfo))
+}
+
+// healthz only shows the process is up and serving HTTP.
+func (h *HealthHandler) healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+type readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// readyz checks that every data file loaded and can be written, and that
+// the webhook worker is running.
+func (h *HealthHandler) readyz(w http.ResponseWriter, _ *http.Request) {
+	checks := map[string]string{
+		"storage":  "ok",
+		"webhooks": "ok",
+	}
+
+	var problems []string
+	for _, st := range h.storageStatus() {
+		switch {
+		case st.LoadError != "":
+			problems = append(problems, st.File+" not loaded")
+		case st.WriteError != "":
+			problems = append(problems, st.File+" not written")
+		}
+	}
+	if err := storage.CheckWritable(h.dataDir); err != nil {
+		problems = append(problems, "data directory not writable")
+	}
+	if len(problems) > 0 {
+		checks["storage"] = strings.Join(problems, "; ")
+	}
+	if !h.dispatcher.Running() {
+		checks["webhooks"] = "delivery worker not running"
+	}
+
+	status, resp := http.StatusOK, readiness{Status: "ready", Checks: checks}
+	for _, result := range checks {
+		if result != "ok" {
+			status, resp.Status = http.StatusServiceUnavailable, "not_ready"
+			break
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+type debugInfo struct {
+	Version       string               `json:"version"`
+	Revision      string               `json:"revision,omitempty"`
+	GoVersion     string               `json:"go_version"`
+	StartedAt     time.Time            `json:"started_at"`
+	Uptime        string               `json:"uptime"`
+	UptimeSeconds int64                `json:"uptime_seconds"`
+	Goroutines    int                  `json:"goroutines"`
+	DataDir       string               `json:"data_dir"`
+	DataDirBytes  int64                `json:"data_dir_bytes"`
+	LastWrite     *time.Time           `json:"last_write,omitempty"`
+	Repositories  []storage.FileStatus `json:"repositories"`
+}
+
+func (h *HealthHandler) debugInfo(w http.ResponseWriter, _ *http.Request) {
+	uptime := time.Since(h.started)
+	info := debugInfo{
+		Version:       h.version,
+		GoVersion:     runtime.Version(),
+		StartedAt:     h.started,
+		Uptime:        uptime.Truncate(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		DataDir:       h.dataDir,
+		Repositories:  h.storageStatus(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				info.Revision = s.Value
+			}
+		}
+	}
+	if size, err := storage.DirSize(h.dataDir); err == nil {
+		info.DataDirBytes = size
+	}
+	for _, st := range info.Repositories {
+		if st.LastWrite != nil && (info.LastWrite == nil || st.LastWrite.After(*info.LastWrite)) {
+			info.LastWrite = st.LastWrite
+		}
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (h *HealthHandler) storageStatus() []storage.FileStatus {
+	return append(h.service.StorageStatus(), h.dispatcher.StorageStatus()...)
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	IdempotencyHeader  = "Idempotency-Key"
+	maxIdempotencyKey  = 255
+	maxFingerprintBody = 1 << 20
+
+	// maxIdempotencyEntries caps how many keys are remembered at once; the
+	// oldest answered ones make room for new ones.
+	maxIdempotencyEntries = 10000
+	// maxIdempotentBody is the largest response kept for replay. Larger
+	// ones are not remembered, so a retry runs the request again.
+	maxIdempotentBody = 64 << 10
+)
+
+// perRequestHeaders are response headers that belong to the request that
+// set them, not to its response, so a replay does not repeat them. The
+// names are canonical, as http.Header keys them.
+var perRequestHeaders = map[string]bool{
+	http.CanonicalHeaderKey(RequestIDHeader): true,
+	"Date":                                   true,
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	done        bool
+	status      int
+	header      http.Header
+	body        []byte
+	expires     time.Time
+}
+
+// IdempotencyStore remembers the response to every mutating request that
+// carried an Idempotency-Key, so a retried request is answered from the cache
+// instead of being executed twice. Keys belong to the caller who sent them,
+// as named for the audit log, so one caller cannot replay another's
+// response. Answered requests are kept in repo too, and survive a restart.
+type IdempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	service   *services.LotteryService
+	repo      *storage.IdempotencyRepository
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+func NewIdempotencyStore(s *services.LotteryService, repo *storage.IdempotencyRepository, ttl time.Duration) *IdempotencyStore {
+	store := &IdempotencyStore{
+		ttl:     ttl,
+		service: s,
+		repo:    repo,
+		entries: make(map[string]*idempotencyEntry),
+	}
+	now := time.Now()
+	var expired []string
+	for _, resp := range repo.List() {
+		if now.After(resp.ExpiresAt) {
+			expired = append(expired, resp.Key)
+			continue
+		}
+		store.entries[resp.Key] = &idempotencyEntry{
+			fingerprint: resp.Fingerprint,
+			done:        true,
+			status:      resp.Status,
+			header:      resp.Header,
+			body:        resp.Body,
+			expires:     resp.ExpiresAt,
+		}
+	}
+	if len(expired) > 0 {
+		store.forget(expired)
+	}
+	return store
+}
+
+// Middleware applies idempotency keys to POST, PUT, PATCH and DELETE
+// requests. Requests without the header pass straight through.
+func (s *IdempotencyStore) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyHeader)
+		if key == "" || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKey {
+			writeBadRequest(w, "Idempotency key is too long")
+			return
+		}
+		key = actor(s.service, r) + " " + key
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxFingerprintBody))
+		if err != nil {
+			writeBadRequest(w, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+		fingerprint := requestFingerprint(r, body)
+		entry, status := s.begin(key, fingerprint)
+		switch status {
+		case beginConflict:
+			writeErrorResponse(w, http.StatusConflict, errorResponse{Code: "idempotency_key_reused", Message: "Idempotency key was already used with a different request"})
+			return
+		case beginInFlight:
+			writeErrorResponse(w, http.StatusConflict, errorResponse{Code: "idempotency_key_in_use", Message: "A request with this idempotency key is still in progress"})
+			return
+		case beginFull:
+			writeErrorResponse(w, http.StatusServiceUnavailable, errorResponse{Code: "idempotency_keys_exhausted", Message: "Too many requests with idempotency keys are in progress"})
+			return
+		case beginReplay:
+			for k, v := range entry.header {
+				if !perRequestHeaders[k] {
+					w.Header()[k] = v
+				}
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(entry.status)
+			w.Write(entry.body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			if p := recover(); p != nil {
+				s.abandon(key)
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+		s.finish(key, fingerprint, rec)
+	})
+}
+
+type beginStatus int
+
+const (
+	beginNew beginStatus = iota
+	beginReplay
+	beginInFlight
+	beginConflict
+	beginFull
+)
+
+// begin looks key up and, if it is new, claims it for this request. The
+// store's lock is held across the writes to repo so they land in the order
+// the entries changed.
+func (s *IdempotencyStore) begin(key, fingerprint string) (*idempotencyEntry, beginStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		var expired []string
+		for k, e := range s.entries {
+			if e.done && now.After(e.expires) {
+				expired = append(expired, k)
+			}
+		}
+		s.forget(expired)
+		s.lastSweep = now
+	}
+
+	entry, ok := s.entries[key]
+	if ok && entry.done && now.After(entry.expires) {
+		ok = false
+	}
+	if !ok {
+		if _, replacing := s.entries[key]; !replacing && len(s.entries) >= maxIdempotencyEntries && !s.evictOldest() {
+			return nil, beginFull
+		}
+		s.entries[key] = &idempotencyEntry{fingerprint: fingerprint}
+		return nil, beginNew
+	}
+
+	if entry.fingerprint != fingerprint {
+		return nil, beginConflict
+	}
+	if !entry.done {
+		return nil, beginInFlight
+	}
+	return entry, beginReplay
+}
+
+// evictOldest forgets the answered entry that expires first, reporting
+// whether there was one.
+func (s *IdempotencyStore) evictOldest() bool {
+	oldest := ""
+	for k, e := range s.entries {
+		if e.done && (oldest == "" || e.expires.Before(s.entries[oldest].expires)) {
+			oldest = k
+		}
+	}
+	if oldest == "" {
+		return false
+	}
+	s.forget([]string{oldest})
+	return true
+}
+
+// forget drops keys from memory and from repo. The caller holds s.mu, or
+// has the store to itself.
+func (s *IdempotencyStore) forget(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	for _, k := range keys {
+		delete(s.entries, k)
+	}
+	if err := s.repo.Delete(keys...); err != nil {
+		slog.Warn("idempotency keys not removed from disk", "keys", len(keys), "err", err)
+	}
+}
+
+func (s *IdempotencyStore) finish(key, fingerprint string, rec *responseRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Server errors are not cached so the client can retry them.
+	if rec.status >= http.StatusInternalServerError {
+		delete(s.entries, key)
+		return
+	}
+	if rec.body.Len() > maxIdempotentBody {
+		slog.Warn("response too large to keep for its idempotency key", "bytes", rec.body.Len())
+		delete(s.entries, key)
+		return
+	}
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	entry.done = true
+	entry.status = rec.status
+	entry.header = rec.Header().Clone()
+	for k := range perRequestHeaders {
+		delete(entry.header, k)
+	}
+	entry.body = rec.body.Bytes()
+	entry.expires = time.Now().Add(s.ttl)
+
+	// A response that is not on disk is still answered from memory until
+	// the server stops.
+	err := s.repo.Put(models.IdempotentResponse{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      entry.status,
+		Header:      entry.header,
+		Body:        entry.body,
+		ExpiresAt:   entry.expires,
+	})
+	if err != nil {
+		slog.Warn("idempotent response not saved", "err", err)
+	}
+}
+
+func (s *IdempotencyStore) abandon(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.Path)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.RawQuery)
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingHandler answers every request with how many it has seen.
+type countingHandler struct {
+	calls int
+	body  string
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	h.calls++
+	fmt.Fprintf(w, "call %d%s", h.calls, h.body)
+}
+
+func newTestIdempotency(t *testing.T) (*services.LotteryService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	s := services.NewLotteryService(
+		storage.NewUserRepository(dir),
+		storage.NewDrawRepository(dir),
+		storage.NewTicketRepository(dir),
+		storage.NewPrizeRepository(dir),
+	)
+	s.SetAdminToken("admin-secret")
+	return s, dir
+}
+
+func post(h http.Handler, key, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/draws", strings.NewReader("{}"))
+	r.Header.Set(IdempotencyHeader, key)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotencyKeysAreScopedToTheCaller(t *testing.T) {
+	s, dir := newTestIdempotency(t)
+	alice, _ := s.RegisterUser("alice", "password123")
+	bob, _ := s.RegisterUser("bob", "password123")
+	next := &countingHandler{}
+	h := NewIdempotencyStore(s, storage.NewIdempotencyRepository(dir), time.Hour).Middleware(next)
+
+	aliceToken := s.IssueSession(alice.ID).Token
+	first := post(h, "k1", aliceToken)
+	retry := post(h, "k1", aliceToken)
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry = %q, want the replayed %q", retry.Body, first.Body)
+	}
+	other := post(h, "k1", s.IssueSession(bob.ID).Token)
+	if other.Header().Get("Idempotent-Replayed") != "" || next.calls != 2 {
+		t.Fatalf("another caller's request with the same key was answered from alice's (%q, %d calls)", other.Body, next.calls)
+	}
+}
+
+func TestIdempotencyKeysSurviveRestart(t *testing.T) {
+	s, dir := newTestIdempotency(t)
+	next := &countingHandler{}
+	first := post(NewIdempotencyStore(s, storage.NewIdempotencyRepository(dir), time.Hour).Middleware(next), "k1", "admin-secret")
+
+	restarted := NewIdempotencyStore(s, storage.NewIdempotencyRepository(dir), time.Hour).Middleware(next)
+	retry := post(restarted, "k1", "admin-secret")
+	if retry.Body.String() != first.Body.String() || next.calls != 1 {
+		t.Fatalf("retry after restart = %q after %d calls, want the replayed %q", retry.Body, next.calls, first.Body)
+	}
+}
+
+func TestIdempotencyStoreLimits(t *testing.T) {
+	s, dir := newTestIdempotency(t)
+	store := NewIdempotencyStore(s, storage.NewIdempotencyRepository(dir), time.Hour)
+	next := &countingHandler{}
+	h := store.Middleware(next)
+
+	post(h, "oldest", "admin-secret")
+	for i := len(store.entries); i < maxIdempotencyEntries; i++ {
+		store.entries[fmt.Sprint("filler", i)] = &idempotencyEntry{done: true, expires: time.Now().Add(2 * time.Hour)}
+	}
+	post(h, "new", "admin-secret")
+	if len(store.entries) != maxIdempotencyEntries {
+		t.Fatalf("%d entries, want at most %d", len(store.entries), maxIdempotencyEntries)
+	}
+	if retry := post(h, "oldest", "admin-secret"); retry.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("the oldest entry was kept over the cap")
+	}
+
+	next.body = strings.Repeat("x", maxIdempotentBody)
+	post(h, "large", "admin-secret")
+	if retry := post(h, "large", "admin-secret"); retry.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("a response over the size limit was kept")
+	}
+}
+
+func TestIdempotencyReplayKeepsItsOwnRequestID(t *testing.T) {
+	s, dir := newTestIdempotency(t)
+	h := RequestLogger(NewIdempotencyStore(s, storage.NewIdempotencyRepository(dir), time.Hour).Middleware(&countingHandler{}))
+
+	send := func(requestID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/draws", strings.NewReader("{}"))
+		r.Header.Set(IdempotencyHeader, "k1")
+		r.Header.Set(RequestIDHeader, requestID)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	send("first")
+	retry := send("retry")
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("retry was not replayed")
+	}
+	if got := retry.Header().Values(RequestIDHeader); len(got) != 1 || got[0] != "retry" {
+		t.Fatalf("replayed %s = %q, want the retry's own", RequestIDHeader, got)
+	}
+}
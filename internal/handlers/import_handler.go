@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"LotterySystem/internal/importer"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"net/http"
+)
+
+// maxImportSize bounds an uploaded import file.
+const maxImportSize = 32 << 20
+
+type ImportHandler struct {
+	service *services.LotteryService
+}
+
+func NewImportHandler(s *services.LotteryService) *ImportHandler {
+	return &ImportHandler{service: s}
+}
+
+func (h *ImportHandler) Register(mux *http.ServeMux) {
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *ImportHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: apiV1 + "/imports/{dataset}", Summary: "Validate or import users or tickets from a CSV body (admin token)", Tag: "imports",
+			Query: []string{"mode"}, Response: models.ImportReport{}, Handler: requireAdmin(h.service, h.importCSV)},
+	}
+}
+
+// importCSV runs a dry run unless mode=commit. Row problems are reported in
+// the body with a 200, since a dry run that finds them has succeeded; only a
+// file that cannot be read at all is a 422.
+func (h *ImportHandler) importCSV(w http.ResponseWriter, r *http.Request) {
+	dataset := r.PathValue("dataset")
+	if !importer.IsDataset(dataset) {
+		writeErrorResponse(w, http.StatusNotFound, errorResponse{Code: "not_found", Message: "unknown import " + dataset})
+		return
+	}
+
+	var commit bool
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", "dry-run":
+	case "commit":
+		commit = true
+	default:
+		writeError(w, services.NewValidationError("Invalid query parameters", map[string]string{"mode": "must be dry-run or commit"}))
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	report, err := importer.Run(h.service, actor(h.service, r), dataset, body, commit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"net/http"
+)
+
+// IntegrityHandler lets administrators check the stored records for
+// dangling references and inconsistencies, and repair the ones that can be
+// repaired safely.
+type IntegrityHandler struct {
+	service *services.LotteryService
+}
+
+func NewIntegrityHandler(s *services.LotteryService) *IntegrityHandler {
+	return &IntegrityHandler{service: s}
+}
+
+func (h *IntegrityHandler) Register(mux *http.ServeMux) {
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *IntegrityHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: apiV1 + "/integrity", Summary: "Check the stored records' integrity (admin token)", Tag: "admin",
+			Response: models.IntegrityReport{}, Handler: requireAdmin(h.service, h.check)},
+		{Method: http.MethodPost, Path: apiV1 + "/integrity/repairs", Summary: "Repair the integrity issues that have a safe fix (admin token)", Tag: "admin",
+			Query: []string{"mode"}, Response: models.IntegrityReport{}, Handler: requireAdmin(h.service, h.repair)},
+	}
+}
+
+func (h *IntegrityHandler) check(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.service.CheckIntegrity())
+}
+
+// repair only reports what it would fix unless mode=commit, like imports.
+func (h *IntegrityHandler) repair(w http.ResponseWriter, r *http.Request) {
+	var commit bool
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", "dry-run":
+	case "commit":
+		commit = true
+	default:
+		writeError(w, services.NewValidationError("Invalid query parameters", map[string]string{"mode": "must be dry-run or commit"}))
+		return
+	}
+
+	report, err := h.service.RepairIntegrity(actor(h.service, r), !commit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"LotterySystem/internal/metrics"
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec("lottery_http_requests_total",
+		"HTTP requests served, by method, route and status code.", "method", "route", "code")
+	httpDuration = metrics.NewHistogramVec("lottery_http_request_duration_seconds",
+		"Time taken to serve HTTP requests, by method and route.", nil, "method", "route")
+)
+
+// RegisterMetrics serves the metrics at GET /metrics.
+func RegisterMetrics(mux *http.ServeMux) {
+	mux.Handle("GET /metrics", metrics.Handler())
+}
+
+// MetricsMiddleware counts and times every request by the mux pattern it
+// matches, so paths with IDs in them fall under one route. Event streams
+// and WebSockets are counted when they end.
+func MetricsMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if _, pattern := mux.Handler(r); pattern != "" {
+			// Patterns are "METHOD /path"; the method is its own label.
+			_, path, found := strings.Cut(pattern, " ")
+			if !found {
+				path = pattern
+			}
+			route = path
+		}
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		method := metricMethod(r.Method)
+		httpDuration.With(method, route).Since(start)
+		httpRequests.With(method, route, strconv.Itoa(sw.status)).Inc()
+	})
+}
+
+// metricMethod is the method label for method. Clients can send any token
+// as a method, so everything but the standard methods counts as "other"
+// and the number of series stays bounded.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+// statusWriter remembers the status code. It passes Flush and Hijack
+// through so event streams and WebSocket upgrades keep working.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	w.wroteHeader = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = http.StatusSwitchingProtocols
+	}
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
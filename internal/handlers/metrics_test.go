@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"LotterySystem/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsMiddlewareBoundsMethods(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ping", func(w http.ResponseWriter, _ *http.Request) {})
+	h := MetricsMiddleware(mux, mux)
+	for _, method := range []string{"GET", "BREW", "PROPFIND-1"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/ping", nil))
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := w.Body.String()
+	for _, method := range []string{"BREW", "PROPFIND-1"} {
+		if strings.Contains(out, `method="`+method+`"`) {
+			t.Errorf("metrics have a series for method %s", method)
+		}
+	}
+	if !strings.Contains(out, `method="other"`) {
+		t.Error(`metrics have no series for method "other"`)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/websocket"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = 2 * wsPingInterval
+	wsWriteTimeout = 10 * time.Second
+	wsSendBuffer   = 32
+)
+
+type NotificationHandler struct {
+	service  *services.LotteryService
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func NewNotificationHandler(s *services.LotteryService) *NotificationHandler {
+	return &NotificationHandler{service: s, done: make(chan struct{})}
+}
+
+// Shutdown closes every open connection with "going away". The server does
+// not track hijacked connections, so nothing else would.
+func (h *NotificationHandler) Shutdown() {
+	h.doneOnce.Do(func() { close(h.done) })
+}
+
+func (h *NotificationHandler) Register(mux *http.ServeMux) {
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *NotificationHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: apiV1 + "/notifications", Summary: "Personal notifications over WebSocket", Tag: "users",
+			Query: []string{"token", "last_event_id"}, Status: http.StatusSwitchingProtocols, Handler: h.connect},
+	}
+}
+
+// connect authenticates the caller, upgrades to a WebSocket and streams the
+// user's events. The token may come from an Authorization bearer header or,
+// for browsers, the token query parameter. Clients resume after a reconnect
+// by passing the last event ID they received.
+func (h *NotificationHandler) connect(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if t := bearerToken(r); t != "" {
+		token = t
+	}
+
+	user, err := h.service.Authenticate(token)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var lastID uint64
+	if v := r.URL.Query().Get("last_event_id"); v != "" {
+		lastID, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, services.NewValidationError("Invalid last_event_id", map[string]string{
+				"last_event_id": "must be a non-negative integer",
+			}))
+			return
+		}
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	replay, events, cancel, truncated := h.service.Notifications().Subscribe(user.ID, lastID, wsSendBuffer)
+	defer cancel()
+
+	// The read loop only exists to answer pings and notice the peer leaving.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn.SetReadDeadline(time.Now().Add(wsPongWait))
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(v interface{}) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return true
+		}
+		return conn.WriteText(data, wsWriteTimeout) == nil
+	}
+
+	if truncated {
+		send(map[string]string{"type": "replay.truncated"})
+	}
+	for _, e := range replay {
+		if !send(e) {
+			conn.Close(websocket.CloseGoingAway, "")
+			return
+		}
+	}
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			conn.Close(websocket.CloseNormal, "")
+			return
+		case <-h.done:
+			conn.Close(websocket.CloseGoingAway, "server shutting down")
+			return
+		case <-ping.C:
+			if err := conn.Ping(wsWriteTimeout); err != nil {
+				conn.Close(websocket.CloseGoingAway, "")
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				// The notifier dropped us for falling behind.
+				conn.Close(websocket.CloseTryAgainLater, "slow consumer, reconnect with last_event_id")
+				return
+			}
+			if !send(e) {
+				conn.Close(websocket.CloseGoingAway, "")
+				return
+			}
+		}
+	}
+}
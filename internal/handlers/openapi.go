@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OpenAPIHandler struct {
+	spec map[string]any
+}
+
+// NewOpenAPIHandler builds the OpenAPI 3 document for the routes of the given
+// handlers once, at startup.
+func NewOpenAPIHandler(providers ...RouteProvider) *OpenAPIHandler {
+	var routes []Route
+	for _, p := range providers {
+		routes = append(routes, p.Routes()...)
+	}
+	return &OpenAPIHandler{spec: BuildOpenAPI(routes)}
+}
+
+func (h *OpenAPIHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiV1+"/openapi.json", h.getSpec)
+}
+
+func (h *OpenAPIHandler) getSpec(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.spec)
+}
+
+// BuildOpenAPI derives an OpenAPI 3.0 document from a route table. Body
+// schemas are generated from the Go types via their json tags.
+func BuildOpenAPI(routes []Route) map[string]any {
+	sb := &schemaBuilder{components: map[string]any{}}
+	errorRef := sb.ref(reflect.TypeOf(errorResponse{}))
+
+	paths := map[string]map[string]any{}
+	for _, rt := range routes {
+		op := map[string]any{
+			"summary":     rt.Summary,
+			"operationId": operationID(rt),
+		}
+		if rt.Tag != "" {
+			op["tags"] = []string{rt.Tag}
+		}
+
+		var params []any
+		for _, name := range pathParams(rt.Path) {
+			params = append(params, map[string]any{
+				"name": name, "in": "path", "required": true,
+				"schema": map[string]any{"type": "string"},
+			})
+		}
+		for _, name := range rt.Query {
+			params = append(params, map[string]any{
+				"name": name, "in": "query",
+				"schema": map[string]any{"type": "string"},
+			})
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if rt.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": sb.schema(reflect.TypeOf(rt.Request))},
+				},
+			}
+		}
+
+		success := map[string]any{"description": http.StatusText(rt.successStatus())}
+		if rt.Response != nil {
+			success["content"] = map[string]any{
+				"application/json": map[string]any{"schema": sb.schema(reflect.TypeOf(rt.Response))},
+			}
+		} else if rt.ContentType != "" {
+			success["content"] = map[string]any{
+				rt.ContentType: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+			}
+		}
+		op["responses"] = map[string]any{
+			strconv.Itoa(rt.successStatus()): success,
+			"default": map[string]any{
+				"description": "Error",
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": errorRef},
+				},
+			},
+		}
+
+		if paths[rt.Path] == nil {
+			paths[rt.Path] = map[string]any{}
+		}
+		paths[rt.Path][strings.ToLower(rt.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Lottery Imitation System API",
+			"version": "1.0.0",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": sb.components},
+	}
+}
+
+func operationID(rt Route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rt.Method))
+	for _, seg := range strings.Split(strings.TrimPrefix(rt.Path, apiV1), "/") {
+		seg = strings.Trim(seg, "{}")
+		if seg == "" {
+			continue
+		}
+		for _, part := range strings.FieldsFunc(seg, func(r rune) bool { return r == '_' || r == '.' || r == '-' }) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+type schemaBuilder struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (sb *schemaBuilder) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		return sb.ref(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": sb.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": sb.schema(t.Elem())}
+	case reflect.Struct:
+		return sb.object(t)
+	}
+	return map[string]any{}
+}
+
+func (sb *schemaBuilder) ref(t reflect.Type) map[string]any {
+	name := t.Name()
+	if _, ok := sb.components[name]; !ok {
+		// Reserve the name first so recursive types terminate.
+		sb.components[name] = map[string]any{}
+		sb.components[name] = sb.object(t)
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func (sb *schemaBuilder) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = sb.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	obj := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		obj["required"] = required
+	}
+	return obj
+}
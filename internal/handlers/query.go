@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"LotterySystem/internal/export"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var listQuery = []string{"sort", "order", "limit", "cursor"}
+
+// queryParser collects every bad parameter so a single 422 can report them
+// all at once.
+type queryParser struct {
+	values  url.Values
+	fields  map[string]string
+	unpaged bool
+}
+
+func newQueryParser(r *http.Request) *queryParser {
+	unpaged, _ := r.Context().Value(unpagedKey{}).(bool)
+	return &queryParser{values: r.URL.Query(), fields: map[string]string{}, unpaged: unpaged}
+}
+
+type unpagedKey struct{}
+
+// unpaged lists every record unless the request sets a limit, as the
+// /api/admin list routes did before lists were paged; admin.html reads them
+// whole.
+func unpaged(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h(w, r.WithContext(context.WithValue(r.Context(), unpagedKey{}, true)))
+	}
+}
+
+func (p *queryParser) err() error {
+	if len(p.fields) == 0 {
+		return nil
+	}
+	return services.NewValidationError("Invalid query parameters", p.fields)
+}
+
+func (p *queryParser) string(name string) string {
+	return p.values.Get(name)
+}
+
+func (p *queryParser) int(name string) int {
+	v := p.values.Get(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		p.fields[name] = "must be a non-negative integer"
+		return 0
+	}
+	return n
+}
+
+// time accepts RFC 3339 timestamps or plain dates, as export.ParseTime
+// does. A plain date used as an upper bound covers the whole day.
+func (p *queryParser) time(name string, endOfDay bool) time.Time {
+	t, err := export.ParseTime(p.values.Get(name), endOfDay)
+	if err != nil {
+		p.fields[name] = "must be a date (2006-01-02) or RFC 3339 timestamp"
+		return time.Time{}
+	}
+	return t
+}
+
+func (p *queryParser) listOptions() models.ListOptions {
+	asc := false
+	switch strings.ToLower(p.values.Get("order")) {
+	case "", "desc":
+	case "asc":
+		asc = true
+	default:
+		p.fields["order"] = "must be asc or desc"
+	}
+	opts := models.NewListOptions(p.values.Get("sort"), asc, p.int("limit"), p.values.Get("cursor"))
+	if p.unpaged && p.values.Get("limit") == "" {
+		opts.Limit = 0
+	}
+	return opts
+}
+
+// writePage replies with the page items as a plain JSON array and advertises
+// the next page in the X-Next-Cursor and Link headers.
+func writePage[T any](w http.ResponseWriter, r *http.Request, page models.Page[T]) {
+	if page.NextCursor != "" {
+		next := *r.URL
+		q := next.Query()
+		q.Set("cursor", page.NextCursor)
+		next.RawQuery = q.Encode()
+
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", "<"+next.RequestURI()+">; rel=\"next\"")
+	}
+	writeJSON(w, http.StatusOK, page.Items)
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"LotterySystem/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnpagedListOptions(t *testing.T) {
+	for _, tt := range []struct {
+		url     string
+		unpaged bool
+		want    int
+	}{
+		{"/api/v1/draws", false, models.DefaultPageSize},
+		{"/api/v1/draws?limit=5", false, 5},
+		{"/api/admin/draws", true, 0},
+		{"/api/admin/draws?limit=5", true, 5},
+	} {
+		var got models.ListOptions
+		h := func(w http.ResponseWriter, r *http.Request) { got = newQueryParser(r).listOptions() }
+		if tt.unpaged {
+			h = unpaged(h)
+		}
+		h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if got.Limit != tt.want {
+			t.Errorf("%s: limit = %d, want %d", tt.url, got.Limit, tt.want)
+		}
+	}
+}
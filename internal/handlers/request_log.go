@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"LotterySystem/internal/utils"
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	maxRequestID    = 128
+	requestIDPrefix = "req"
+)
+
+type requestIDKey struct{}
+
+var requestIDs = utils.NewULIDGenerator()
+
+// RequestID returns the ID RequestLogger assigned to the request, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestLogger gives every request an ID and writes one access log line
+// when it finishes. The ID comes from the X-Request-ID header when the
+// client or a proxy sent a usable one, so a request can be followed across
+// services; otherwise a new one is made. Either way it is echoed in the
+// response.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = requestIDs.NewID(requestIDPrefix)
+		}
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+
+		start := time.Now()
+		lw := &logWriter{statusWriter: statusWriter{ResponseWriter: w, status: http.StatusOK}}
+		next.ServeHTTP(lw, r)
+
+		level := slog.LevelInfo
+		switch {
+		case lw.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case isProbe(r) && lw.status == http.StatusOK:
+			// Probes arrive every few seconds; only failures are news.
+			level = slog.LevelDebug
+		}
+		attrs := []slog.Attr{
+			slog.String("request_id", id),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", lw.status),
+			slog.Int64("bytes", lw.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote", r.RemoteAddr),
+		}
+		if lw.err != nil {
+			attrs = append(attrs, slog.String("err", lw.err.Error()))
+		}
+		slog.LogAttrs(r.Context(), level, "http request", attrs...)
+	})
+}
+
+func isProbe(r *http.Request) bool {
+	return r.URL.Path == "/healthz" || r.URL.Path == "/readyz"
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces, so a client
+// cannot break up log lines with what it sends.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// logWriter also counts the body bytes and holds the error noteError
+// recorded, for the access log.
+type logWriter struct {
+	statusWriter
+	bytes int64
+	err   error
+}
+
+func (w *logWriter) Write(b []byte) (int, error) {
+	n, err := w.statusWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// noteError attaches err to the request's access log line. Internal errors
+// are hidden from the client, so this is where their text ends up.
+func noteError(w http.ResponseWriter, err error) {
+	for {
+		switch v := w.(type) {
+		case *logWriter:
+			v.err = err
+			return
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			slog.Error("request failed", "err", err)
+			return
+		}
+	}
+}
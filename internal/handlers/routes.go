@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const apiV1 = "/api/v1"
+
+// Route describes one /api/v1 endpoint. The same table registers the handler
+// on the mux and produces the OpenAPI document, so the two cannot drift.
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+	Query   []string
+
+	// Request and Response are zero values of the body types; nil means no
+	// body. Status is the success status code, 200 when zero.
+	Request     any
+	Response    any
+	ContentType string
+	Status      int
+
+	Handler http.HandlerFunc
+}
+
+// RouteProvider is implemented by every handler that serves /api/v1 routes.
+type RouteProvider interface {
+	Routes() []Route
+}
+
+func (rt Route) pattern() string {
+	return rt.Method + " " + rt.Path
+}
+
+func (rt Route) successStatus() int {
+	if rt.Status == 0 {
+		return http.StatusOK
+	}
+	return rt.Status
+}
+
+func registerRoutes(mux *http.ServeMux, routes []Route) {
+	for _, rt := range routes {
+		mux.HandleFunc(rt.pattern(), rt.Handler)
+	}
+}
+
+// deprecated marks a pre-v1 route. It keeps working but tells clients where
+// the replacement lives.
+func deprecated(successor string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+">; rel=\"successor-version\"")
+		h(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeBadRequest(w, "Invalid request body")
+		return false
+	}
+	return true
+}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			params = append(params, strings.TrimSuffix(strings.TrimPrefix(seg, "{"), "}"))
+		}
+	}
+	return params
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"LotterySystem/internal/models"
 	"LotterySystem/internal/services"
 	"encoding/json"
 	"net/http"
+	"strconv"
 )
 
 type TicketHandler struct {
@@ -14,10 +16,72 @@ func NewTicketHandler(s *services.LotteryService) *TicketHandler {
 	return &TicketHandler{service: s}
 }
 
+type createTicketRequest struct {
+	UserID  string `json:"user_id"`
+	DrawID  string `json:"draw_id"`
+	Numbers []int  `json:"numbers"`
+}
+
+type cancelTicketRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type verifyReceiptRequest struct {
+	Payload string `json:"payload"`
+}
+
+type ticketView struct {
+	Ticket     models.Ticket `json:"ticket"`
+	Prize      *models.Prize `json:"prize"`
+	DrawStatus string        `json:"draw_status"`
+}
+
+// serialView is what a serial lookup shows whoever holds the serial. The
+// ticket itself, with its owner, numbers and prize, is only included for
+// the owner's session or an admin.
+type serialView struct {
+	Valid      bool        `json:"valid"`
+	Serial     string      `json:"serial"`
+	DrawID     string      `json:"draw_id"`
+	DrawStatus string      `json:"draw_status"`
+	Status     string      `json:"status"`
+	Ticket     *ticketView `json:"ticket,omitempty"`
+}
+
 func (h *TicketHandler) Register(mux *http.ServeMux) {
-	mux.HandleFunc("/api/tickets", h.handleTickets)
-	mux.HandleFunc("/api/tickets/user", h.getUserTickets)
-	mux.HandleFunc("/api/tickets/detail", h.getTicketDetail)
+	mux.HandleFunc("/api/tickets", deprecated(apiV1+"/tickets", h.handleTickets))
+	mux.HandleFunc("/api/tickets/user", deprecated(apiV1+"/users/{id}/tickets", h.getUserTickets))
+	mux.HandleFunc("/api/tickets/detail", deprecated(apiV1+"/tickets/{id}", h.getTicketDetail))
+	mux.HandleFunc("/api/tickets/cancel", deprecated(apiV1+"/tickets/{id}/cancel", h.cancelTicket))
+	mux.HandleFunc("/api/tickets/receipt", deprecated(apiV1+"/tickets/{id}/receipt", h.getReceipt))
+	mux.HandleFunc("/api/tickets/receipt/qr", deprecated(apiV1+"/tickets/{id}/receipt/qr", h.getReceiptQR))
+	mux.HandleFunc("/api/tickets/verify", deprecated(apiV1+"/receipts/verify", h.verifyReceipt))
+
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *TicketHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: apiV1 + "/tickets", Summary: "Buy a ticket", Tag: "tickets",
+			Request: createTicketRequest{}, Response: models.Ticket{}, Status: http.StatusCreated, Handler: h.createTicketV1},
+		{Method: http.MethodGet, Path: apiV1 + "/tickets", Summary: "Search all tickets (admin token)", Tag: "tickets",
+			Query: append([]string{"user_id", "draw_id", "status", "min_matches", "from", "to"}, listQuery...), Response: []ticketView{},
+			Handler: requireAdmin(h.service, h.listTicketsV1)},
+		{Method: http.MethodGet, Path: apiV1 + "/tickets/{id}", Summary: "Get a ticket with its result", Tag: "tickets",
+			Response: ticketView{}, Handler: h.getTicketV1},
+		{Method: http.MethodPost, Path: apiV1 + "/tickets/{id}/cancel", Summary: "Cancel a ticket and refund it", Tag: "tickets",
+			Request: cancelTicketRequest{}, Response: models.Ticket{}, Handler: h.cancelTicketV1},
+		{Method: http.MethodGet, Path: apiV1 + "/tickets/{id}/receipt", Summary: "Get a signed ticket receipt", Tag: "tickets",
+			Response: models.Receipt{}, Handler: h.getReceiptV1},
+		{Method: http.MethodGet, Path: apiV1 + "/tickets/{id}/receipt/qr", Summary: "Get a ticket receipt as a QR code", Tag: "tickets",
+			Query: []string{"scale"}, ContentType: "image/png", Handler: h.getReceiptQRV1},
+		{Method: http.MethodGet, Path: apiV1 + "/serials/{serial}", Summary: "Check a ticket serial; the owner or an admin also gets the ticket", Tag: "tickets",
+			Response: serialView{}, Handler: h.getTicketBySerialV1},
+		{Method: http.MethodPost, Path: apiV1 + "/receipts/verify", Summary: "Verify a ticket receipt", Tag: "tickets",
+			Request: verifyReceiptRequest{}, Response: models.ReceiptVerification{}, Handler: h.verifyReceiptV1},
+		{Method: http.MethodGet, Path: apiV1 + "/users/{id}/tickets", Summary: "List a user's tickets", Tag: "tickets",
+			Query: append([]string{"draw_id", "status", "min_matches", "from", "to"}, listQuery...), Response: []ticketView{}, Handler: h.getUserTicketsV1},
+	}
 }
 
 func (h *TicketHandler) handleTickets(w http.ResponseWriter, r *http.Request) {
@@ -27,35 +91,52 @@ func (h *TicketHandler) handleTickets(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusMethodNotAllowed)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Method not allowed",
-	})
+	writeMethodNotAllowed(w)
 }
 
 func (h *TicketHandler) createTicket(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	var req createTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "Invalid request body")
+		return
+	}
+
+	ticket, err := h.service.CreateTicket(req.UserID, req.DrawID, req.Numbers)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"ticket":  ticket,
+	})
+}
+
+func (h *TicketHandler) cancelTicket(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
 	var req struct {
-		UserID  string `json:"user_id"`
-		DrawID  string `json:"draw_id"`
-		Numbers []int  `json:"numbers"`
+		UserID   string `json:"user_id"`
+		TicketID string `json:"ticket_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "Invalid request body",
-		})
+		writeBadRequest(w, "Invalid request body")
 		return
 	}
 
-	ticket, err := h.service.CreateTicket(req.UserID, req.DrawID, req.Numbers)
+	ticket, err := h.service.CancelTicket(req.UserID, req.TicketID)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": err.Error(),
-		})
+		writeError(w, err)
 		return
 	}
 
@@ -70,94 +151,310 @@ func (h *TicketHandler) getUserTickets(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "Method not allowed",
-		})
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID := r.URL.Query().Get("user_id")
 	if userID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "User ID is required",
-		})
+		writeRequired(w, "user_id", "User ID is required")
 		return
 	}
 
-	tickets := h.service.GetUserTickets(userID)
+	h.writeUserTickets(w, r, userID)
+}
 
-	result := make([]map[string]interface{}, 0, len(tickets))
-	for _, ticket := range tickets {
+func (h *TicketHandler) getTicketDetail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 
-		draw, err := h.service.GetDraw(ticket.DrawID)
-		drawStatus := "unknown"
-		if err == nil {
-			drawStatus = draw.Status
-		}
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
 
-		ticketData := map[string]interface{}{
-			"ticket":      ticket,
-			"prize":       nil,
-			"draw_status": drawStatus, // 🔥 ВАЖНО
-		}
+	ticketID := r.URL.Query().Get("id")
+	serial := r.URL.Query().Get("serial")
+	if ticketID == "" && serial == "" {
+		writeRequired(w, "id", "Ticket ID or serial is required")
+		return
+	}
 
-		if ticket.PrizeID != "" {
-			prize, err := h.service.GetPrizeByTicket(ticket.ID)
-			if err == nil {
-				ticketData["prize"] = prize
-			}
+	if serial != "" {
+		ticket, err := h.service.GetTicketBySerial(serial)
+		if err != nil {
+			writeError(w, err)
+			return
 		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(h.serialView(r, ticket))
+		return
+	}
 
-		result = append(result, ticketData)
+	ticket, err := h.service.GetTicket(ticketID)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(h.ticketView(ticket))
 }
 
-func (h *TicketHandler) getTicketDetail(w http.ResponseWriter, r *http.Request) {
+func (h *TicketHandler) getReceipt(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "Method not allowed",
-		})
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	ticketID := r.URL.Query().Get("id")
 	if ticketID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "Ticket ID is required",
-		})
+		writeRequired(w, "id", "Ticket ID is required")
 		return
 	}
 
-	ticket, err := h.service.GetTicket(ticketID)
+	receipt, err := h.service.GetTicketReceipt(ticketID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(receipt)
+}
+
+func (h *TicketHandler) getReceiptQR(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	ticketID := r.URL.Query().Get("id")
+	if ticketID == "" {
+		writeRequired(w, "id", "Ticket ID is required")
+		return
+	}
+
+	h.writeReceiptQR(w, r, ticketID)
+}
+
+func (h *TicketHandler) verifyReceipt(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var payload string
+	switch r.Method {
+	case http.MethodGet:
+		payload = r.URL.Query().Get("payload")
+	case http.MethodPost:
+		var req verifyReceiptRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "Invalid request body")
+			return
+		}
+		payload = req.Payload
+	default:
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	h.writeVerification(w, payload)
+}
+
+func (h *TicketHandler) createTicketV1(w http.ResponseWriter, r *http.Request) {
+	var req createTicketRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	ticket, err := h.service.CreateTicket(req.UserID, req.DrawID, req.Numbers)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ticket)
+}
+
+func (h *TicketHandler) getTicketV1(w http.ResponseWriter, r *http.Request) {
+	ticket, err := h.service.GetTicket(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.ticketView(ticket))
+}
+
+func (h *TicketHandler) getTicketBySerialV1(w http.ResponseWriter, r *http.Request) {
+	ticket, err := h.service.GetTicketBySerial(r.PathValue("serial"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.serialView(r, ticket))
+}
+
+func (h *TicketHandler) cancelTicketV1(w http.ResponseWriter, r *http.Request) {
+	var req cancelTicketRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	ticket, err := h.service.CancelTicket(req.UserID, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ticket)
+}
+
+func (h *TicketHandler) getReceiptV1(w http.ResponseWriter, r *http.Request) {
+	receipt, err := h.service.GetTicketReceipt(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, receipt)
+}
+
+func (h *TicketHandler) getReceiptQRV1(w http.ResponseWriter, r *http.Request) {
+	h.writeReceiptQR(w, r, r.PathValue("id"))
+}
+
+func (h *TicketHandler) verifyReceiptV1(w http.ResponseWriter, r *http.Request) {
+	var req verifyReceiptRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	h.writeVerification(w, req.Payload)
+}
+
+func (h *TicketHandler) getUserTicketsV1(w http.ResponseWriter, r *http.Request) {
+	h.writeUserTickets(w, r, r.PathValue("id"))
+}
+
+func (h *TicketHandler) listTicketsV1(w http.ResponseWriter, r *http.Request) {
+	h.writeUserTickets(w, r, r.URL.Query().Get("user_id"))
+}
+
+func (h *TicketHandler) writeReceiptQR(w http.ResponseWriter, r *http.Request, ticketID string) {
+	scale := 6
+	if v := r.URL.Query().Get("scale"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 20 {
+			writeError(w, services.NewValidationError("Scale must be between 1 and 20", map[string]string{
+				"scale": "must be between 1 and 20",
+			}))
+			return
+		}
+		scale = n
+	}
+
+	img, err := h.service.GetTicketReceiptQR(ticketID, scale)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(img)
+}
+
+func (h *TicketHandler) writeVerification(w http.ResponseWriter, payload string) {
+	if payload == "" {
+		writeRequired(w, "payload", "Receipt payload is required")
+		return
+	}
+
+	// An invalid receipt is a valid answer, not a failed request.
+	result, err := h.service.VerifyReceipt(payload)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"valid":   false,
 			"message": err.Error(),
 		})
 		return
 	}
 
-	result := map[string]interface{}{
-		"ticket": ticket,
-		"prize":  nil,
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *TicketHandler) writeUserTickets(w http.ResponseWriter, r *http.Request, userID string) {
+	q := newQueryParser(r)
+	filter := models.TicketFilter{
+		ListOptions: q.listOptions(),
+		UserID:      userID,
+		DrawID:      q.string("draw_id"),
+		Status:      q.string("status"),
+		MinMatches:  q.int("min_matches"),
+		From:        q.time("from", false),
+		To:          q.time("to", true),
+	}
+	if err := q.err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := h.service.QueryTickets(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	views := models.Page[ticketView]{
+		Items:      make([]ticketView, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+	}
+	for _, ticket := range page.Items {
+		views.Items = append(views.Items, h.ticketView(ticket))
+	}
+
+	writePage(w, r, views)
+}
+
+func (h *TicketHandler) ticketView(ticket models.Ticket) ticketView {
+	view := ticketView{Ticket: ticket, DrawStatus: "unknown"}
+
+	if draw, err := h.service.GetDraw(ticket.DrawID); err == nil {
+		view.DrawStatus = draw.Status
 	}
 
 	if ticket.PrizeID != "" {
-		prize, err := h.service.GetPrizeByTicket(ticket.ID)
-		if err == nil {
-			result["prize"] = prize
+		if prize, err := h.service.GetPrizeByTicket(ticket.ID); err == nil {
+			view.Prize = &prize
 		}
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(result)
+	return view
+}
+
+func (h *TicketHandler) serialView(r *http.Request, ticket models.Ticket) serialView {
+	full := h.ticketView(ticket)
+	view := serialView{
+		Valid:      true,
+		Serial:     ticket.Serial,
+		DrawID:     ticket.DrawID,
+		DrawStatus: full.DrawStatus,
+		Status:     ticket.Status,
+	}
+	if h.ownsTicket(r, ticket) {
+		view.Ticket = &full
+	}
+	return view
+}
+
+// ownsTicket reports whether the request's bearer token is the ticket
+// owner's session or an admin's.
+func (h *TicketHandler) ownsTicket(r *http.Request, ticket models.Ticket) bool {
+	token := bearerToken(r)
+	if h.service.IsAdmin(token) {
+		return true
+	}
+	user, err := h.service.Authenticate(token)
+	return err == nil && user.ID == ticket.UserID
 }
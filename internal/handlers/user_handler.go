@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"LotterySystem/internal/models"
 	"LotterySystem/internal/services"
 	"encoding/json"
 	"net/http"
@@ -14,36 +15,66 @@ func NewUserHandler(s *services.LotteryService) *UserHandler {
 	return &UserHandler{service: s}
 }
 
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type balanceAdjustmentRequest struct {
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+}
+
+type roleRequest struct {
+	Role string `json:"role"`
+}
+
+type sessionResponse struct {
+	User    models.User    `json:"user"`
+	Session models.Session `json:"session"`
+}
+
 func (h *UserHandler) Register(mux *http.ServeMux) {
-	mux.HandleFunc("/api/register", h.register)
-	mux.HandleFunc("/api/login", h.login)
-	mux.HandleFunc("/api/user", h.getUser)
+	mux.HandleFunc("/api/register", deprecated(apiV1+"/users", h.register))
+	mux.HandleFunc("/api/login", deprecated(apiV1+"/sessions", h.login))
+	mux.HandleFunc("/api/user", deprecated(apiV1+"/users/{id}", h.getUser))
+
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *UserHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: apiV1 + "/users", Summary: "Register a user", Tag: "users",
+			Request: credentialsRequest{}, Response: models.User{}, Status: http.StatusCreated, Handler: h.registerV1},
+		{Method: http.MethodPost, Path: apiV1 + "/sessions", Summary: "Log in", Tag: "users",
+			Request: credentialsRequest{}, Response: sessionResponse{}, Handler: h.loginV1},
+		{Method: http.MethodGet, Path: apiV1 + "/users/{id}", Summary: "Get a user", Tag: "users",
+			Response: models.User{}, Handler: h.getUserV1},
+		{Method: http.MethodGet, Path: apiV1 + "/users", Summary: "Search users (admin token)", Tag: "users",
+			Query: append([]string{"username", "role"}, listQuery...), Response: []models.User{}, Handler: requireAdmin(h.service, h.listUsersV1)},
+		{Method: http.MethodPost, Path: apiV1 + "/users/{id}/balance-adjustments", Summary: "Credit or debit a balance (admin token)", Tag: "users",
+			Request: balanceAdjustmentRequest{}, Response: models.User{}, Handler: requireAdmin(h.service, h.adjustBalanceV1)},
+		{Method: http.MethodPut, Path: apiV1 + "/users/{id}/role", Summary: "Set a user's role (admin token)", Tag: "users",
+			Request: roleRequest{}, Response: models.User{}, Handler: requireAdmin(h.service, h.setRoleV1)},
+	}
 }
 
 func (h *UserHandler) register(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
-	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-	}
+	var req credentialsRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	if req.Username == "" || req.Password == "" {
-		http.Error(w, "Username and password are required", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid request body")
 		return
 	}
 
 	user, err := h.service.RegisterUser(req.Username, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
@@ -56,51 +87,146 @@ func (h *UserHandler) register(w http.ResponseWriter, r *http.Request) {
 
 func (h *UserHandler) login(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
-	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-	}
+	var req credentialsRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, "Invalid request body")
 		return
 	}
 
 	user, err := h.service.LoginUser(req.Username, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, err)
 		return
 	}
 
+	session := h.service.IssueSession(user.ID)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"user":    user,
+		"success":    true,
+		"user":       user,
+		"token":      session.Token,
+		"expires_at": session.ExpiresAt,
 	})
 }
 
 func (h *UserHandler) getUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	userID := r.URL.Query().Get("id")
 	if userID == "" {
-		http.Error(w, "User ID is required", http.StatusBadRequest)
+		writeRequired(w, "id", "User ID is required")
 		return
 	}
 
 	user, err := h.service.GetUser(userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
+
+func (h *UserHandler) registerV1(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.service.RegisterUser(req.Username, req.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+func (h *UserHandler) loginV1(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.service.LoginUser(req.Username, req.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sessionResponse{
+		User:    user,
+		Session: h.service.IssueSession(user.ID),
+	})
+}
+
+func (h *UserHandler) getUserV1(w http.ResponseWriter, r *http.Request) {
+	user, err := h.service.GetUser(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) listUsersV1(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParser(r)
+	filter := models.UserFilter{
+		ListOptions: q.listOptions(),
+		Username:    q.string("username"),
+		Role:        q.string("role"),
+	}
+	if err := q.err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := h.service.QueryUsers(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writePage(w, r, page)
+}
+
+func (h *UserHandler) adjustBalanceV1(w http.ResponseWriter, r *http.Request) {
+	var req balanceAdjustmentRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.service.AdjustBalance(actor(h.service, r), r.PathValue("id"), req.Delta, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) setRoleV1(w http.ResponseWriter, r *http.Request) {
+	var req roleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.service.SetUserRole(actor(h.service, r), r.PathValue("id"), req.Role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
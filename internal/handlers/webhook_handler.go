@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"net/http"
+)
+
+type WebhookHandler struct {
+	service    *services.LotteryService
+	dispatcher *services.WebhookDispatcher
+}
+
+func NewWebhookHandler(s *services.LotteryService, d *services.WebhookDispatcher) *WebhookHandler {
+	return &WebhookHandler{service: s, dispatcher: d}
+}
+
+func (h *WebhookHandler) Register(mux *http.ServeMux) {
+	registerRoutes(mux, h.Routes())
+}
+
+func (h *WebhookHandler) Routes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: apiV1 + "/webhooks", Summary: "List webhook subscriptions (admin token)", Tag: "webhooks",
+			Response: []models.Webhook{}, Handler: requireAdmin(h.service, h.listWebhooks)},
+		{Method: http.MethodPost, Path: apiV1 + "/webhooks", Summary: "Register a webhook; the secret is only returned here (admin token)", Tag: "webhooks",
+			Request: createWebhookRequest{}, Response: models.Webhook{}, Status: http.StatusCreated, Handler: requireAdmin(h.service, h.createWebhook)},
+		{Method: http.MethodGet, Path: apiV1 + "/webhooks/{id}", Summary: "Get a webhook subscription (admin token)", Tag: "webhooks",
+			Response: models.Webhook{}, Handler: requireAdmin(h.service, h.getWebhook)},
+		{Method: http.MethodDelete, Path: apiV1 + "/webhooks/{id}", Summary: "Delete a webhook subscription (admin token)", Tag: "webhooks",
+			Status: http.StatusNoContent, Handler: requireAdmin(h.service, h.deleteWebhook)},
+		{Method: http.MethodGet, Path: apiV1 + "/webhooks/{id}/deliveries", Summary: "List a webhook's delivery log (admin token)", Tag: "webhooks",
+			Query: append([]string{"status"}, listQuery...), Response: []models.WebhookDelivery{}, Handler: requireAdmin(h.service, h.listDeliveries)},
+		{Method: http.MethodPost, Path: apiV1 + "/webhooks/deliveries/{id}/redeliver", Summary: "Queue a delivery to be sent again (admin token)", Tag: "webhooks",
+			Response: models.WebhookDelivery{}, Handler: requireAdmin(h.service, h.redeliver)},
+	}
+}
+
+type createWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+func (h *WebhookHandler) listWebhooks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.dispatcher.ListWebhooks())
+}
+
+func (h *WebhookHandler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var req createWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	webhook, err := h.dispatcher.CreateWebhook(req.URL, req.Events, req.Secret)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) getWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.dispatcher.GetWebhook(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.dispatcher.DeleteWebhook(r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParser(r)
+	opts := q.listOptions()
+	status := q.string("status")
+	if err := q.err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := h.dispatcher.QueryDeliveries(r.PathValue("id"), status, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writePage(w, r, page)
+}
+
+func (h *WebhookHandler) redeliver(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.dispatcher.Redeliver(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, delivery)
+}
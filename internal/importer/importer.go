@@ -0,0 +1,214 @@
+// Package importer reads users and tickets from CSV for bulk creation. It
+// only parses; validation and the all-or-nothing commit are done by the
+// service, so the import endpoint and the import command behave the same.
+//
+// The first line is a header naming the columns, in any order:
+//
+//	users:   username, password, balance (optional)
+//	tickets: user_id or username, draw_id (optional), numbers
+//
+// An imported balance may be lower than the starting balance, not higher.
+//
+// numbers holds six numbers separated by spaces, commas or semicolons, e.g.
+// "3 11 19 27 35 42". Line numbers in reports count the header as line 1.
+package importer
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Datasets lists what can be imported.
+var Datasets = []string{"users", "tickets"}
+
+var columns = map[string]map[string]bool{
+	"users":   {"username": true, "password": true, "balance": true},
+	"tickets": {"user_id": true, "username": true, "draw_id": true, "numbers": true},
+}
+
+func IsDataset(name string) bool {
+	_, ok := columns[name]
+	return ok
+}
+
+// Run parses r and hands the rows to the service, which validates them and
+// commits when commit is set and nothing is wrong. actor is recorded in the
+// audit log as who ran the import.
+func Run(s *services.LotteryService, actor, dataset string, r io.Reader, commit bool) (models.ImportReport, error) {
+	switch dataset {
+	case "users":
+		rows, errs, err := parseUsers(r)
+		if err != nil {
+			return models.ImportReport{}, err
+		}
+		return s.ImportUsers(actor, rows, errs, commit)
+	case "tickets":
+		rows, errs, err := parseTickets(r)
+		if err != nil {
+			return models.ImportReport{}, err
+		}
+		return s.ImportTickets(actor, rows, errs, commit)
+	}
+	return models.ImportReport{}, fmt.Errorf("unknown dataset %q", dataset)
+}
+
+// csvFile yields records along with the column index of each header name.
+type csvFile struct {
+	r      *csv.Reader
+	header map[string]int
+}
+
+func openCSV(r io.Reader, dataset string, required ...string) (*csvFile, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	names, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, services.NewValidationError("the file is empty", map[string]string{"file": "must start with a header line"})
+	} else if err != nil {
+		return nil, services.NewValidationError("not a valid CSV file: "+err.Error(), nil)
+	}
+
+	header := map[string]int{}
+	fields := map[string]string{}
+	for i, name := range names {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if !columns[dataset][name] {
+			fields[name] = "is not a known column"
+			continue
+		}
+		header[name] = i
+	}
+	for _, name := range required {
+		alternatives := strings.Split(name, "|")
+		found := false
+		for _, alt := range alternatives {
+			if _, ok := header[alt]; ok {
+				found = true
+			}
+		}
+		if !found {
+			msg := "column is required"
+			if len(alternatives) > 1 {
+				msg = "column (or " + strings.Join(alternatives[1:], ", ") + ") is required"
+			}
+			fields[alternatives[0]] = msg
+		}
+	}
+	if len(fields) > 0 {
+		return nil, services.NewValidationError("invalid header line", fields)
+	}
+	return &csvFile{r: cr, header: header}, nil
+}
+
+// next returns the next record and its line number, or io.EOF.
+func (f *csvFile) next() ([]string, int, error) {
+	for {
+		record, err := f.r.Read()
+		if err != nil {
+			return nil, 0, err
+		}
+		line, _ := f.r.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue // blank line
+		}
+		return record, line, nil
+	}
+}
+
+func (f *csvFile) get(record []string, column string) string {
+	i, ok := f.header[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func parseUsers(r io.Reader) ([]models.UserImportRow, []models.ImportRowError, error) {
+	f, err := openCSV(r, "users", "username", "password")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []models.UserImportRow
+	var errs []models.ImportRowError
+	for {
+		record, line, err := f.next()
+		if errors.Is(err, io.EOF) {
+			return rows, errs, nil
+		} else if err != nil {
+			return nil, nil, services.NewValidationError("not a valid CSV file: "+err.Error(), nil)
+		}
+
+		row := models.UserImportRow{
+			Line:     line,
+			Username: f.get(record, "username"),
+			Password: f.get(record, "password"),
+		}
+		if v := f.get(record, "balance"); v != "" {
+			balance, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, models.ImportRowError{Line: line, Field: "balance", Message: "must be a whole number"})
+				continue
+			}
+			row.Balance = &balance
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseTickets(r io.Reader) ([]models.TicketImportRow, []models.ImportRowError, error) {
+	f, err := openCSV(r, "tickets", "user_id|username", "numbers")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []models.TicketImportRow
+	var errs []models.ImportRowError
+	for {
+		record, line, err := f.next()
+		if errors.Is(err, io.EOF) {
+			return rows, errs, nil
+		} else if err != nil {
+			return nil, nil, services.NewValidationError("not a valid CSV file: "+err.Error(), nil)
+		}
+
+		numbers, err := parseNumbers(f.get(record, "numbers"))
+		if err != nil {
+			errs = append(errs, models.ImportRowError{Line: line, Field: "numbers", Message: err.Error()})
+			continue
+		}
+		rows = append(rows, models.TicketImportRow{
+			Line:     line,
+			UserID:   f.get(record, "user_id"),
+			Username: f.get(record, "username"),
+			DrawID:   f.get(record, "draw_id"),
+			Numbers:  numbers,
+		})
+	}
+}
+
+func parseNumbers(v string) ([]int, error) {
+	parts := strings.FieldsFunc(v, func(r rune) bool {
+		return r == ' ' || r == ',' || r == ';' || r == '\t'
+	})
+	if len(parts) == 0 {
+		return nil, errors.New("is required")
+	}
+	numbers := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", p)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
@@ -0,0 +1,233 @@
+package importer
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/services"
+	"LotterySystem/internal/storage"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newService returns a service over a scratch data directory, with alice
+// registered and a draw open, and the directory.
+func newService(t *testing.T) (*services.LotteryService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	s := services.NewLotteryService(
+		storage.NewUserRepository(dir),
+		storage.NewDrawRepository(dir),
+		storage.NewTicketRepository(dir),
+		storage.NewPrizeRepository(dir),
+	)
+	s.SetAuditLog(storage.NewAuditLog(dir))
+	s.SetTicketCost(10)
+	s.SetStartingBalance(100)
+	if _, err := s.RegisterUser("alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateDraw(services.SystemActor); err != nil {
+		t.Fatal(err)
+	}
+	return s, dir
+}
+
+// files reads the data files an import writes.
+func files(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	m := map[string][]byte{}
+	for _, name := range []string{storage.UsersFile, storage.TicketsFile, storage.AuditFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		m[name] = data
+	}
+	return m
+}
+
+func unchanged(t *testing.T, dir string, before map[string][]byte) {
+	t.Helper()
+	for name, data := range files(t, dir) {
+		if !bytes.Equal(data, before[name]) {
+			t.Errorf("%s changed although nothing was imported", name)
+		}
+	}
+}
+
+// errorsOf lists a report's errors as "line field: message".
+func errorsOf(r models.ImportReport) []string {
+	var errs []string
+	for _, e := range r.Errors {
+		errs = append(errs, fmt.Sprintf("%d %s: %s", e.Line, e.Field, e.Message))
+	}
+	return errs
+}
+
+// validationFields returns the fields of err if it is a validation error.
+func validationFields(err error) (map[string]string, bool) {
+	var serr *services.Error
+	if !errors.As(err, &serr) || serr.Kind != services.ErrValidation {
+		return nil, false
+	}
+	return serr.Fields, true
+}
+
+func TestHeader(t *testing.T) {
+	for _, tt := range []struct {
+		name, dataset, csv string
+		fields             []string
+	}{
+		{"empty file", "users", "", []string{"file"}},
+		{"unknown column", "users", "username,password,email\n", []string{"email"}},
+		{"missing column", "users", "username\n", []string{"password"}},
+		{"missing user column", "tickets", "draw_id,numbers\n", []string{"user_id"}},
+	} {
+		s, _ := newService(t)
+		_, err := Run(s, "admin", tt.dataset, strings.NewReader(tt.csv), false)
+		fields, ok := validationFields(err)
+		if !ok {
+			t.Errorf("%s: err = %v, want a validation error", tt.name, err)
+			continue
+		}
+		for _, f := range tt.fields {
+			if _, ok := fields[f]; !ok {
+				t.Errorf("%s: fields = %v, want %s", tt.name, fields, f)
+			}
+		}
+	}
+}
+
+func TestMalformedRows(t *testing.T) {
+	s, dir := newService(t)
+	before := files(t, dir)
+
+	users := "username,password,balance\n" +
+		"bob,secret,50\n" +
+		"carol,secret,lots\n" + // not a number
+		",secret,\n" + // no username
+		"dave,,\n" + // no password
+		"erin,secret,-1\n" + // negative
+		"frank,secret,1000\n" // over the starting balance
+	report, err := Run(s, "admin", "users", strings.NewReader(users), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"3 balance: must be a whole number",
+		"4 username: is required",
+		"5 password: is required",
+		"6 balance: must not be negative",
+		"7 balance: must not exceed the starting balance of 100",
+	}
+	if got := errorsOf(report); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if report.Rows != 6 || report.Valid != 1 || report.Committed {
+		t.Errorf("rows %d, valid %d, committed %v; want 6, 1, false", report.Rows, report.Valid, report.Committed)
+	}
+	unchanged(t, dir, before)
+
+	tickets := "username,numbers\n" +
+		"alice,1 2 3 4 5 6\n" +
+		"alice,1 2 x 4 5 6\n" + // not a number
+		"alice,\n" + // no numbers
+		"alice,1;2;3;4;5;50\n" + // out of range
+		"alice,1 1 2 3 4 5\n" + // repeated
+		"nobody,1 2 3 4 5 6\n" // no such user
+	report, err = Run(s, "admin", "tickets", strings.NewReader(tickets), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 5 || report.Valid != 1 || report.Committed {
+		t.Errorf("errors %v, valid %d, committed %v; want 5, 1, false", errorsOf(report), report.Valid, report.Committed)
+	}
+	for _, e := range report.Errors {
+		if e.Line == 3 && e.Message != `"x" is not a number` {
+			t.Errorf("line 3: %s, want x reported", e.Message)
+		}
+	}
+	unchanged(t, dir, before)
+}
+
+func TestBrokenCSV(t *testing.T) {
+	s, dir := newService(t)
+	before := files(t, dir)
+	_, err := Run(s, "admin", "users", strings.NewReader("username,password\n\"bob,secret\n"), true)
+	if _, ok := validationFields(err); !ok {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	unchanged(t, dir, before)
+}
+
+func TestDuplicates(t *testing.T) {
+	s, dir := newService(t)
+	before := files(t, dir)
+
+	users := "username,password\n" +
+		"bob,secret\n" +
+		"alice,secret\n" + // already registered
+		"bob,other\n" // twice in the file
+	report, err := Run(s, "admin", "users", strings.NewReader(users), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"3 username: already exists", "4 username: duplicates line 2"}
+	if got := errorsOf(report); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors = %v, want %v", got, want)
+	}
+	if report.Committed {
+		t.Error("an import with duplicates was committed")
+	}
+	unchanged(t, dir, before)
+	if page, _ := s.QueryUsers(models.UserFilter{Username: "bob"}); len(page.Items) != 0 {
+		t.Errorf("%d users named bob after a failed import", len(page.Items))
+	}
+}
+
+func TestCommit(t *testing.T) {
+	s, dir := newService(t)
+	before := files(t, dir)
+
+	report, err := Run(s, "admin", "users", strings.NewReader("username,password,balance\nbob,secret,40\ncarol,secret\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || report.Valid != 2 || report.Committed {
+		t.Fatalf("dry run = valid %d, committed %v", report.Valid, report.Committed)
+	}
+	unchanged(t, dir, before)
+
+	report, err = Run(s, "admin", "users", strings.NewReader("username,password,balance\nbob,secret,40\ncarol,secret\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || len(report.Created) != 2 {
+		t.Fatalf("import = committed %v, created %v", report.Committed, report.Created)
+	}
+
+	report, err = Run(s, "admin", "tickets", strings.NewReader("username,numbers\nbob,1 2 3 4 5 6\nbob,7 8 9 10 11 12\ncarol,1 2 3 4 5 6\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || len(report.Created) != 3 {
+		t.Fatalf("ticket import = committed %v, created %v", report.Committed, report.Created)
+	}
+	bob, _ := s.GetUser(userID(t, s, "bob"))
+	if bob.Balance != 20 {
+		t.Errorf("bob's balance = %d, want 20", bob.Balance)
+	}
+}
+
+func userID(t *testing.T, s *services.LotteryService, name string) string {
+	t.Helper()
+	page, err := s.QueryUsers(models.UserFilter{Username: name})
+	if err != nil || len(page.Items) != 1 {
+		t.Fatalf("user %s: %v", name, err)
+	}
+	return page.Items[0].ID
+}
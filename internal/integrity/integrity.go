@@ -0,0 +1,191 @@
+// Package integrity cross-checks users, draws, tickets and prizes: records
+// pointing at ones that do not exist, prizes and tickets that disagree,
+// duplicate usernames, negative balances, completed draws whose tickets
+// were not all settled and money prizes of settled draws that were never
+// credited. It only reads; the service applies the repairs it suggests.
+package integrity
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/utils"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Data is every record, keyed as stored.
+type Data struct {
+	Users   map[string]models.User
+	Draws   map[string]models.Draw
+	Tickets map[string]models.Ticket
+	Prizes  map[string]models.Prize
+	// Settlements are keyed by draw ID. A completed draw without one was
+	// settled before settlements were kept.
+	Settlements map[string]models.Settlement
+}
+
+// Check returns every issue in d, sorted by kind and record.
+func Check(d Data) []models.IntegrityIssue {
+	c := checker{Data: d, issues: []models.IntegrityIssue{}}
+	c.users()
+	c.draws()
+	c.tickets()
+	c.prizes()
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		if c.issues[i].Kind != c.issues[j].Kind {
+			return c.issues[i].Kind < c.issues[j].Kind
+		}
+		return c.issues[i].Record < c.issues[j].Record
+	})
+	return c.issues
+}
+
+type checker struct {
+	Data
+	issues []models.IntegrityIssue
+}
+
+func (c *checker) add(kind, record, fix, format string, args ...any) {
+	c.issues = append(c.issues, models.IntegrityIssue{Kind: kind, Record: record, Detail: fmt.Sprintf(format, args...), Fix: fix})
+}
+
+func (c *checker) keyed(kind, id, key string) {
+	if id != key {
+		c.add(models.IssueKeyMismatch, key, "", "%s %s is stored under %s", kind, id, key)
+	}
+}
+
+func (c *checker) users() {
+	byName := map[string][]string{}
+	for key, u := range c.Users {
+		c.keyed("user", u.ID, key)
+		byName[u.Username] = append(byName[u.Username], key)
+		if u.Balance < 0 {
+			c.add(models.IssueNegativeBalance, key, "", "balance is %d", u.Balance)
+		}
+	}
+	for name, ids := range byName {
+		if len(ids) > 1 {
+			sort.Strings(ids)
+			c.add(models.IssueDuplicateUsername, ids[0], "", "username %q is shared by %s", name, strings.Join(ids, ", "))
+		}
+	}
+}
+
+func (c *checker) draws() {
+	var open []string
+	for key, d := range c.Draws {
+		c.keyed("draw", d.ID, key)
+		if d.Status == "pending" || d.Status == "drawing" {
+			open = append(open, key)
+		}
+	}
+	if len(open) > 1 {
+		sort.Strings(open)
+		c.add(models.IssueOpenDraws, open[0], "", "%d draws are open at once: %s", len(open), strings.Join(open, ", "))
+	}
+}
+
+func (c *checker) tickets() {
+	prizeFor := map[string]string{}
+	for key, p := range c.Prizes {
+		prizeFor[p.TicketID] = key
+	}
+
+	for key, t := range c.Tickets {
+		c.keyed("ticket", t.ID, key)
+		if _, ok := c.Users[t.UserID]; !ok {
+			c.add(models.IssueTicketUserMissing, key, "", "user %s does not exist", t.UserID)
+		}
+		draw, ok := c.Draws[t.DrawID]
+		if !ok {
+			c.add(models.IssueTicketDrawMissing, key, "", "draw %s does not exist", t.DrawID)
+		}
+
+		awarded := prizeFor[key]
+		switch {
+		case t.PrizeID == awarded:
+		case awarded != "":
+			c.add(models.IssueTicketPrizeLink, key, "point the ticket at prize "+awarded,
+				"prize_id is %q but prize %s was awarded for it", t.PrizeID, awarded)
+		default:
+			detail := "prize %s does not exist"
+			if _, exists := c.Prizes[t.PrizeID]; exists {
+				detail = "prize %s was awarded for another ticket"
+			}
+			c.add(models.IssueTicketPrizeLink, key, "clear the ticket's prize_id", detail, t.PrizeID)
+		}
+
+		if ok && draw.Status == "completed" && !t.IsCancelled() {
+			c.settled(key, t, draw, awarded)
+		}
+	}
+}
+
+// settled checks that a ticket in a completed draw has its matches counted
+// and, if they win something, a prize.
+func (c *checker) settled(key string, t models.Ticket, draw models.Draw, awarded string) {
+	matches := utils.CountMatches(t.Numbers, draw.WinningNumbers)
+	due := len(models.PrizeDefinitions[matches]) > 0
+	switch {
+	case due && awarded == "":
+		c.add(models.IssueTicketUnsettled, key, "", "matches %d numbers but has no prize", matches)
+	case t.Matches != matches && !due:
+		c.add(models.IssueTicketUnsettled, key, fmt.Sprintf("set matches to %d", matches),
+			"matches %d numbers but records %d", matches, t.Matches)
+	case t.Matches != matches:
+		c.add(models.IssueTicketUnsettled, key, "", "matches %d numbers but records %d", matches, t.Matches)
+	}
+}
+
+func (c *checker) prizes() {
+	for key, p := range c.Prizes {
+		c.keyed("prize", p.ID, key)
+		if _, ok := c.Users[p.UserID]; !ok {
+			c.add(models.IssuePrizeUserMissing, key, "", "user %s does not exist", p.UserID)
+		}
+
+		t, ok := c.Tickets[p.TicketID]
+		drawID := p.DrawID
+		if ok {
+			drawID = t.DrawID
+		}
+		c.credited(key, p, drawID)
+		if !ok {
+			c.add(models.IssuePrizeTicketMissing, key, "", "ticket %s does not exist", p.TicketID)
+			if _, ok := c.Draws[p.DrawID]; p.DrawID != "" && !ok {
+				c.add(models.IssuePrizeDrawMissing, key, "", "draw %s does not exist", p.DrawID)
+			}
+			continue
+		}
+		// Paying the right user means moving money, so it is left to a
+		// person.
+		if t.UserID != p.UserID {
+			c.add(models.IssuePrizeUserMismatch, key, "", "awarded to %s but ticket %s belongs to %s", p.UserID, t.ID, t.UserID)
+		}
+		// Prizes from before draw IDs were recorded have none until the
+		// data is migrated.
+		if p.DrawID != "" && t.DrawID != p.DrawID {
+			c.add(models.IssuePrizeDrawMismatch, key, "set draw_id to "+t.DrawID,
+				"draw_id is %q but ticket %s is in draw %s", p.DrawID, t.ID, t.DrawID)
+		}
+	}
+}
+
+// credited checks that a money prize of a settled draw was added to its
+// winner's balance. A settlement that is running or failed will credit it
+// when it is resumed or the draw resettled; otherwise paying it moves
+// money, and the audit log may show it was paid and only not marked, so it
+// is left to a person.
+func (c *checker) credited(key string, p models.Prize, drawID string) {
+	if p.Type != models.Money || p.CreditedAt != nil {
+		return
+	}
+	if d, ok := c.Draws[drawID]; !ok || d.Status != "completed" {
+		return
+	}
+	if st, ok := c.Settlements[drawID]; ok && st.Status != models.SettlementCompleted {
+		return
+	}
+	c.add(models.IssuePrizeUncredited, key, "", "%d money prize of settled draw %s was never credited to %s", p.Value, drawID, p.UserID)
+}
@@ -0,0 +1,171 @@
+package integrity
+
+import (
+	"LotterySystem/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+// clean returns records with no issues: a settled draw with a winning and a
+// losing ticket, and an open draw.
+func clean() Data {
+	now := time.Now()
+	return Data{
+		Users: map[string]models.User{
+			"u1": {ID: "u1", Username: "alice", Balance: 100},
+		},
+		Draws: map[string]models.Draw{
+			"d1": {ID: "d1", Status: "completed", WinningNumbers: []int{1, 2, 3, 4, 5, 6}},
+			"d2": {ID: "d2", Status: "pending", WinningNumbers: []int{}},
+		},
+		Tickets: map[string]models.Ticket{
+			"t1": {ID: "t1", UserID: "u1", DrawID: "d1", Numbers: []int{1, 2, 3, 10, 11, 12}, Matches: 3, PrizeID: "p1", Status: "active"},
+			"t2": {ID: "t2", UserID: "u1", DrawID: "d1", Numbers: []int{20, 21, 22, 23, 24, 25}, Status: "active"},
+		},
+		Prizes: map[string]models.Prize{
+			"p1": {ID: "p1", TicketID: "t1", UserID: "u1", DrawID: "d1", Type: models.Money, Value: 500, MatchesCount: 3, CreditedAt: &now},
+		},
+		Settlements: map[string]models.Settlement{},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(d Data)
+		kinds []string
+	}{
+		{"clean", func(d Data) {}, nil},
+		{"record under another key", func(d Data) {
+			d.Draws["d3"] = models.Draw{ID: "d4", Status: "completed"}
+		}, []string{models.IssueKeyMismatch}},
+		{"negative balance", func(d Data) {
+			d.Users["u1"] = models.User{ID: "u1", Username: "alice", Balance: -5}
+		}, []string{models.IssueNegativeBalance}},
+		{"shared username", func(d Data) {
+			d.Users["u2"] = models.User{ID: "u2", Username: "alice"}
+		}, []string{models.IssueDuplicateUsername}},
+		{"two open draws", func(d Data) {
+			d.Draws["d3"] = models.Draw{ID: "d3", Status: "drawing"}
+		}, []string{models.IssueOpenDraws}},
+		{"ticket of a missing user", func(d Data) {
+			t := d.Tickets["t2"]
+			t.UserID = "ghost"
+			d.Tickets["t2"] = t
+		}, []string{models.IssueTicketUserMissing}},
+		{"ticket in a missing draw", func(d Data) {
+			t := d.Tickets["t2"]
+			t.DrawID = "ghost"
+			d.Tickets["t2"] = t
+		}, []string{models.IssueTicketDrawMissing}},
+		{"ticket pointing at a missing prize", func(d Data) {
+			t := d.Tickets["t2"]
+			t.PrizeID = "ghost"
+			d.Tickets["t2"] = t
+		}, []string{models.IssueTicketPrizeLink}},
+		{"ticket with miscounted matches", func(d Data) {
+			t := d.Tickets["t1"]
+			t.Matches = 2
+			d.Tickets["t1"] = t
+		}, []string{models.IssueTicketUnsettled}},
+		{"winning ticket without a prize", func(d Data) {
+			t := d.Tickets["t2"]
+			t.Numbers = []int{1, 2, 3, 4, 24, 25}
+			t.Matches = 4
+			d.Tickets["t2"] = t
+		}, []string{models.IssueTicketUnsettled}},
+		{"prize for a missing ticket", func(d Data) {
+			d.Prizes["p2"] = models.Prize{ID: "p2", TicketID: "ghost", UserID: "u1", DrawID: "d1", Type: models.Gift}
+		}, []string{models.IssuePrizeTicketMissing}},
+		{"prize for a missing ticket and draw", func(d Data) {
+			d.Prizes["p2"] = models.Prize{ID: "p2", TicketID: "ghost", UserID: "u1", DrawID: "ghost", Type: models.Gift}
+		}, []string{models.IssuePrizeDrawMissing, models.IssuePrizeTicketMissing}},
+		{"prize of a missing user", func(d Data) {
+			p := d.Prizes["p1"]
+			p.UserID = "ghost"
+			d.Prizes["p1"] = p
+		}, []string{models.IssuePrizeUserMismatch, models.IssuePrizeUserMissing}},
+		{"prize awarded to someone else", func(d Data) {
+			d.Users["u2"] = models.User{ID: "u2", Username: "bob"}
+			p := d.Prizes["p1"]
+			p.UserID = "u2"
+			d.Prizes["p1"] = p
+		}, []string{models.IssuePrizeUserMismatch}},
+		{"prize in another draw than its ticket", func(d Data) {
+			p := d.Prizes["p1"]
+			p.DrawID = "d2"
+			d.Prizes["p1"] = p
+		}, []string{models.IssuePrizeDrawMismatch}},
+		{"money prize never credited", func(d Data) {
+			p := d.Prizes["p1"]
+			p.CreditedAt = nil
+			d.Prizes["p1"] = p
+		}, []string{models.IssuePrizeUncredited}},
+		{"money prize never credited by a completed settlement", func(d Data) {
+			p := d.Prizes["p1"]
+			p.CreditedAt = nil
+			d.Prizes["p1"] = p
+			d.Settlements["d1"] = models.Settlement{DrawID: "d1", Status: models.SettlementCompleted}
+		}, []string{models.IssuePrizeUncredited}},
+		{"money prize a failed settlement has yet to credit", func(d Data) {
+			p := d.Prizes["p1"]
+			p.CreditedAt = nil
+			d.Prizes["p1"] = p
+			d.Settlements["d1"] = models.Settlement{DrawID: "d1", Status: models.SettlementFailed}
+		}, nil},
+		{"gift prize", func(d Data) {
+			p := d.Prizes["p1"]
+			p.Type = models.Gift
+			p.CreditedAt = nil
+			d.Prizes["p1"] = p
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := clean()
+			tt.edit(d)
+			var kinds []string
+			for _, issue := range Check(d) {
+				kinds = append(kinds, issue.Kind)
+			}
+			if !slices.Equal(kinds, tt.kinds) {
+				t.Errorf("issues = %v, want %v", kinds, tt.kinds)
+			}
+		})
+	}
+}
+
+func TestCheckSuggestsFixes(t *testing.T) {
+	d := clean()
+	t1 := d.Tickets["t1"]
+	t1.PrizeID = ""
+	d.Tickets["t1"] = t1
+	t2 := d.Tickets["t2"]
+	t2.Matches = 1
+	d.Tickets["t2"] = t2
+	p := d.Prizes["p1"]
+	p.DrawID = "d2"
+	p.CreditedAt = nil
+	d.Prizes["p1"] = p
+
+	fixes := map[string]string{}
+	for _, issue := range Check(d) {
+		fixes[issue.Kind] = issue.Fix
+	}
+	want := map[string]string{
+		models.IssueTicketPrizeLink:   "point the ticket at prize p1",
+		models.IssueTicketUnsettled:   "set matches to 0",
+		models.IssuePrizeDrawMismatch: "set draw_id to d1",
+		// Paying a prize moves money, so it is left to a person.
+		models.IssuePrizeUncredited: "",
+	}
+	if len(fixes) != len(want) {
+		t.Errorf("issues = %v, want %v", fixes, want)
+	}
+	for kind, fix := range want {
+		if got, ok := fixes[kind]; !ok || got != fix {
+			t.Errorf("%s fix = %q, want %q", kind, got, fix)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package metrics keeps counters, gauges and histograms in memory and serves
+// them in the Prometheus text exposition format. It covers only what this
+// server needs, so there is no dependency on the Prometheus client.
+//
+// Metrics are created once, usually as package variables, and register
+// themselves with Default:
+//
+//	var ticketsSold = metrics.NewCounter("lottery_tickets_sold_total", "Tickets sold.")
+//	ticketsSold.Inc()
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefBuckets suits request and write latencies, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds metrics by name.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+type metric interface {
+	write(w io.Writer, name string)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+// Default is the registry the New functions register with and Handler
+// serves.
+var Default = NewRegistry()
+
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.metrics[name]; dup {
+		panic("metrics: " + name + " registered twice")
+	}
+	r.metrics[name] = m
+}
+
+// WriteText writes every metric, sorted by name.
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([]metric, len(names))
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.Unlock()
+
+	for i, name := range names {
+		metrics[i].write(w, name)
+	}
+}
+
+// Handler serves the Default registry.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Default.WriteText(w)
+	})
+}
+
+// value is a float64 updated atomically.
+type value struct {
+	bits uint64
+}
+
+func (v *value) add(d float64) {
+	for {
+		old := atomic.LoadUint64(&v.bits)
+		next := math.Float64bits(math.Float64frombits(old) + d)
+		if atomic.CompareAndSwapUint64(&v.bits, old, next) {
+			return
+		}
+	}
+}
+
+func (v *value) load() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&v.bits))
+}
+
+// vec keeps one series per combination of label values.
+type vec[T any] struct {
+	help   string
+	kind   string
+	labels []string
+	create func() *T
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func newVec[T any](help, kind string, labels []string, create func() *T) *vec[T] {
+	return &vec[T]{
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		create: create,
+		series: map[string]*T{},
+		values: map[string][]string{},
+	}
+}
+
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for %d labels", len(values), len(v.labels)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = v.create()
+		v.series[key] = s
+		v.values[key] = append([]string(nil), values...)
+	}
+	return s
+}
+
+// each calls fn for every series, ordered by label values.
+func (v *vec[T]) each(fn func(labels string, s *T)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	series := make([]*T, len(keys))
+	values := make([][]string, len(keys))
+	for i, k := range keys {
+		series[i] = v.series[k]
+		values[i] = v.values[k]
+	}
+	v.mu.Unlock()
+
+	for i := range keys {
+		fn(formatLabels(v.labels, values[i]), series[i])
+	}
+}
+
+func (v *vec[T]) header(w io.Writer, name string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(v.help), name, v.kind)
+}
+
+// Counter only goes up.
+type Counter struct {
+	v value
+}
+
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add increases the counter; negative amounts are ignored.
+func (c *Counter) Add(d float64) {
+	if d > 0 {
+		c.v.add(d)
+	}
+}
+
+type CounterVec struct {
+	vec *vec[Counter]
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec: newVec(help, "counter", labels, func() *Counter { return &Counter{} })}
+	Default.register(name, c)
+	return c
+}
+
+// NewCounter registers a counter without labels.
+func NewCounter(name, help string) *Counter {
+	return NewCounterVec(name, help).With()
+}
+
+// With returns the counter for the given label values, in the order the
+// labels were declared.
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.vec.with(values)
+}
+
+func (c *CounterVec) write(w io.Writer, name string) {
+	c.vec.header(w, name)
+	c.vec.each(func(labels string, s *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(s.v.load()))
+	})
+}
+
+// GaugeFunc reports the value fn returns at scrape time.
+type GaugeFunc struct {
+	help string
+	fn   func() float64
+}
+
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{help: help, fn: fn}
+	Default.register(name, g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, escapeHelp(g.help), name, name, formatFloat(g.fn()))
+}
+
+// Histogram counts observations into buckets by upper bound.
+type Histogram struct {
+	upper  []float64
+	counts []uint64
+	count  uint64
+	sum    value
+}
+
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+	if i < len(h.counts) {
+		atomic.AddUint64(&h.counts[i], 1)
+	}
+	h.sum.add(v)
+	atomic.AddUint64(&h.count, 1)
+}
+
+// Since observes the seconds elapsed since start.
+func (h *Histogram) Since(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+type HistogramVec struct {
+	vec *vec[Histogram]
+}
+
+// NewHistogramVec registers a histogram with the given bucket upper bounds,
+// which must be sorted; DefBuckets is used when buckets is nil.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	create := func() *Histogram {
+		return &Histogram{upper: buckets, counts: make([]uint64, len(buckets))}
+	}
+	h := &HistogramVec{vec: newVec(help, "histogram", labels, create)}
+	Default.register(name, h)
+	return h
+}
+
+// NewHistogram registers a histogram without labels.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return NewHistogramVec(name, help, buckets).With()
+}
+
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.vec.with(values)
+}
+
+func (h *HistogramVec) write(w io.Writer, name string) {
+	h.vec.header(w, name)
+	h.vec.each(func(labels string, s *Histogram) {
+		// Read the total first so the buckets never add up to more than it.
+		count := atomic.LoadUint64(&s.count)
+		var cumulative uint64
+		for i, upper := range s.upper {
+			cumulative += atomic.LoadUint64(&s.counts[i])
+			if cumulative > count {
+				cumulative = count
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(s.sum.load()))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, labels, count)
+	})
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel adds one more label to an already formatted label set.
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + escapeLabel(value) + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, +1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
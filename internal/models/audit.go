@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records one administrative or financial action. Each entry
+// carries the hash of the one before it, so editing, removing or
+// reordering an entry breaks every hash after it.
+type AuditEntry struct {
+	Seq    int             `json:"seq"`
+	Time   time.Time       `json:"time"`
+	Actor  string          `json:"actor"`
+	Action string          `json:"action"`
+	Target string          `json:"target,omitempty"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// AuditFilter sorts by "seq". Target is the ID of the record acted on.
+type AuditFilter struct {
+	ListOptions
+	Actor  string
+	Action string
+	Target string
+}
+
+// AuditVerification is the result of checking the hash chain. When OK is
+// false, BrokenAt is the line of the first entry that does not check out
+// and everything from there on is suspect. Head is the hash of the last
+// good entry; comparing it with a copy kept elsewhere also catches entries
+// cut off the end.
+type AuditVerification struct {
+	OK       bool   `json:"ok"`
+	Entries  int    `json:"entries"`
+	Head     string `json:"head,omitempty"`
+	BrokenAt int    `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
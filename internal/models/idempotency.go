@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// IdempotentResponse is the response to a request that carried an
+// Idempotency-Key, kept to answer retries of it. Key is the caller's scope
+// and the key they sent.
+type IdempotentResponse struct {
+	Key         string              `json:"key"`
+	Fingerprint string              `json:"fingerprint"`
+	Status      int                 `json:"status"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// UserImportRow is one parsed line of a user import. A nil Balance means
+// the standard starting balance.
+type UserImportRow struct {
+	Line     int
+	Username string
+	Password string
+	Balance  *int
+}
+
+// TicketImportRow is one parsed line of a ticket import. The user is given
+// by ID or username; an empty DrawID means the pending draw.
+type TicketImportRow struct {
+	Line     int
+	UserID   string
+	Username string
+	DrawID   string
+	Numbers  []int
+}
+
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport describes an import run. Nothing is written unless Committed
+// is true, and a commit only happens when Errors is empty.
+type ImportReport struct {
+	Dataset   string           `json:"dataset"`
+	DryRun    bool             `json:"dry_run"`
+	Rows      int              `json:"rows"`
+	Valid     int              `json:"valid"`
+	Committed bool             `json:"committed"`
+	Created   []string         `json:"created,omitempty"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+func (r *ImportReport) AddError(line int, field, message string) {
+	r.Errors = append(r.Errors, ImportRowError{Line: line, Field: field, Message: message})
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Integrity issue kinds.
+const (
+	IssueKeyMismatch        = "key_mismatch"
+	IssueTicketUserMissing  = "ticket_user_missing"
+	IssueTicketDrawMissing  = "ticket_draw_missing"
+	IssueTicketPrizeLink    = "ticket_prize_link"
+	IssueTicketUnsettled    = "ticket_unsettled"
+	IssuePrizeTicketMissing = "prize_ticket_missing"
+	IssuePrizeUserMissing   = "prize_user_missing"
+	IssuePrizeUserMismatch  = "prize_user_mismatch"
+	IssuePrizeDrawMismatch  = "prize_draw_mismatch"
+	IssuePrizeDrawMissing   = "prize_draw_missing"
+	IssuePrizeUncredited    = "prize_uncredited"
+	IssueDuplicateUsername  = "duplicate_username"
+	IssueNegativeBalance    = "negative_balance"
+	IssueOpenDraws          = "multiple_open_draws"
+)
+
+// IntegrityIssue is one problem found in the stored records. Fix says what
+// a repair would do; it is empty when the issue needs a person to decide.
+type IntegrityIssue struct {
+	Kind     string `json:"kind"`
+	Record   string `json:"record"`
+	Detail   string `json:"detail"`
+	Fix      string `json:"fix,omitempty"`
+	Repaired bool   `json:"repaired,omitempty"`
+}
+
+type IntegrityReport struct {
+	CheckedAt time.Time        `json:"checked_at"`
+	Records   map[string]int   `json:"records"`
+	Issues    []IntegrityIssue `json:"issues"`
+	// Repaired counts the issues fixed, by a repair that was not a dry run.
+	Repaired int  `json:"repaired"`
+	DryRun   bool `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// LedgerEntry is one balance movement. Amount is negative for debits. Kind is
+// "ticket_purchase", "ticket_refund", "prize" or "adjustment", the same
+// reasons used in balance notifications. Reason is the one an admin gave for
+// an adjustment.
+type LedgerEntry struct {
+	Time     time.Time `json:"time"`
+	UserID   string    `json:"user_id"`
+	Kind     string    `json:"kind"`
+	Amount   int       `json:"amount"`
+	TicketID string    `json:"ticket_id,omitempty"`
+	DrawID   string    `json:"draw_id,omitempty"`
+	PrizeID  string    `json:"prize_id,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type PrizeType string
 
 const (
@@ -9,13 +11,17 @@ const (
 )
 
 type Prize struct {
-	ID           string    json:"id"
-	TicketID     string    json:"ticket_id"
-	UserID       string    json:"user_id"
-	Type         PrizeType json:"type"
-	Name         string    json:"name"
-	Value        int       json:"value"
-	MatchesCount int       json:"matches_count"
+	ID           string    `json:"id"`
+	TicketID     string    `json:"ticket_id"`
+	UserID       string    `json:"user_id"`
+	DrawID       string    `json:"draw_id,omitempty"`
+	Type         PrizeType `json:"type"`
+	Name         string    `json:"name"`
+	Value        int       `json:"value"`
+	MatchesCount int       `json:"matches_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	// CreditedAt is when a money prize was added to the user's balance.
+	CreditedAt *time.Time `json:"credited_at,omitempty"`
 }
 
 var PrizeDefinitions = map[int][]Prize{
@@ -37,4 +43,4 @@ var PrizeDefinitions = map[int][]Prize{
 	6: {
 		{Type: Money, Name: "Jackpot", Value: 100000, MatchesCount: 6},
 	},
-}
\ No newline at end of file
+}
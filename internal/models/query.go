@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// ListOptions controls ordering and paging of list queries. Sort names a
+// field such as "created_at"; Cursor is the opaque NextCursor of a previous
+// page. A zero Limit returns everything.
+type ListOptions struct {
+	Sort   string
+	Desc   bool
+	Limit  int
+	Cursor string
+}
+
+// Page sizes shared by every API that lists records.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// NewListOptions builds the options of a list request the same way for
+// every API: newest or largest first unless asc, DefaultPageSize items when
+// limit is zero, and never more than MaxPageSize.
+func NewListOptions(sort string, asc bool, limit int, cursor string) ListOptions {
+	opts := ListOptions{Sort: sort, Desc: !asc, Limit: DefaultPageSize, Cursor: cursor}
+	if limit > 0 {
+		opts.Limit = min(limit, MaxPageSize)
+	}
+	return opts
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// DrawFilter sorts by "created_at" or "draw_date". From and To bound the
+// draw date and are ignored when zero.
+type DrawFilter struct {
+	ListOptions
+	Status string
+	From   time.Time
+	To     time.Time
+}
+
+// UserFilter sorts by "created_at", "username" or "balance". Username
+// matches any part of the name, ignoring case.
+type UserFilter struct {
+	ListOptions
+	Username string
+	Role     string
+}
+
+// PrizeFilter sorts by "created_at" or "value".
+type PrizeFilter struct {
+	ListOptions
+	Type       PrizeType
+	DrawID     string
+	UserID     string
+	MinMatches int
+}
+
+// TicketFilter sorts by "created_at" or "matches". From and To bound the
+// purchase time and are ignored when zero.
+type TicketFilter struct {
+	ListOptions
+	UserID     string
+	DrawID     string
+	Status     string
+	MinMatches int
+	From       time.Time
+	To         time.Time
+}
+
+// ExportFilter narrows an export to one draw and/or a time range. From and
+// To bound each record's timestamp and are ignored when zero.
+type ExportFilter struct {
+	DrawID string
+	From   time.Time
+	To     time.Time
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type Receipt struct {
+	TicketID string    `json:"ticket_id"`
+	Serial   string    `json:"serial"`
+	DrawID   string    `json:"draw_id"`
+	Numbers  []int     `json:"numbers"`
+	IssuedAt time.Time `json:"issued_at"`
+	Payload  string    `json:"payload"`
+}
+
+// ReceiptVerification is the public answer to a receipt check. It never
+// carries the ticket owner.
+type ReceiptVerification struct {
+	Valid          bool      `json:"valid"`
+	Serial         string    `json:"serial,omitempty"`
+	DrawID         string    `json:"draw_id,omitempty"`
+	DrawStatus     string    `json:"draw_status,omitempty"`
+	TicketStatus   string    `json:"ticket_status,omitempty"`
+	Numbers        []int     `json:"numbers,omitempty"`
+	WinningNumbers []int     `json:"winning_numbers,omitempty"`
+	Matches        int       `json:"matches"`
+	PrizeType      PrizeType `json:"prize_type,omitempty"`
+	PrizeName      string    `json:"prize_name,omitempty"`
+	PrizeValue     int       `json:"prize_value,omitempty"`
+}
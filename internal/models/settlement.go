@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Settlement statuses.
+const (
+	SettlementRunning   = "running"
+	SettlementCompleted = "completed"
+	SettlementFailed    = "failed"
+)
+
+// Settlement tracks the settling of a draw's tickets. It is saved as the
+// tickets are worked through, so one cut short shows how far it got. Run
+// counts the settlements of the draw: the first when it is executed, then
+// one per resettle. The counts are of what the latest run did.
+type Settlement struct {
+	DrawID     string     `json:"draw_id"`
+	Status     string     `json:"status"`
+	Run        int        `json:"run"`
+	Tickets    int        `json:"tickets"`
+	Processed  int        `json:"processed"`
+	Winners    int        `json:"winners"`
+	Awarded    int        `json:"awarded"`
+	Credited   int        `json:"credited"`
+	Updated    int        `json:"updated"`
+	Failed     int        `json:"failed"`
+	LastError  string     `json:"last_error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Discrepancy kinds found by reconciliation.
+const (
+	DiscrepancyMatches      = "matches"
+	DiscrepancyPrizeMissing = "prize_missing"
+	DiscrepancyPrizeWrong   = "prize_unexpected"
+	DiscrepancyPrizeLink    = "prize_unlinked"
+	DiscrepancyCredit       = "credit_missing"
+	// DiscrepancyCreditMark is a money prize the audit log shows was
+	// credited but that is not marked credited.
+	DiscrepancyCreditMark = "credit_unmarked"
+)
+
+type Discrepancy struct {
+	TicketID string `json:"ticket_id"`
+	PrizeID  string `json:"prize_id,omitempty"`
+	Kind     string `json:"kind"`
+	Detail   string `json:"detail"`
+}
+
+// Reconciliation compares what a draw's tickets should have won, worked
+// out again from the winning numbers, with the prizes awarded and the
+// money credited. Payouts count money prizes only.
+type Reconciliation struct {
+	DrawID          string        `json:"draw_id"`
+	Settlement      *Settlement   `json:"settlement,omitempty"`
+	Tickets         int           `json:"tickets"`
+	ExpectedWinners int           `json:"expected_winners"`
+	ActualWinners   int           `json:"actual_winners"`
+	ExpectedPayout  int           `json:"expected_payout"`
+	AwardedPayout   int           `json:"awarded_payout"`
+	CreditedPayout  int           `json:"credited_payout"`
+	Discrepancies   []Discrepancy `json:"discrepancies"`
+	Balanced        bool          `json:"balanced"`
+}
@@ -2,12 +2,24 @@ package models
 
 import "time"
 
+// LegacyTicketPrice is what tickets bought before prices were recorded on
+// them paid.
+const LegacyTicketPrice = 100
+
 type Ticket struct {
-	ID        string    json:"id"
-	UserID    string    json:"user_id"
-	DrawID    string    json:"draw_id"
-	Numbers   []int     json:"numbers"
-	Matches   int       json:"matches"
-	PrizeID   string    json:"prize_id,omitempty"
-	CreatedAt time.Time json:"created_at"
-}
\ No newline at end of file
+	ID          string     `json:"id"`
+	Serial      string     `json:"serial,omitempty"`
+	UserID      string     `json:"user_id"`
+	DrawID      string     `json:"draw_id"`
+	Numbers     []int      `json:"numbers"`
+	Matches     int        `json:"matches"`
+	PrizeID     string     `json:"prize_id,omitempty"`
+	Price       int        `json:"price"`
+	Status      string     `json:"status"` // "active", "cancelled"
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (t Ticket) IsCancelled() bool {
+	return t.Status == "cancelled"
+}
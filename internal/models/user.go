@@ -2,10 +2,25 @@ package models
 
 import "time"
 
+// Roles. Users registered before roles existed have none and are players.
+const (
+	RolePlayer = "player"
+	RoleAdmin  = "admin"
+)
+
 type User struct {
-	ID        string    json:"id"
-	Username  string    json:"username"
-	Password  string    json:"password,omitempty"
-	Balance   int       json:"balance"
-	CreatedAt time.Time json:"created_at"
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password,omitempty"`
+	Balance   int       `json:"balance"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+func IsRole(role string) bool {
+	return role == RolePlayer || role == RoleAdmin
 }
\ No newline at end of file
@@ -0,0 +1,496 @@
+// Package qrcode is a minimal QR code encoder used to render ticket receipts.
+// It supports byte mode at error correction level M for versions 1 through 10,
+// which is more than enough for a signed receipt payload.
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const quietZone = 4
+
+type blockSpec struct {
+	ecPerBlock int
+	g1Blocks   int
+	g1Data     int
+	g2Blocks   int
+	g2Data     int
+}
+
+// Error correction layout for level M, indexed by version.
+var levelM = [...]blockSpec{
+	1:  {10, 1, 16, 0, 0},
+	2:  {16, 1, 28, 0, 0},
+	3:  {26, 1, 44, 0, 0},
+	4:  {18, 2, 32, 0, 0},
+	5:  {24, 2, 43, 0, 0},
+	6:  {16, 4, 27, 0, 0},
+	7:  {18, 4, 31, 0, 0},
+	8:  {22, 2, 38, 2, 39},
+	9:  {22, 3, 36, 2, 37},
+	10: {26, 4, 43, 1, 44},
+}
+
+var alignmentPositions = [...][]int{
+	1:  {},
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+var remainderBits = [...]int{1: 0, 2: 7, 3: 7, 4: 7, 5: 7, 6: 7, 7: 0, 8: 0, 9: 0, 10: 0}
+
+var ErrTooLong = errors.New("qrcode: data too long")
+
+// Code is an encoded QR symbol. Modules are indexed [row][column]; true is dark.
+type Code struct {
+	Version int
+	Size    int
+	Modules [][]bool
+
+	function [][]bool
+}
+
+func (b blockSpec) dataCodewords() int {
+	return b.g1Blocks*b.g1Data + b.g2Blocks*b.g2Data
+}
+
+// Encode builds the smallest QR symbol that holds data in byte mode.
+func Encode(data []byte) (*Code, error) {
+	version := 0
+	for v := 1; v < len(levelM); v++ {
+		if len(data)+countBits(v)/8+1 <= levelM[v].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := interleave(version, encodeData(version, data))
+
+	c := newCode(version)
+	c.drawFunctionPatterns()
+	c.drawCodewords(codewords)
+
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			bestMask, bestPenalty = mask, p
+		}
+		c.applyMask(mask)
+	}
+	c.applyMask(bestMask)
+	c.drawFormatBits(bestMask)
+
+	return c, nil
+}
+
+// Image renders the code with a quiet zone, scale pixels per module.
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	dim := (c.Size + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, dim, dim))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, color.Gray{Y: 0})
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG returns the code rendered as a PNG image.
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func encodeData(version int, data []byte) []byte {
+	capacity := levelM[version].dataCodewords() * 8
+
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	return bits.bytes()
+}
+
+func interleave(version int, data []byte) []byte {
+	spec := levelM[version]
+	divisor := rsDivisor(spec.ecPerBlock)
+
+	var blocks, ecc [][]byte
+	offset := 0
+	for i := 0; i < spec.g1Blocks+spec.g2Blocks; i++ {
+		n := spec.g1Data
+		if i >= spec.g1Blocks {
+			n = spec.g2Data
+		}
+		block := data[offset : offset+n]
+		offset += n
+		blocks = append(blocks, block)
+		ecc = append(ecc, rsRemainder(block, divisor))
+	}
+
+	result := make([]byte, 0, len(data)+len(blocks)*spec.ecPerBlock)
+	longest := spec.g1Data
+	if spec.g2Data > longest {
+		longest = spec.g2Data
+	}
+	for i := 0; i < longest; i++ {
+		for _, b := range blocks {
+			if i < len(b) {
+				result = append(result, b[i])
+			}
+		}
+	}
+	for i := 0; i < spec.ecPerBlock; i++ {
+		for _, e := range ecc {
+			result = append(result, e[i])
+		}
+	}
+	return result
+}
+
+func newCode(version int) *Code {
+	size := version*4 + 17
+	c := &Code{Version: version, Size: size}
+	c.Modules = make([][]bool, size)
+	c.function = make([][]bool, size)
+	for i := range c.Modules {
+		c.Modules[i] = make([]bool, size)
+		c.function[i] = make([]bool, size)
+	}
+	return c
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.Modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	pos := alignmentPositions[c.Version]
+	last := len(pos) - 1
+	for i, x := range pos {
+		for j, y := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(x, y)
+		}
+	}
+
+	// Reserve the format areas; real bits are drawn once a mask is chosen.
+	c.drawFormatBits(0)
+	c.drawVersionBits()
+}
+
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= c.Size || y < 0 || y >= c.Size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			c.setFunction(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	// Level M is encoded as 00 in the format information.
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+func (c *Code) drawVersionBits() {
+	if c.Version < 7 {
+		return
+	}
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := c.Version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		a := c.Size - 11 + i%3
+		b := i / 3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+func (c *Code) drawCodewords(data []byte) {
+	total := len(data)*8 + remainderBits[c.Version]
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.function[y][x] || i >= total {
+					continue
+				}
+				if i < len(data)*8 {
+					c.Modules[y][x] = bit(int(data[i>>3]), 7-(i&7))
+				}
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				c.Modules[y][x] = !c.Modules[y][x]
+			}
+		}
+	}
+}
+
+func (c *Code) penalty() int {
+	result := 0
+	dark := 0
+
+	line := func(get func(i int) bool) {
+		run := 1
+		for i := 1; i < c.Size; i++ {
+			if get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				result += run - 2
+			}
+			run = 1
+		}
+		if run >= 5 {
+			result += run - 2
+		}
+
+		for i := 0; i+7 <= c.Size; i++ {
+			if get(i) && !get(i+1) && get(i+2) && get(i+3) && get(i+4) && !get(i+5) && get(i+6) {
+				result += 40
+			}
+		}
+	}
+
+	for y := 0; y < c.Size; y++ {
+		row := y
+		line(func(i int) bool { return c.Modules[row][i] })
+	}
+	for x := 0; x < c.Size; x++ {
+		col := x
+		line(func(i int) bool { return c.Modules[i][col] })
+	}
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				v := c.Modules[y][x]
+				if v == c.Modules[y][x+1] && v == c.Modules[y+1][x] && v == c.Modules[y+1][x+1] {
+					result += 3
+				}
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10) + total - 1) / total
+	result += (k - 1) * 10
+	if k == 0 {
+		result += 10
+	}
+	return result
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 != 0)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, v := range b {
+		if v {
+			out[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+	return out
+}
+
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMul(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func bit(x, i int) bool {
+	return (x>>i)&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
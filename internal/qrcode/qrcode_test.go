@@ -0,0 +1,158 @@
+package qrcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	zxing "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// capacity is how many bytes each version holds at level M, from ISO/IEC
+// 18004 table 7.
+var capacity = [...]int{1: 14, 2: 26, 3: 42, 4: 62, 5: 84, 6: 106, 7: 122, 8: 152, 9: 180, 10: 213}
+
+// versionInfo is the 18-bit version information of versions 7 and up,
+// from ISO/IEC 18004 annex D.
+var versionInfo = map[int]int{7: 0x07C94, 8: 0x085BC, 9: 0x09A99, 10: 0x0A4D3}
+
+// formatInfo is the masked 15-bit format information of level M for each
+// mask, from ISO/IEC 18004 annex C.
+var formatInfo = [8]int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+
+// payload is n bytes of a receipt-like string.
+func payload(n int) []byte {
+	return []byte(strings.Repeat("LOTTERY-RECEIPT:0123456789abcdef|", n/33+1)[:n])
+}
+
+// decode reads c back with an independent decoder.
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	bmp, err := gozxing.NewBinaryBitmapFromImage(c.Image(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := zxing.NewQRCodeReader().Decode(bmp, map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_PURE_BARCODE: true,
+	})
+	if err != nil {
+		t.Fatalf("version %d: %v", c.Version, err)
+	}
+	return res.GetText()
+}
+
+func TestEncodeDecodes(t *testing.T) {
+	for v := 1; v < len(capacity); v++ {
+		// The fullest and the emptiest payload of each version.
+		for _, n := range []int{capacity[v-1] + 1, capacity[v]} {
+			data := payload(n)
+			c, err := Encode(data)
+			if err != nil {
+				t.Fatalf("%d bytes: %v", n, err)
+			}
+			if c.Version != v || c.Size != 17+4*v {
+				t.Errorf("%d bytes: version %d size %d, want version %d", n, c.Version, c.Size, v)
+			}
+			if got := decode(t, c); got != string(data) {
+				t.Errorf("version %d, %d bytes: decoded %q", v, n, got)
+			}
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(payload(capacity[10] + 1)); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("Encode of %d bytes: err = %v, want ErrTooLong", capacity[10]+1, err)
+	}
+}
+
+// readBits reads n bits, least significant first, from the modules at the
+// positions at returns.
+func readBits(c *Code, n int, at func(i int) (x, y int)) int {
+	bits := 0
+	for i := 0; i < n; i++ {
+		if x, y := at(i); c.Modules[y][x] {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+func TestVersionInformation(t *testing.T) {
+	for v := 7; v <= 10; v++ {
+		c, err := Encode(payload(capacity[v]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Both copies: above the bottom-left finder and left of the
+		// top-right one.
+		bottomLeft := readBits(c, 18, func(i int) (int, int) { return i / 3, c.Size - 11 + i%3 })
+		topRight := readBits(c, 18, func(i int) (int, int) { return c.Size - 11 + i%3, i / 3 })
+		if bottomLeft != versionInfo[v] || topRight != versionInfo[v] {
+			t.Errorf("version %d: version information %05X and %05X, want %05X", v, bottomLeft, topRight, versionInfo[v])
+		}
+	}
+}
+
+func TestFormatInformation(t *testing.T) {
+	for v := 1; v <= 10; v++ {
+		c, err := Encode(payload(capacity[v]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The copy split around the top-left finder.
+		around := readBits(c, 15, func(i int) (int, int) {
+			switch {
+			case i <= 5:
+				return 8, i
+			case i <= 7:
+				return 8, i + 1
+			case i == 8:
+				return 7, 8
+			default:
+				return 14 - i, 8
+			}
+		})
+		// The copy split between the other two finders.
+		split := readBits(c, 15, func(i int) (int, int) {
+			if i < 8 {
+				return c.Size - 1 - i, 8
+			}
+			return 8, c.Size - 15 + i
+		})
+		if around != split {
+			t.Errorf("version %d: format information copies differ: %04X and %04X", v, around, split)
+		}
+		valid := false
+		for _, f := range formatInfo {
+			valid = valid || around == f
+		}
+		if !valid {
+			t.Errorf("version %d: format information %04X is not level M with any mask", v, around)
+		}
+		if !c.Modules[c.Size-8][8] {
+			t.Errorf("version %d: the dark module is light", v)
+		}
+	}
+}
+
+func TestGaloisField(t *testing.T) {
+	// The degree-10 generator of version 1-M, from ISO/IEC 18004 annex A,
+	// as exponents of alpha after the leading coefficient.
+	want := []int{251, 67, 46, 61, 118, 70, 64, 94, 32, 45}
+	got := rsDivisor(10)
+	for i, e := range want {
+		if p := pow(e); got[i] != p {
+			t.Fatalf("generator coefficient %d = %d, want alpha^%d = %d", i, got[i], e, p)
+		}
+	}
+}
+
+func pow(e int) byte {
+	x := byte(1)
+	for range e {
+		x = gfMul(x, 2)
+	}
+	return x
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// CancelDraw calls off a draw that has not been drawn yet. Every active
+// ticket in it is cancelled and its price refunded, all in one write each:
+// if the refunds cannot be made, the tickets and the draw are put back and
+// the draw is not cancelled.
+func (s *LotteryService) CancelDraw(actor, drawID string) (models.Draw, error) {
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+	s.drawMu.Lock()
+	defer s.drawMu.Unlock()
+
+	draw, err := s.draws.GetByID(drawID)
+	if err != nil {
+		return models.Draw{}, err
+	}
+
+	switch draw.Status {
+	case "completed":
+		return models.Draw{}, newError(ErrConflict, "draw already completed")
+	case "drawing":
+		return models.Draw{}, newError(ErrConflict, "draw is already in progress")
+	case "cancelled":
+		return models.Draw{}, newError(ErrConflict, "draw already cancelled")
+	}
+
+	status := draw.Status
+	draw.Status = "cancelled"
+	if err := s.draws.Update(draw); err != nil {
+		return models.Draw{}, err
+	}
+	log := slog.With("draw_id", draw.ID)
+	reopen := func() {
+		draw.Status = status
+		if err := s.draws.Update(draw); err != nil {
+			log.Error("draw not reopened after failed cancellation", "err", err)
+		}
+	}
+
+	var active, cancelled []models.Ticket
+	owed := map[string]int{}
+	now := time.Now()
+	for _, ticket := range s.tickets.GetByDrawID(draw.ID) {
+		if ticket.IsCancelled() {
+			continue
+		}
+		active = append(active, ticket)
+		if ticket.Price == 0 {
+			ticket.Price = legacyTicketPrice
+		}
+		ticket.Status = "cancelled"
+		ticket.CancelledAt = &now
+		cancelled = append(cancelled, ticket)
+		owed[ticket.UserID] += ticket.Price
+	}
+	if len(cancelled) > 0 {
+		if err := s.tickets.UpdateAll(cancelled); err != nil {
+			reopen()
+			return models.Draw{}, err
+		}
+		if err := s.users.AdjustBalances(owed); err != nil {
+			if revertErr := s.tickets.UpdateAll(active); revertErr != nil {
+				log.Error("cancelled tickets not restored after failed refund", "tickets", len(active), "err", revertErr)
+			}
+			reopen()
+			return models.Draw{}, err
+		}
+	}
+
+	s.record(actor, "draw.cancel", draw.ID, map[string]any{"status": status}, map[string]any{"status": draw.Status})
+	for _, ticket := range cancelled {
+		ticketsCancelled.Inc()
+		refunds.Add(float64(ticket.Price))
+	}
+	for userID, amount := range owed {
+		user, err := s.users.GetByID(userID)
+		if err != nil {
+			continue
+		}
+		s.record(actor, "balance.credit", userID,
+			map[string]any{"balance": user.Balance - amount},
+			map[string]any{"balance": user.Balance, "draw_id": draw.ID, "reason": "draw_cancelled"})
+		s.notifyBalance(user, amount, "draw_cancelled")
+	}
+
+	log.Info("draw cancelled", "tickets", len(cancelled), "users", len(owed))
+	s.events.Publish(EventDrawCancelled, draw.ID, map[string]interface{}{
+		"tickets_refunded": len(cancelled),
+	})
+	return draw, nil
+}
+
+// AdjustBalance adds delta, which may be negative, to a user's balance.
+// reason is required and kept in the audit log.
+func (s *LotteryService) AdjustBalance(actor, userID string, delta int, reason string) (models.User, error) {
+	reason = strings.TrimSpace(reason)
+	fields := map[string]string{}
+	if delta == 0 {
+		fields["delta"] = "must not be zero"
+	}
+	if reason == "" {
+		fields["reason"] = "is required"
+	}
+	if len(fields) > 0 {
+		return models.User{}, NewValidationError("Invalid balance adjustment", fields)
+	}
+
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
+	if err := s.users.AdjustBalances(map[string]int{userID: delta}); err != nil {
+		if errors.Is(err, storage.ErrNegativeBalance) {
+			return models.User{}, newError(ErrInsufficientBalance, "balance cannot go below zero")
+		}
+		return models.User{}, err
+	}
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.record(actor, "balance.adjust", userID,
+		map[string]any{"balance": user.Balance - delta},
+		map[string]any{"balance": user.Balance, "delta": delta, "reason": reason})
+	slog.Info("balance adjusted", "user_id", userID, "delta", delta, "actor", actor)
+	s.notifyBalance(user, delta, "adjustment")
+	return user, nil
+}
+
+// SetUserRole makes a user a player or an admin. An admin's session token
+// is accepted wherever the admin token is.
+func (s *LotteryService) SetUserRole(actor, userID, role string) (models.User, error) {
+	if !models.IsRole(role) {
+		return models.User{}, NewValidationError("Invalid role", map[string]string{
+			"role": "must be " + models.RolePlayer + " or " + models.RoleAdmin,
+		})
+	}
+
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	before := user.Role
+	if before == "" {
+		before = models.RolePlayer
+	}
+	if before != role {
+		user.Role = role
+		if err := s.users.Update(user); err != nil {
+			return models.User{}, err
+		}
+		s.record(actor, "user.role", userID, map[string]any{"role": before}, map[string]any{"role": role})
+		slog.Info("user role changed", "user_id", userID, "role", role, "actor", actor)
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func (s *LotteryService) QueryUsers(f models.UserFilter) (models.Page[models.User], error) {
+	page, err := s.users.Query(f)
+	for i := range page.Items {
+		page.Items[i].Password = ""
+	}
+	return page, queryError(err)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"crypto/subtle"
+	"log/slog"
+)
+
+// Actors recorded in the audit log. Users appear as "user:<id>"; callers
+// that could not be identified as "anonymous@<address>".
+const (
+	AdminActor  = "admin"
+	SystemActor = "system"
+)
+
+func UserActor(userID string) string {
+	return "user:" + userID
+}
+
+func AnonymousActor(addr string) string {
+	return "anonymous@" + addr
+}
+
+// SetAuditLog turns on the audit log. Without one nothing is recorded.
+func (s *LotteryService) SetAuditLog(l *storage.AuditLog) {
+	s.audit = l
+}
+
+// SetAdminToken sets the bearer token that identifies an administrator.
+// Empty means only admin users' sessions do.
+func (s *LotteryService) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
+// IsAdmin reports whether token is the admin token or the session token
+// of a user with the admin role.
+func (s *LotteryService) IsAdmin(token string) bool {
+	if token == "" {
+		return false
+	}
+	if s.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1 {
+		return true
+	}
+	user, err := s.Authenticate(token)
+	return err == nil && user.IsAdmin()
+}
+
+// ResolveActor names whoever presented token for the audit log: the user
+// of a valid session, the admin token, or an anonymous caller at addr.
+func (s *LotteryService) ResolveActor(token, addr string) string {
+	if token != "" {
+		if user, err := s.Authenticate(token); err == nil {
+			return UserActor(user.ID)
+		}
+		if s.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1 {
+			return AdminActor
+		}
+	}
+	return AnonymousActor(addr)
+}
+
+// record appends to the audit log. The action has already happened by the
+// time it is recorded, so a failure is logged rather than undoing it.
+func (s *LotteryService) record(actor, action, target string, before, after any) {
+	if s.audit == nil {
+		return
+	}
+	if _, err := s.audit.Append(actor, action, target, before, after); err != nil {
+		slog.Error("audit entry not written", "actor", actor, "action", action, "target", target, "err", err)
+	}
+}
+
+// recordAll appends records to the audit log with one write, the way
+// record appends one.
+func (s *LotteryService) recordAll(records []storage.AuditRecord) {
+	if s.audit == nil || len(records) == 0 {
+		return
+	}
+	if _, err := s.audit.AppendAll(records); err != nil {
+		slog.Error("audit entries not written", "entries", len(records), "first_action", records[0].Action, "err", err)
+	}
+}
+
+func (s *LotteryService) QueryAudit(f models.AuditFilter) (models.Page[models.AuditEntry], error) {
+	if s.audit == nil {
+		return models.Page[models.AuditEntry]{Items: []models.AuditEntry{}}, nil
+	}
+	page, err := s.audit.Query(f)
+	return page, queryError(err)
+}
+
+// VerifyAudit checks the audit log's hash chain.
+func (s *LotteryService) VerifyAudit() (models.AuditVerification, error) {
+	if s.audit == nil {
+		return models.AuditVerification{OK: true}, nil
+	}
+	return s.audit.Verify()
+}
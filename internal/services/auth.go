@@ -0,0 +1,73 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultSessionTTL = 24 * time.Hour
+
+var errInvalidToken = newError(ErrUnauthorized, "invalid or expired token")
+
+// SetAuthKey sets the HMAC key used to sign session tokens. Tokens signed
+// with a previous key stop working.
+func (s *LotteryService) SetAuthKey(key []byte) {
+	s.authKey = key
+}
+
+// IssueSession returns a signed, stateless token of the form
+// "<base64 user id|expiry>.<base64 signature>".
+func (s *LotteryService) IssueSession(userID string) models.Session {
+	expires := time.Now().Add(defaultSessionTTL)
+	claims := base64.RawURLEncoding.EncodeToString([]byte(userID + "|" + strconv.FormatInt(expires.Unix(), 10)))
+
+	return models.Session{
+		Token:     claims + "." + base64.RawURLEncoding.EncodeToString(s.tokenSignature(claims)),
+		UserID:    userID,
+		ExpiresAt: expires.Truncate(time.Second),
+	}
+}
+
+// Authenticate resolves a session token to its user.
+func (s *LotteryService) Authenticate(token string) (models.User, error) {
+	claims, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return models.User{}, errInvalidToken
+	}
+
+	want, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(want, s.tokenSignature(claims)) {
+		return models.User{}, errInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(claims)
+	if err != nil {
+		return models.User{}, errInvalidToken
+	}
+	userID, exp, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return models.User{}, errInvalidToken
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return models.User{}, errInvalidToken
+	}
+
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return models.User{}, errInvalidToken
+	}
+	return user, nil
+}
+
+func (s *LotteryService) tokenSignature(claims string) []byte {
+	mac := hmac.New(sha256.New, s.authKey)
+	mac.Write([]byte("session\x00"))
+	mac.Write([]byte(claims))
+	return mac.Sum(nil)
+}
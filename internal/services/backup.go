@@ -0,0 +1,50 @@
+package services
+
+import (
+	"LotterySystem/internal/backup"
+	"LotterySystem/internal/storage"
+	"log/slog"
+)
+
+// Snapshot copies the users, draws, tickets and prizes files, and the
+// settlements and audit log when there are some, as they stand between two
+// changes. It waits for a draw being settled to finish, and holds up new
+// changes while it copies.
+func (s *LotteryService) Snapshot() ([]storage.SnapshotFile, error) {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+
+	repos := []storage.Snapshotter{s.users, s.draws, s.tickets, s.prizes}
+	if s.settlements != nil {
+		repos = append(repos, s.settlements)
+	}
+	if s.audit != nil {
+		repos = append(repos, s.audit)
+	}
+	return storage.Snapshot(repos...)
+}
+
+// CreateBackup writes a snapshot to a new archive in dir and then deletes
+// the archives r no longer keeps, which it returns. A failure to prune is
+// only logged: the new backup is there either way.
+func (s *LotteryService) CreateBackup(actor, dir string, r backup.Retention) (backup.Info, []backup.Info, error) {
+	files, err := s.Snapshot()
+	if err != nil {
+		return backup.Info{}, nil, err
+	}
+	info, err := backup.Create(dir, files)
+	if err != nil {
+		return backup.Info{}, nil, err
+	}
+	s.record(actor, "backup.create", info.Name, nil, map[string]any{"size": info.Size})
+	slog.Info("backup created", "archive", info.Path, "size", info.Size, "actor", actor)
+
+	pruned, err := backup.Prune(dir, r)
+	if err != nil {
+		slog.Error("old backups not pruned", "dir", dir, "err", err)
+	}
+	for _, p := range pruned {
+		slog.Info("backup pruned", "archive", p.Path)
+	}
+	return info, pruned, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"LotterySystem/internal/storage"
+	"errors"
+)
+
+var (
+	ErrNotFound            = storage.ErrNotFound
+	ErrAlreadyExists       = storage.ErrAlreadyExists
+	ErrConflict            = errors.New("conflict")
+	ErrValidation          = errors.New("validation failed")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrDrawClosed          = errors.New("draw closed")
+	ErrUnauthorized        = errors.New("unauthorized")
+)
+
+// Error carries a user-facing message and unwraps to one of the sentinel
+// errors above, so callers can branch on it with errors.Is.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// NewValidationError reports invalid input. fields maps each offending field
+// to what is wrong with it and may be nil.
+func NewValidationError(message string, fields map[string]string) error {
+	return &Error{Kind: ErrValidation, Message: message, Fields: fields}
+}
+
+// queryError turns storage paging errors into validation errors.
+func queryError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, storage.ErrInvalidCursor):
+		return NewValidationError(err.Error(), map[string]string{"cursor": "is invalid"})
+	case errors.Is(err, storage.ErrInvalidSort):
+		return NewValidationError(err.Error(), map[string]string{"sort": "is not a sortable field"})
+	}
+	return err
+}
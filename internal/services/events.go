@@ -0,0 +1,146 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventDrawOpened     EventType = "draw.opened"
+	EventSalesClosed    EventType = "draw.sales_closed"
+	EventNumberDrawn    EventType = "draw.number_drawn"
+	EventDrawSettled    EventType = "draw.settled"
+	EventSettleProgress EventType = "draw.settle_progress"
+	EventDrawCancelled  EventType = "draw.cancelled"
+	EventPrizeAwarded   EventType = "prize.awarded"
+)
+
+type Event struct {
+	ID     uint64      `json:"id"`
+	Type   EventType   `json:"type"`
+	DrawID string      `json:"draw_id,omitempty"`
+	Time   time.Time   `json:"time"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// EventBus fans events out to in-process subscribers. Publishing never
+// blocks: a channel subscriber whose buffer is full misses the event, which
+// lottery_events_dropped_total counts, while a queue subscriber keeps every
+// event until it takes them.
+type EventBus struct {
+	mu     sync.Mutex
+	seq    uint64
+	nextID int
+	subs   map[int]chan Event
+	queues map[int]*EventQueue
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int]chan Event), queues: make(map[int]*EventQueue)}
+}
+
+func (b *EventBus) Publish(t EventType, drawID string, data interface{}) Event {
+	return b.PublishAll(t, drawID, []interface{}{data})[0]
+}
+
+// PublishAll publishes an event of type t for each of data at once, so a
+// queue subscriber takes them together.
+func (b *EventBus) PublishAll(t EventType, drawID string, data []interface{}) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	events := make([]Event, len(data))
+	for i, d := range data {
+		b.seq++
+		events[i] = Event{ID: b.seq, Type: t, DrawID: drawID, Time: now, Data: d}
+	}
+	for _, ch := range b.subs {
+		for _, e := range events {
+			select {
+			case ch <- e:
+			default:
+				eventsDropped.Inc()
+			}
+		}
+	}
+	for _, q := range b.queues {
+		q.push(events)
+	}
+	return events
+}
+
+// Subscribe returns a channel of future events and a function that ends the
+// subscription and closes the channel.
+func (b *EventBus) Subscribe(buffer int) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, buffer)
+	b.subs[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs, id)
+			close(ch)
+		})
+	}
+}
+
+// SubscribeQueue returns a queue of future events that misses none of
+// them, and a function that ends the subscription.
+func (b *EventBus) SubscribeQueue() (*EventQueue, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	q := &EventQueue{ready: make(chan struct{}, 1)}
+	b.queues[id] = q
+
+	return q, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.queues, id)
+	}
+}
+
+// EventQueue holds the events published since they were last taken. It
+// grows as long as its subscriber does not keep up, so it is for
+// subscribers that must see every event and take them in batches.
+type EventQueue struct {
+	mu     sync.Mutex
+	events []Event
+	ready  chan struct{}
+}
+
+func (q *EventQueue) push(events []Event) {
+	q.mu.Lock()
+	q.events = append(q.events, events...)
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready receives when events are waiting to be taken.
+func (q *EventQueue) Ready() <-chan struct{} {
+	return q.ready
+}
+
+// Take returns the events waiting, oldest first, and empties the queue.
+func (q *EventQueue) Take() []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events
+	q.events = nil
+	return events
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The Export methods call fn once per record, oldest first, and stop at the
+// first error fn returns. They look records up one at a time so an export
+// never copies a whole table; a record removed mid-export is skipped.
+
+func (s *LotteryService) ExportTickets(f models.ExportFilter, fn func(models.Ticket) error) error {
+	for _, id := range s.tickets.Scan(f) {
+		ticket, err := s.tickets.GetByID(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if ticket.Status == "" {
+			ticket.Status = "active"
+		}
+		if err := fn(ticket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LotteryService) ExportPrizes(f models.ExportFilter, fn func(models.Prize) error) error {
+	for _, id := range s.prizes.Scan(f) {
+		prize, err := s.prizes.GetByID(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := fn(prize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LotteryService) ExportDraws(f models.ExportFilter, fn func(models.Draw) error) error {
+	for _, id := range s.draws.Scan(f) {
+		draw, err := s.draws.GetByID(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := fn(draw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ledgerRef is a movement found by the scan, looked up again when it is
+// written. Adjustments come from the audit log, so their entry is built
+// up front.
+type ledgerRef struct {
+	at    time.Time
+	kind  string
+	id    string
+	entry *models.LedgerEntry
+}
+
+// ExportLedger reconstructs balance movements from tickets, prizes and the
+// audit log: a debit for every purchase, a credit for every refund, a
+// credit for every money prize once it is credited, and every balance
+// adjustment an admin made. From and To bound the time of the movement
+// itself. Adjustments belong to no draw, so a DrawID leaves them out.
+func (s *LotteryService) ExportLedger(f models.ExportFilter, fn func(models.LedgerEntry) error) error {
+	var refs []ledgerRef
+
+	// A refund or a credit can fall inside the range even when the
+	// purchase or the award does not, so tickets and prizes are scanned by
+	// draw only and the times checked here.
+	for _, id := range s.tickets.Scan(models.ExportFilter{DrawID: f.DrawID}) {
+		ticket, err := s.tickets.GetByID(id)
+		if err != nil {
+			continue
+		}
+		if inExportRange(ticket.CreatedAt, f) {
+			refs = append(refs, ledgerRef{at: ticket.CreatedAt, kind: "ticket_purchase", id: id})
+		}
+		if ticket.IsCancelled() && ticket.CancelledAt != nil && inExportRange(*ticket.CancelledAt, f) {
+			refs = append(refs, ledgerRef{at: *ticket.CancelledAt, kind: "ticket_refund", id: id})
+		}
+	}
+	for _, id := range s.prizes.Scan(models.ExportFilter{DrawID: f.DrawID}) {
+		prize, err := s.prizes.GetByID(id)
+		if err != nil || prize.Type != models.Money || prize.Value == 0 || prize.CreditedAt == nil {
+			continue
+		}
+		if inExportRange(*prize.CreditedAt, f) {
+			refs = append(refs, ledgerRef{at: *prize.CreditedAt, kind: "prize", id: id})
+		}
+	}
+	if f.DrawID == "" {
+		adjustments, err := s.ledgerAdjustments(f)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, adjustments...)
+	}
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].at.Before(refs[j].at)
+	})
+
+	for _, ref := range refs {
+		entry, err := s.ledgerEntry(ref)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ledgerAdjustments reads the balance adjustments in range from the audit
+// log, which is the only record of them.
+func (s *LotteryService) ledgerAdjustments(f models.ExportFilter) ([]ledgerRef, error) {
+	if s.audit == nil {
+		return nil, nil
+	}
+	page, err := s.audit.Query(models.AuditFilter{Action: "balance.adjust"})
+	if err != nil {
+		return nil, err
+	}
+	var refs []ledgerRef
+	for _, e := range page.Items {
+		if !inExportRange(e.Time, f) {
+			continue
+		}
+		var after struct {
+			Delta  int    `json:"delta"`
+			Reason string `json:"reason"`
+		}
+		if err := json.Unmarshal(e.After, &after); err != nil {
+			return nil, fmt.Errorf("audit entry %d: %w", e.Seq, err)
+		}
+		refs = append(refs, ledgerRef{at: e.Time, kind: "adjustment", entry: &models.LedgerEntry{
+			Time:   e.Time,
+			UserID: e.Target,
+			Kind:   "adjustment",
+			Amount: after.Delta,
+			Reason: after.Reason,
+		}})
+	}
+	return refs, nil
+}
+
+func (s *LotteryService) ledgerEntry(ref ledgerRef) (models.LedgerEntry, error) {
+	if ref.entry != nil {
+		return *ref.entry, nil
+	}
+	if ref.kind == "prize" {
+		prize, err := s.prizes.GetByID(ref.id)
+		if err != nil {
+			return models.LedgerEntry{}, err
+		}
+		return models.LedgerEntry{
+			Time:     ref.at,
+			UserID:   prize.UserID,
+			Kind:     ref.kind,
+			Amount:   prize.Value,
+			TicketID: prize.TicketID,
+			DrawID:   prize.DrawID,
+			PrizeID:  prize.ID,
+		}, nil
+	}
+
+	ticket, err := s.tickets.GetByID(ref.id)
+	if err != nil {
+		return models.LedgerEntry{}, err
+	}
+	price := ticket.Price
+	if price == 0 {
+		price = legacyTicketPrice
+	}
+	if ref.kind == "ticket_purchase" {
+		price = -price
+	}
+	return models.LedgerEntry{
+		Time:     ref.at,
+		UserID:   ticket.UserID,
+		Kind:     ref.kind,
+		Amount:   price,
+		TicketID: ticket.ID,
+		DrawID:   ticket.DrawID,
+	}, nil
+}
+
+func inExportRange(t time.Time, f models.ExportFilter) bool {
+	if !f.From.IsZero() && t.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && t.After(f.To) {
+		return false
+	}
+	return true
+}
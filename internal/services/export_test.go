@@ -0,0 +1,75 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/utils"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportLedger(t *testing.T) {
+	s := newTestService(t)
+	s.SetTicketCost(10)
+	user, err := s.RegisterUser("alice", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draw, err := s.CreateDraw(SystemActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := s.CreateTicket(user.ID, draw.ID, utils.GenerateWinningNumbers()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AdjustBalance(AdminActor, user.ID, 50, "goodwill"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A prize awarded long ago but credited now, and one never credited.
+	awarded := start.Add(-48 * time.Hour)
+	credited := time.Now()
+	for _, p := range []models.Prize{
+		{ID: "prz_credited", TicketID: "tkt_a", UserID: user.ID, DrawID: draw.ID, Type: models.Money, Value: 500, CreatedAt: awarded, CreditedAt: &credited},
+		{ID: "prz_owed", TicketID: "tkt_b", UserID: user.ID, DrawID: draw.ID, Type: models.Money, Value: 700, CreatedAt: awarded},
+	} {
+		if err := s.prizes.Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ledger := func(f models.ExportFilter) (kinds []string, amounts []int) {
+		t.Helper()
+		err := s.ExportLedger(f, func(e models.LedgerEntry) error {
+			kinds = append(kinds, e.Kind)
+			amounts = append(amounts, e.Amount)
+			switch {
+			case e.Kind == "adjustment" && (e.UserID != "usr_000001" || e.Reason != "goodwill"):
+				t.Errorf("adjustment entry = %+v", e)
+			case e.Kind == "ticket_purchase" && (e.TicketID != "tkt_000001" || e.DrawID != "drw_000001"):
+				t.Errorf("purchase entry = %+v", e)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return kinds, amounts
+	}
+
+	kinds, amounts := ledger(models.ExportFilter{From: start.Add(-time.Second)})
+	if want := []string{"ticket_purchase", "adjustment", "prize"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("kinds = %v, want %v", kinds, want)
+	}
+	if want := []int{-10, 50, 500}; !reflect.DeepEqual(amounts, want) {
+		t.Errorf("amounts = %v, want %v", amounts, want)
+	}
+
+	if kinds, _ := ledger(models.ExportFilter{DrawID: draw.ID}); !reflect.DeepEqual(kinds, []string{"ticket_purchase", "prize"}) {
+		t.Errorf("kinds for one draw = %v, want no adjustments", kinds)
+	}
+	if kinds, _ := ledger(models.ExportFilter{To: start}); len(kinds) != 0 {
+		t.Errorf("kinds before anything was credited = %v, want none", kinds)
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"LotterySystem/internal/utils"
+	"errors"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ImportUsers validates rows and, when commit is set and every row is valid,
+// creates all of the users at once. Rows rejected while parsing are passed
+// in as parseErrs so they appear in the report and block the commit. actor
+// is who ran the import, for the audit log.
+func (s *LotteryService) ImportUsers(actor string, rows []models.UserImportRow, parseErrs []models.ImportRowError, commit bool) (models.ImportReport, error) {
+	report := newImportReport("users", len(rows)+len(parseErrs), parseErrs, commit)
+
+	seen := map[string]int{}
+	users := make([]models.User, 0, len(rows))
+	// imported marks the users whose balance came from the file.
+	imported := make([]bool, 0, len(rows))
+	now := time.Now()
+	for _, row := range rows {
+		rowErrs := len(report.Errors)
+		if row.Username == "" {
+			report.AddError(row.Line, "username", "is required")
+		} else if line, dup := seen[row.Username]; dup {
+			report.AddError(row.Line, "username", "duplicates line "+strconv.Itoa(line))
+		} else {
+			seen[row.Username] = row.Line
+			if _, err := s.users.GetByUsername(row.Username); err == nil {
+				report.AddError(row.Line, "username", "already exists")
+			}
+		}
+		if row.Password == "" {
+			report.AddError(row.Line, "password", "is required")
+		}
+		balance := s.startBalance
+		if row.Balance != nil {
+			balance = *row.Balance
+			if balance < 0 {
+				report.AddError(row.Line, "balance", "must not be negative")
+			} else if balance > s.startBalance {
+				report.AddError(row.Line, "balance", "must not exceed the starting balance of "+strconv.Itoa(s.startBalance))
+			}
+		}
+		if len(report.Errors) > rowErrs {
+			continue
+		}
+
+		report.Valid++
+		users = append(users, models.User{
+			ID:        s.generateID(utils.UserIDPrefix),
+			Username:  row.Username,
+			Password:  row.Password,
+			Balance:   balance,
+			CreatedAt: now,
+		})
+		imported = append(imported, row.Balance != nil)
+	}
+
+	sortImportErrors(&report)
+	if report.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
+	if err := s.users.SaveAll(users); err != nil {
+		if errors.Is(err, ErrAlreadyExists) {
+			return report, newError(ErrConflict, "a username was taken while importing, nothing was imported")
+		}
+		return report, err
+	}
+
+	report.Committed = true
+	slog.Info("users imported", "count", len(users))
+	for i, u := range users {
+		report.Created = append(report.Created, u.ID)
+		s.record(actor, "user.register", u.ID, nil, map[string]any{
+			"username": u.Username,
+			"balance":  u.Balance,
+			"import":   true,
+		})
+		if imported[i] {
+			s.record(actor, "balance.import", u.ID,
+				map[string]any{"balance": s.startBalance},
+				map[string]any{"balance": u.Balance, "reason": "import"})
+		}
+	}
+	return report, nil
+}
+
+// ImportTickets validates rows and, when commit is set and every row is
+// valid, buys all of the tickets at once, charging each user the usual
+// ticket price. Draws stay open for the whole commit, and either every
+// ticket is stored and paid for or nothing changes. actor is who ran the
+// import, for the audit log.
+func (s *LotteryService) ImportTickets(actor string, rows []models.TicketImportRow, parseErrs []models.ImportRowError, commit bool) (models.ImportReport, error) {
+	report := newImportReport("tickets", len(rows)+len(parseErrs), parseErrs, commit)
+
+	pending, pendingErr := s.draws.GetPending()
+	draws := map[string]models.Draw{}
+	charges := map[string]int{}
+	tickets := make([]models.Ticket, 0, len(rows))
+	now := time.Now()
+
+	for _, row := range rows {
+		rowErrs := len(report.Errors)
+
+		if !utils.ValidateNumbers(row.Numbers) {
+			report.AddError(row.Line, "numbers", "must be 6 unique numbers between 1 and 49")
+		}
+
+		drawID := row.DrawID
+		if drawID == "" {
+			if pendingErr != nil {
+				report.AddError(row.Line, "draw_id", "is required when no draw is open")
+			}
+			drawID = pending.ID
+		}
+		if drawID != "" {
+			draw, ok := draws[drawID]
+			if !ok {
+				draw, _ = s.draws.GetByID(drawID)
+				draws[drawID] = draw
+			}
+			if draw.ID == "" {
+				report.AddError(row.Line, "draw_id", "draw not found")
+			} else if draw.Status != "pending" {
+				report.AddError(row.Line, "draw_id", "draw is not accepting tickets")
+			}
+		}
+
+		user, err := s.importUser(row)
+		if err != nil {
+			field := "user_id"
+			if row.UserID == "" {
+				field = "username"
+			}
+			report.AddError(row.Line, field, err.Error())
+		} else if user.Balance < charges[user.ID]+s.ticketCost {
+			report.AddError(row.Line, "", "insufficient balance for "+user.Username)
+		}
+
+		if len(report.Errors) > rowErrs {
+			continue
+		}
+
+		report.Valid++
+		charges[user.ID] += s.ticketCost
+		tickets = append(tickets, models.Ticket{
+			ID:        s.generateID(utils.TicketIDPrefix),
+			UserID:    user.ID,
+			DrawID:    drawID,
+			Numbers:   row.Numbers,
+			Serial:    utils.GenerateSerial(),
+			Price:     s.ticketCost,
+			Status:    "active",
+			CreatedAt: now,
+		})
+	}
+
+	sortImportErrors(&report)
+	if report.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
+	if err := s.commitTickets(tickets, charges); err != nil {
+		return report, err
+	}
+
+	report.Committed = true
+	slog.Info("tickets imported", "count", len(tickets), "users", len(charges))
+	s.record(actor, "import.tickets", "", nil, map[string]any{"tickets": len(tickets), "users": len(charges)})
+	for _, t := range tickets {
+		ticketsSold.Inc()
+		revenue.Add(float64(t.Price))
+		report.Created = append(report.Created, t.ID)
+		s.notifier.Notify(t.UserID, UserEventTicketConfirmed, t)
+	}
+	for userID, charge := range charges {
+		if user, err := s.users.GetByID(userID); err == nil {
+			s.record(actor, "balance.debit", userID,
+				map[string]any{"balance": user.Balance + charge},
+				map[string]any{"balance": user.Balance, "charge": charge, "reason": "ticket_import"})
+			s.notifyBalance(user, -charge, "ticket_purchase")
+		}
+	}
+	return report, nil
+}
+
+// commitTickets holds drawMu so no draw can close between the status check
+// and the tickets being stored. Balances are charged first and refunded if
+// the tickets cannot be saved.
+func (s *LotteryService) commitTickets(tickets []models.Ticket, charges map[string]int) error {
+	s.drawMu.Lock()
+	defer s.drawMu.Unlock()
+
+	checked := map[string]bool{}
+	for _, t := range tickets {
+		if checked[t.DrawID] {
+			continue
+		}
+		draw, err := s.draws.GetByID(t.DrawID)
+		if err != nil {
+			return err
+		}
+		if draw.Status != "pending" {
+			return newError(ErrDrawClosed, "draw "+draw.ID+" closed while importing, nothing was imported")
+		}
+		checked[t.DrawID] = true
+	}
+
+	debits := make(map[string]int, len(charges))
+	for userID, charge := range charges {
+		debits[userID] = -charge
+	}
+	if err := s.users.AdjustBalances(debits); err != nil {
+		if errors.Is(err, storage.ErrNegativeBalance) {
+			return newError(ErrInsufficientBalance, "a balance changed while importing, nothing was imported")
+		}
+		return err
+	}
+
+	if err := s.tickets.SaveAll(tickets); err != nil {
+		if rerr := s.users.AdjustBalances(charges); rerr != nil {
+			slog.Error("import: charges not refunded after failed save", "users", len(charges), "err", rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *LotteryService) importUser(row models.TicketImportRow) (models.User, error) {
+	switch {
+	case row.UserID != "":
+		user, err := s.users.GetByID(row.UserID)
+		if err != nil {
+			return models.User{}, errors.New("user not found")
+		}
+		return user, nil
+	case row.Username != "":
+		user, err := s.users.GetByUsername(row.Username)
+		if err != nil {
+			return models.User{}, errors.New("user not found")
+		}
+		return user, nil
+	}
+	return models.User{}, errors.New("user_id or username is required")
+}
+
+func sortImportErrors(report *models.ImportReport) {
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Line < report.Errors[j].Line
+	})
+}
+
+func newImportReport(dataset string, rows int, parseErrs []models.ImportRowError, commit bool) models.ImportReport {
+	return models.ImportReport{
+		Dataset: dataset,
+		DryRun:  !commit,
+		Rows:    rows,
+		Errors:  append([]models.ImportRowError{}, parseErrs...),
+	}
+}
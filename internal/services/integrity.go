@@ -0,0 +1,140 @@
+package services
+
+import (
+	"LotterySystem/internal/integrity"
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/utils"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// CheckIntegrity cross-checks every user, draw, ticket and prize as they
+// stand between two changes.
+func (s *LotteryService) CheckIntegrity() models.IntegrityReport {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+	return s.checkIntegrity()
+}
+
+func (s *LotteryService) checkIntegrity() models.IntegrityReport {
+	d := integrity.Data{
+		Users:   byID(s.users.List(), func(u models.User) string { return u.ID }),
+		Draws:   byID(s.draws.List(), func(d models.Draw) string { return d.ID }),
+		Tickets: byID(s.tickets.List(), func(t models.Ticket) string { return t.ID }),
+		Prizes:  byID(s.prizes.List(), func(p models.Prize) string { return p.ID }),
+	}
+	report := models.IntegrityReport{
+		CheckedAt: time.Now(),
+		Records: map[string]int{
+			"users":   len(d.Users),
+			"draws":   len(d.Draws),
+			"tickets": len(d.Tickets),
+			"prizes":  len(d.Prizes),
+		},
+	}
+	if s.settlements != nil {
+		d.Settlements = byID(s.settlements.List(), func(st models.Settlement) string { return st.DrawID })
+		report.Records["settlements"] = len(d.Settlements)
+	}
+	report.Issues = integrity.Check(d)
+	return report
+}
+
+func byID[T any](items []T, id func(T) string) map[string]T {
+	m := make(map[string]T, len(items))
+	for _, it := range items {
+		m[id(it)] = it
+	}
+	return m
+}
+
+// RepairIntegrity checks the records and, unless dryRun is set, applies
+// the fix of every issue that has one. Nothing changes in between, so the
+// fixes apply to exactly what was checked. Each repair is recorded in the
+// audit log as actor's; issues without a fix are left for a person.
+func (s *LotteryService) RepairIntegrity(actor string, dryRun bool) (models.IntegrityReport, error) {
+	s.changeMu.Lock()
+	defer s.changeMu.Unlock()
+
+	report := s.checkIntegrity()
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+	for i, issue := range report.Issues {
+		if issue.Fix == "" {
+			continue
+		}
+		if err := s.repair(actor, issue); err != nil {
+			return report, fmt.Errorf("repairing %s %s: %w", issue.Kind, issue.Record, err)
+		}
+		report.Issues[i].Repaired = true
+		report.Repaired++
+	}
+	if report.Repaired > 0 {
+		slog.Info("integrity issues repaired", "repaired", report.Repaired, "issues", len(report.Issues), "actor", actor)
+	}
+	return report, nil
+}
+
+func (s *LotteryService) repair(actor string, issue models.IntegrityIssue) error {
+	switch issue.Kind {
+	case models.IssueTicketPrizeLink:
+		ticket, err := s.tickets.GetByID(issue.Record)
+		if err != nil {
+			return err
+		}
+		before := ticket.PrizeID
+		ticket.PrizeID = ""
+		if prize, err := s.prizes.GetByTicketID(ticket.ID); err == nil {
+			ticket.PrizeID = prize.ID
+		}
+		if err := s.tickets.Update(ticket); err != nil {
+			return err
+		}
+		s.record(actor, "integrity.repair", ticket.ID,
+			map[string]any{"prize_id": before},
+			map[string]any{"prize_id": ticket.PrizeID, "issue": issue.Kind})
+
+	case models.IssuePrizeDrawMismatch:
+		prize, err := s.prizes.GetByID(issue.Record)
+		if err != nil {
+			return err
+		}
+		ticket, err := s.tickets.GetByID(prize.TicketID)
+		if err != nil {
+			return err
+		}
+		before := prize.DrawID
+		prize.DrawID = ticket.DrawID
+		if err := s.prizes.Update(prize); err != nil {
+			return err
+		}
+		s.record(actor, "integrity.repair", prize.ID,
+			map[string]any{"draw_id": before},
+			map[string]any{"draw_id": prize.DrawID, "issue": issue.Kind})
+
+	case models.IssueTicketUnsettled:
+		ticket, err := s.tickets.GetByID(issue.Record)
+		if err != nil {
+			return err
+		}
+		draw, err := s.draws.GetByID(ticket.DrawID)
+		if err != nil {
+			return err
+		}
+		before := ticket.Matches
+		ticket.Matches = utils.CountMatches(ticket.Numbers, draw.WinningNumbers)
+		if err := s.tickets.Update(ticket); err != nil {
+			return err
+		}
+		s.record(actor, "integrity.repair", ticket.ID,
+			map[string]any{"matches": before},
+			map[string]any{"matches": ticket.Matches, "issue": issue.Kind})
+
+	default:
+		return fmt.Errorf("no repair for %s", issue.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"testing"
+	"time"
+)
+
+func TestRepairIntegrity(t *testing.T) {
+	s := newTestService(t)
+	user, err := s.RegisterUser("dave", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	draw := models.Draw{ID: "drw_1", Status: "completed", WinningNumbers: []int{1, 2, 3, 4, 5, 6}, DrawDate: now, CreatedAt: now}
+	if err := s.draws.Save(draw); err != nil {
+		t.Fatal(err)
+	}
+	// A losing ticket with its matches miscounted, one pointing at a prize
+	// that does not exist, and a money prize never credited.
+	for _, tk := range []models.Ticket{
+		{ID: "tkt_miscounted", UserID: user.ID, DrawID: draw.ID, Numbers: []int{20, 21, 22, 23, 24, 25}, Matches: 1, Status: "active"},
+		{ID: "tkt_dangling", UserID: user.ID, DrawID: draw.ID, Numbers: []int{30, 31, 32, 33, 34, 35}, PrizeID: "prz_ghost", Status: "active"},
+		{ID: "tkt_winner", UserID: user.ID, DrawID: draw.ID, Numbers: []int{1, 2, 3, 10, 11, 12}, Matches: 3, PrizeID: "prz_1", Status: "active"},
+	} {
+		if err := s.tickets.Save(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.prizes.Save(models.Prize{ID: "prz_1", TicketID: "tkt_winner", UserID: user.ID, DrawID: draw.ID, Type: models.Money, Value: 500, MatchesCount: 3, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	repairs := func() int {
+		page, err := s.QueryAudit(models.AuditFilter{Action: "integrity.repair"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(page.Items)
+	}
+
+	dry, err := s.RepairIntegrity("admin", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dry.DryRun || dry.Repaired != 0 || len(dry.Issues) != 3 {
+		t.Fatalf("dry run = %d issues, %d repaired, dry_run %v; want 3, 0, true", len(dry.Issues), dry.Repaired, dry.DryRun)
+	}
+	for _, issue := range dry.Issues {
+		if issue.Repaired {
+			t.Errorf("dry run marked %s %s repaired", issue.Kind, issue.Record)
+		}
+	}
+	if tk, _ := s.tickets.GetByID("tkt_miscounted"); tk.Matches != 1 {
+		t.Error("a dry run changed a ticket")
+	}
+	if n := repairs(); n != 0 {
+		t.Errorf("a dry run recorded %d repairs", n)
+	}
+
+	report, err := s.RepairIntegrity("admin", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.DryRun || report.Repaired != 2 {
+		t.Fatalf("repair = %d repaired, dry_run %v; want 2, false", report.Repaired, report.DryRun)
+	}
+	for _, issue := range report.Issues {
+		if want := issue.Kind != models.IssuePrizeUncredited; issue.Repaired != want {
+			t.Errorf("%s %s repaired = %v, want %v", issue.Kind, issue.Record, issue.Repaired, want)
+		}
+	}
+	if tk, _ := s.tickets.GetByID("tkt_miscounted"); tk.Matches != 0 {
+		t.Errorf("matches after the repair = %d, want 0", tk.Matches)
+	}
+	if tk, _ := s.tickets.GetByID("tkt_dangling"); tk.PrizeID != "" {
+		t.Errorf("prize_id after the repair = %q, want none", tk.PrizeID)
+	}
+	if n := repairs(); n != 2 {
+		t.Errorf("%d repairs recorded, want 2", n)
+	}
+	if got, _ := s.GetUser(user.ID); got.Balance != user.Balance {
+		t.Errorf("balance = %d after the repair, want %d: an uncredited prize is left to a person", got.Balance, user.Balance)
+	}
+
+	left := s.CheckIntegrity().Issues
+	if len(left) != 1 || left[0].Kind != models.IssuePrizeUncredited {
+		t.Errorf("issues after the repair = %v, want only the uncredited prize", left)
+	}
+}
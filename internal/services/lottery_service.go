@@ -1,21 +1,55 @@
 package services
 
 import (
+	"LotterySystem/internal/config"
 	"LotterySystem/internal/models"
 	"LotterySystem/internal/storage"
 	"LotterySystem/internal/utils"
+	"crypto/rand"
 	"errors"
-	"fmt"
-	"math/rand"
+	"log/slog"
+	"sync"
 	"time"
 )
 
+const (
+	defaultTicketCost      = 100
+	defaultStartingBalance = 10000
+	defaultCancelWindow    = 30 * time.Minute
+
+	legacyTicketPrice = models.LegacyTicketPrice
+)
+
 type LotteryService struct {
-	users   *storage.UserRepository
-	draws   *storage.DrawRepository
-	tickets *storage.TicketRepository
-	prizes  *storage.PrizeRepository
-	rng     *rand.Rand
+	users        *storage.UserRepository
+	draws        *storage.DrawRepository
+	tickets      *storage.TicketRepository
+	prizes       *storage.PrizeRepository
+	cancelWindow time.Duration
+	ticketCost   int
+	startBalance int
+	receiptKey   []byte
+	authKey      []byte
+	ids          utils.IDGenerator
+	events       *EventBus
+	notifier     *Notifier
+	audit        *storage.AuditLog
+	adminToken   string
+
+	// drawMu serialises draw state transitions; liveDrawDelay is the pause
+	// before each winning number is revealed.
+	drawMu        sync.Mutex
+	liveDrawDelay time.Duration
+
+	// changeMu is held shared by every change that writes more than one
+	// file, the audit log included, and exclusively by Snapshot, so a
+	// snapshot never holds half of a change.
+	changeMu sync.RWMutex
+
+	// settleMu is held while a draw is settled, after changeMu, so a
+	// resettle never runs alongside another settlement.
+	settleMu    sync.Mutex
+	settlements *storage.SettlementRepository
 }
 
 func NewLotteryService(
@@ -25,30 +59,153 @@ func NewLotteryService(
 	prizes *storage.PrizeRepository,
 ) *LotteryService {
 	return &LotteryService{
-		users:   users,
-		draws:   draws,
-		tickets: tickets,
-		prizes:  prizes,
-		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		users:        users,
+		draws:        draws,
+		tickets:      tickets,
+		prizes:       prizes,
+		cancelWindow: defaultCancelWindow,
+		ticketCost:   defaultTicketCost,
+		startBalance: defaultStartingBalance,
+		receiptKey:   randomKey(),
+		authKey:      randomKey(),
+		ids:          utils.NewULIDGenerator(),
+		events:       NewEventBus(),
+		notifier:     NewNotifier(defaultReplaySize),
+	}
+}
+
+// Open opens the data files in cfg.DataDir and applies the service
+// settings in cfg. The server and every command that works on the data
+// directory use it, so they all see the data the same way. It fails if a
+// data file needs migrating first.
+func Open(cfg config.Config) (*LotteryService, error) {
+	if err := storage.CheckSchema(cfg.DataDir); err != nil {
+		return nil, err
+	}
+	s := NewLotteryService(
+		storage.NewUserRepository(cfg.DataDir),
+		storage.NewDrawRepository(cfg.DataDir),
+		storage.NewTicketRepository(cfg.DataDir),
+		storage.NewPrizeRepository(cfg.DataDir),
+	)
+	s.SetTicketCost(cfg.TicketCost)
+	s.SetStartingBalance(cfg.StartingBalance)
+	s.SetCancelWindow(time.Duration(cfg.CancelWindow))
+	s.SetLiveDrawDelay(time.Duration(cfg.LiveDrawDelay))
+	if cfg.ReceiptKey != "" {
+		s.SetReceiptKey([]byte(cfg.ReceiptKey))
+	}
+	if cfg.AuthKey != "" {
+		s.SetAuthKey([]byte(cfg.AuthKey))
+	}
+	s.SetAdminToken(cfg.AdminToken)
+	s.SetAuditLog(storage.NewAuditLog(cfg.DataDir))
+	s.SetSettlements(storage.NewSettlementRepository(cfg.DataDir))
+	return s, nil
+}
+
+func (s *LotteryService) Notifications() *Notifier {
+	return s.notifier
+}
+
+func (s *LotteryService) Events() *EventBus {
+	return s.events
+}
+
+// Flush waits for any draw being settled and rewrites every data file. It
+// is called on shutdown, after the servers have stopped taking requests.
+func (s *LotteryService) Flush() error {
+	s.drawMu.Lock()
+	defer s.drawMu.Unlock()
+	s.settleMu.Lock()
+	defer s.settleMu.Unlock()
+
+	errs := []error{
+		s.users.Flush(),
+		s.draws.Flush(),
+		s.tickets.Flush(),
+		s.prizes.Flush(),
+	}
+	if s.settlements != nil {
+		errs = append(errs, s.settlements.Flush())
 	}
+	return errors.Join(errs...)
+}
+
+func (s *LotteryService) StorageStatus() []storage.FileStatus {
+	status := []storage.FileStatus{s.users.Status(), s.draws.Status(), s.tickets.Status(), s.prizes.Status()}
+	if s.settlements != nil {
+		status = append(status, s.settlements.Status())
+	}
+	if s.audit != nil {
+		status = append(status, s.audit.Status())
+	}
+	return status
+}
+
+// SetLiveDrawDelay makes ExecuteDraw reveal the winning numbers one at a
+// time, waiting d before each. Zero reveals them all at once.
+func (s *LotteryService) SetLiveDrawDelay(d time.Duration) {
+	s.liveDrawDelay = d
+}
+
+// SetIDGenerator replaces the generator used for new record IDs, e.g. with a
+// utils.SequentialIDGenerator to get predictable IDs in tests.
+func (s *LotteryService) SetIDGenerator(g utils.IDGenerator) {
+	s.ids = g
+}
+
+// SetCancelWindow sets how long after purchase a ticket may still be
+// cancelled. Zero means tickets can be cancelled until the draw closes.
+func (s *LotteryService) SetCancelWindow(d time.Duration) {
+	s.cancelWindow = d
+}
+
+// SetTicketCost sets the price charged for new tickets. Tickets already
+// sold keep the price recorded on them.
+func (s *LotteryService) SetTicketCost(cost int) {
+	s.ticketCost = cost
+}
+
+// SetStartingBalance sets the balance new users are registered with.
+func (s *LotteryService) SetStartingBalance(balance int) {
+	s.startBalance = balance
 }
 
 func (s *LotteryService) RegisterUser(username, password string) (models.User, error) {
+	fields := map[string]string{}
+	if username == "" {
+		fields["username"] = "is required"
+	}
+	if password == "" {
+		fields["password"] = "is required"
+	}
+	if len(fields) > 0 {
+		return models.User{}, NewValidationError("Username and password are required", fields)
+	}
+
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
 	if _, err := s.users.GetByUsername(username); err == nil {
-		return models.User{}, errors.New("username already exists")
+		return models.User{}, newError(ErrConflict, "username already exists")
 	}
 
 	user := models.User{
-		ID:        s.generateID(),
+		ID:        s.generateID(utils.UserIDPrefix),
 		Username:  username,
 		Password:  password,
-		Balance:   10000,
+		Balance:   s.startBalance,
 		CreatedAt: time.Now(),
 	}
 
 	if err := s.users.Save(user); err != nil {
 		return models.User{}, err
 	}
+	s.record(UserActor(user.ID), "user.register", user.ID, nil, map[string]any{
+		"username": user.Username,
+		"balance":  user.Balance,
+	})
 
 	user.Password = ""
 	return user, nil
@@ -57,11 +214,11 @@ func (s *LotteryService) RegisterUser(username, password string) (models.User, e
 func (s *LotteryService) LoginUser(username, password string) (models.User, error) {
 	user, err := s.users.GetByUsername(username)
 	if err != nil {
-		return models.User{}, errors.New("invalid username or password")
+		return models.User{}, newError(ErrUnauthorized, "invalid username or password")
 	}
 
 	if user.Password != password {
-		return models.User{}, errors.New("invalid username or password")
+		return models.User{}, newError(ErrUnauthorized, "invalid username or password")
 	}
 
 	user.Password = "" // Don't return password
@@ -77,13 +234,21 @@ func (s *LotteryService) GetUser(userID string) (models.User, error) {
 	return user, nil
 }
 
-func (s *LotteryService) CreateDraw() (models.Draw, error) {
-	if _, err := s.draws.GetPending(); err == nil {
-		return models.Draw{}, errors.New("there is already an active draw")
+// CreateDraw opens a new draw. actor is who asked for it, for the audit log.
+func (s *LotteryService) CreateDraw(actor string) (models.Draw, error) {
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+	s.drawMu.Lock()
+	defer s.drawMu.Unlock()
+
+	for _, d := range s.draws.List() {
+		if d.Status == "pending" || d.Status == "drawing" {
+			return models.Draw{}, newError(ErrConflict, "there is already an active draw")
+		}
 	}
 
 	draw := models.Draw{
-		ID:             s.generateID(),
+		ID:             s.generateID(utils.DrawIDPrefix),
 		WinningNumbers: []int{},
 		Status:         "pending",
 		DrawDate:       time.Now(),
@@ -93,34 +258,112 @@ func (s *LotteryService) CreateDraw() (models.Draw, error) {
 	if err := s.draws.Save(draw); err != nil {
 		return models.Draw{}, err
 	}
+	s.record(actor, "draw.create", draw.ID, nil, map[string]any{"status": draw.Status})
 
+	slog.Info("draw opened", "draw_id", draw.ID)
+	s.events.Publish(EventDrawOpened, draw.ID, draw)
 	return draw, nil
 }
 
-func (s *LotteryService) ExecuteDraw(drawID string) (models.Draw, error) {
-	draw, err := s.draws.GetByID(drawID)
+// ExecuteDraw draws the winning numbers and settles the draw. actor is who
+// asked for it, for the audit log.
+func (s *LotteryService) ExecuteDraw(actor, drawID string) (models.Draw, error) {
+	s.changeMu.RLock()
+	draw, err := s.closeSales(drawID)
+	if err == nil {
+		s.record(actor, "draw.close_sales", draw.ID, map[string]any{"status": "pending"}, map[string]any{"status": draw.Status})
+	}
+	s.changeMu.RUnlock()
 	if err != nil {
 		return models.Draw{}, err
 	}
+	slog.Info("draw sales closed", "draw_id", draw.ID)
+	s.events.Publish(EventSalesClosed, draw.ID, nil)
 
-	if draw.Status == "completed" {
-		return models.Draw{}, errors.New("draw already completed")
+	draw.WinningNumbers = utils.GenerateWinningNumbers()
+	for i, n := range draw.WinningNumbers {
+		if s.liveDrawDelay > 0 {
+			time.Sleep(s.liveDrawDelay)
+		}
+		s.events.Publish(EventNumberDrawn, draw.ID, map[string]int{
+			"position": i + 1,
+			"number":   n,
+		})
 	}
 
-	draw.WinningNumbers = utils.GenerateWinningNumbers()
-	draw.Status = "completed"
+	// A snapshot taken during the reveal sees the draw closed; one taken
+	// once it is over waits for the whole settlement.
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
 
+	draw.Status = "completed"
 	if err := s.draws.Update(draw); err != nil {
 		return models.Draw{}, err
 	}
+	s.record(actor, "draw.execute", draw.ID, map[string]any{"status": "drawing"}, map[string]any{
+		"status":          draw.Status,
+		"winning_numbers": draw.WinningNumbers,
+	})
 
-	if err := s.processDrawResults(draw); err != nil {
+	start := time.Now()
+	s.settle(actor, draw)
+	settlementSeconds.Since(start)
+	drawsSettled.Inc()
+
+	return draw, nil
+}
+
+// closeSales moves a pending draw to "drawing" so no more tickets are sold
+// and a second ExecuteDraw cannot start.
+func (s *LotteryService) closeSales(drawID string) (models.Draw, error) {
+	s.drawMu.Lock()
+	defer s.drawMu.Unlock()
+
+	draw, err := s.draws.GetByID(drawID)
+	if err != nil {
 		return models.Draw{}, err
 	}
 
+	switch draw.Status {
+	case "completed":
+		return models.Draw{}, newError(ErrConflict, "draw already completed")
+	case "drawing":
+		return models.Draw{}, newError(ErrConflict, "draw is already in progress")
+	case "cancelled":
+		return models.Draw{}, newError(ErrConflict, "draw was cancelled")
+	}
+
+	draw.Status = "drawing"
+	if err := s.draws.Update(draw); err != nil {
+		return models.Draw{}, err
+	}
 	return draw, nil
 }
 
+// ReopenInterruptedDraws returns draws left in "drawing" by a server that
+// stopped during the reveal to "pending". Their winning numbers were never
+// stored, so the draw can simply be executed again. It is called at
+// startup, before requests are taken.
+func (s *LotteryService) ReopenInterruptedDraws(actor string) {
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+	s.drawMu.Lock()
+	defer s.drawMu.Unlock()
+
+	for _, draw := range s.draws.List() {
+		if draw.Status != "drawing" {
+			continue
+		}
+		draw.Status = "pending"
+		if err := s.draws.Update(draw); err != nil {
+			slog.Error("interrupted draw not reopened", "draw_id", draw.ID, "err", err)
+			continue
+		}
+		s.record(actor, "draw.reopen", draw.ID, map[string]any{"status": "drawing"}, map[string]any{"status": draw.Status})
+		slog.Warn("reopened draw interrupted during the reveal", "draw_id", draw.ID)
+	}
+}
+
 func (s *LotteryService) GetDraw(drawID string) (models.Draw, error) {
 	return s.draws.GetByID(drawID)
 }
@@ -129,6 +372,11 @@ func (s *LotteryService) ListDraws() []models.Draw {
 	return s.draws.List()
 }
 
+func (s *LotteryService) QueryDraws(f models.DrawFilter) (models.Page[models.Draw], error) {
+	page, err := s.draws.Query(f)
+	return page, queryError(err)
+}
+
 func (s *LotteryService) GetPendingDraw() (models.Draw, error) {
 	return s.draws.GetPending()
 }
@@ -136,114 +384,176 @@ func (s *LotteryService) GetPendingDraw() (models.Draw, error) {
 func (s *LotteryService) CreateTicket(userID, drawID string, numbers []int) (models.Ticket, error) {
 	// Validate numbers
 	if !utils.ValidateNumbers(numbers) {
-		return models.Ticket{}, errors.New("invalid numbers: must be 6 unique numbers between 1 and 49")
+		return models.Ticket{}, NewValidationError("invalid numbers: must be 6 unique numbers between 1 and 49", map[string]string{
+			"numbers": "must be 6 unique numbers between 1 and 49",
+		})
 	}
 
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
 	draw, err := s.draws.GetByID(drawID)
 	if err != nil {
-		return models.Ticket{}, errors.New("draw not found")
+		return models.Ticket{}, err
 	}
 
 	if draw.Status != "pending" {
-		return models.Ticket{}, errors.New("draw is not accepting tickets")
+		return models.Ticket{}, newError(ErrDrawClosed, "draw is not accepting tickets")
 	}
 
-	user, err := s.users.GetByID(userID)
-	if err != nil {
-		return models.Ticket{}, errors.New("user not found")
-	}
-
-	ticketCost := 100
-	if user.Balance < ticketCost {
-		return models.Ticket{}, errors.New("insufficient balance")
-	}
-
-	user.Balance -= ticketCost
-	if err := s.users.Update(user); err != nil {
+	// Take the price in one step, so two purchases cannot both spend the
+	// same balance.
+	if err := s.users.AdjustBalances(map[string]int{userID: -s.ticketCost}); err != nil {
+		if errors.Is(err, storage.ErrNegativeBalance) {
+			return models.Ticket{}, newError(ErrInsufficientBalance, "insufficient balance")
+		}
 		return models.Ticket{}, err
 	}
 
 	ticket := models.Ticket{
-		ID:        s.generateID(),
+		ID:        s.generateID(utils.TicketIDPrefix),
 		UserID:    userID,
 		DrawID:    drawID,
 		Numbers:   numbers,
 		Matches:   0,
+		Serial:    utils.GenerateSerial(),
+		Price:     s.ticketCost,
+		Status:    "active",
 		CreatedAt: time.Now(),
 	}
 
 	if err := s.tickets.Save(ticket); err != nil {
+		if refundErr := s.users.AdjustBalances(map[string]int{userID: s.ticketCost}); refundErr != nil {
+			slog.Error("ticket price not refunded after failed purchase", "user_id", userID, "price", s.ticketCost, "err", refundErr)
+		}
 		return models.Ticket{}, err
 	}
-
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	s.record(UserActor(userID), "ticket.purchase", ticket.ID,
+		map[string]any{"balance": user.Balance + s.ticketCost},
+		map[string]any{"balance": user.Balance, "draw_id": drawID, "price": ticket.Price, "status": ticket.Status})
+
+	ticketsSold.Inc()
+	revenue.Add(float64(ticket.Price))
+	s.notifier.Notify(userID, UserEventTicketConfirmed, ticket)
+	s.notifyBalance(user, -s.ticketCost, "ticket_purchase")
 	return ticket, nil
 }
 
-func (s *LotteryService) GetUserTickets(userID string) []models.Ticket {
-	return s.tickets.GetByUserID(userID)
-}
+func (s *LotteryService) CancelTicket(userID, ticketID string) (models.Ticket, error) {
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
 
-func (s *LotteryService) GetTicket(ticketID string) (models.Ticket, error) {
-	return s.tickets.GetByID(ticketID)
-}
+	ticket, err := s.tickets.GetByID(ticketID)
+	if err != nil {
+		return models.Ticket{}, err
+	}
 
-func (s *LotteryService) processDrawResults(draw models.Draw) error {
-	tickets := s.tickets.GetByDrawID(draw.ID)
+	if ticket.UserID != userID {
+		return models.Ticket{}, newError(ErrNotFound, "ticket not found")
+	}
 
-	for _, ticket := range tickets {
-		matches := utils.CountMatches(ticket.Numbers, draw.WinningNumbers)
-		ticket.Matches = matches
+	if ticket.IsCancelled() {
+		return models.Ticket{}, newError(ErrConflict, "ticket already cancelled")
+	}
 
-		if matches < 1 {
-			s.tickets.Update(ticket)
-			continue
-		}
+	draw, err := s.draws.GetByID(ticket.DrawID)
+	if err != nil {
+		return models.Ticket{}, err
+	}
 
-		prize, err := s.awardPrize(ticket, matches)
-		if err != nil {
-			s.tickets.Update(ticket)
-			continue
-		}
+	if draw.Status != "pending" {
+		return models.Ticket{}, newError(ErrDrawClosed, "draw is no longer accepting cancellations")
+	}
 
-		ticket.PrizeID = prize.ID
+	if s.cancelWindow > 0 && time.Since(ticket.CreatedAt) > s.cancelWindow {
+		return models.Ticket{}, newError(ErrConflict, "cancellation window has expired")
+	}
+
+	if ticket.Price == 0 {
+		ticket.Price = legacyTicketPrice
+	}
 
-		if prize.Type == models.Money {
-			user, err := s.users.GetByID(ticket.UserID)
-			if err == nil {
-				user.Balance += prize.Value
-				s.users.Update(user)
-			}
+	// Move the ticket on only if nobody else has since; the refund
+	// follows from whoever won.
+	status := ticket.Status
+	now := time.Now()
+	ticket.Status = "cancelled"
+	ticket.CancelledAt = &now
+	if err := s.tickets.UpdateIfStatus(ticket, status); err != nil {
+		if errors.Is(err, storage.ErrStatusChanged) {
+			return models.Ticket{}, newError(ErrConflict, "ticket already cancelled")
 		}
+		return models.Ticket{}, err
+	}
 
-		s.tickets.Update(ticket)
+	if err := s.users.AdjustBalances(map[string]int{userID: ticket.Price}); err != nil {
+		ticket.Status = status
+		ticket.CancelledAt = nil
+		if revertErr := s.tickets.Update(ticket); revertErr != nil {
+			slog.Error("cancelled ticket not restored after failed refund", "ticket_id", ticket.ID, "err", revertErr)
+		}
+		return models.Ticket{}, err
 	}
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	s.record(UserActor(userID), "ticket.cancel", ticket.ID,
+		map[string]any{"balance": user.Balance - ticket.Price, "status": status},
+		map[string]any{"balance": user.Balance, "refund": ticket.Price, "status": ticket.Status})
+
+	ticketsCancelled.Inc()
+	refunds.Add(float64(ticket.Price))
+	s.notifyBalance(user, ticket.Price, "ticket_refund")
+	return ticket, nil
+}
+
+func (s *LotteryService) ListCancelledTickets() []models.Ticket {
+	return s.tickets.GetCancelled()
+}
+
+func (s *LotteryService) GetUserTickets(userID string) []models.Ticket {
+	return s.tickets.GetByUserID(userID)
+}
+
+func (s *LotteryService) QueryTickets(f models.TicketFilter) (models.Page[models.Ticket], error) {
+	page, err := s.tickets.Query(f)
+	return page, queryError(err)
+}
 
-	return nil
+func (s *LotteryService) GetTicket(ticketID string) (models.Ticket, error) {
+	return s.tickets.GetByID(ticketID)
 }
 
-func (s *LotteryService) awardPrize(ticket models.Ticket, matches int) (models.Prize, error) {
-	prizeDefs, exists := models.PrizeDefinitions[matches]
-	if !exists || len(prizeDefs) == 0 {
-		return models.Prize{}, errors.New("no prize for this match count")
+func (s *LotteryService) GetTicketBySerial(serial string) (models.Ticket, error) {
+	if !utils.ValidateSerial(serial) {
+		return models.Ticket{}, NewValidationError("invalid serial", map[string]string{
+			"serial": "check digit does not match",
+		})
 	}
+	return s.tickets.GetBySerial(utils.FormatSerial(serial))
+}
 
-	selectedPrize := prizeDefs[s.rng.Intn(len(prizeDefs))]
+// newPrize draws the prize a ticket wins with matches, which must win one.
+func (s *LotteryService) newPrize(ticket models.Ticket, matches int) models.Prize {
+	prizeDefs := models.PrizeDefinitions[matches]
+	selectedPrize := prizeDefs[utils.RandomIntn(len(prizeDefs))]
 
-	prize := models.Prize{
-		ID:           s.generateID(),
+	return models.Prize{
+		ID:           s.generateID(utils.PrizeIDPrefix),
 		TicketID:     ticket.ID,
 		UserID:       ticket.UserID, // 🔥 ВАЖНО
+		DrawID:       ticket.DrawID,
 		Type:         selectedPrize.Type,
 		Name:         selectedPrize.Name,
 		Value:        selectedPrize.Value,
 		MatchesCount: matches,
+		CreatedAt:    time.Now(),
 	}
-
-	if err := s.prizes.Save(prize); err != nil {
-		return models.Prize{}, err
-	}
-
-	return prize, nil
 }
 
 func (s *LotteryService) GetPrizeByTicket(ticketID string) (models.Prize, error) {
@@ -254,6 +564,11 @@ func (s *LotteryService) GetAllPrizes() []models.Prize {
 	return s.prizes.List()
 }
 
+func (s *LotteryService) QueryPrizes(f models.PrizeFilter) (models.Page[models.Prize], error) {
+	page, err := s.prizes.Query(f)
+	return page, queryError(err)
+}
+
 func (s *LotteryService) GetStats() map[string]interface{} {
 	prizes := s.prizes.List()
 
@@ -269,6 +584,12 @@ func (s *LotteryService) GetStats() map[string]interface{} {
 		totalValue += prize.Value
 	}
 
+	cancelled := s.tickets.GetCancelled()
+	refunded := 0
+	for _, t := range cancelled {
+		refunded += t.Price
+	}
+
 	return map[string]interface{}{
 		"total_prizes":   len(prizes),
 		"prizes_by_type": stats,
@@ -276,9 +597,36 @@ func (s *LotteryService) GetStats() map[string]interface{} {
 		"total_users":    len(s.users.List()),
 		"total_tickets":  len(s.tickets.List()),
 		"total_draws":    len(s.draws.List()),
+		"cancelled":      len(cancelled),
+		"total_refunded": refunded,
+	}
+}
+
+func (s *LotteryService) notifyResult(ticket models.Ticket, prize *models.Prize) {
+	s.notifier.Notify(ticket.UserID, UserEventTicketResult, map[string]interface{}{
+		"ticket_id": ticket.ID,
+		"draw_id":   ticket.DrawID,
+		"matches":   ticket.Matches,
+		"prize":     prize,
+	})
+}
+
+func (s *LotteryService) notifyBalance(user models.User, delta int, reason string) {
+	s.notifier.Notify(user.ID, UserEventBalanceChanged, map[string]interface{}{
+		"balance": user.Balance,
+		"delta":   delta,
+		"reason":  reason,
+	})
+}
+
+func randomKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
 	}
+	return key
 }
 
-func (s *LotteryService) generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+func (s *LotteryService) generateID(prefix string) string {
+	return s.ids.NewID(prefix)
 }
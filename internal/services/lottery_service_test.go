@@ -0,0 +1,209 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"LotterySystem/internal/utils"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// newTestService returns a service over a scratch data directory.
+func newTestService(t testing.TB) *LotteryService {
+	t.Helper()
+	return newTestServiceIn(t.TempDir())
+}
+
+// newTestServiceIn returns a service over the data directory dir. Its
+// record IDs are sequential ("tkt_000001", ...), so tests can name them.
+func newTestServiceIn(dir string) *LotteryService {
+	s := NewLotteryService(
+		storage.NewUserRepository(dir),
+		storage.NewDrawRepository(dir),
+		storage.NewTicketRepository(dir),
+		storage.NewPrizeRepository(dir),
+	)
+	s.SetAuditLog(storage.NewAuditLog(dir))
+	s.SetSettlements(storage.NewSettlementRepository(dir))
+	s.SetIDGenerator(utils.NewSequentialIDGenerator())
+	return s
+}
+
+// concurrently runs f n times at once and returns how many calls succeeded
+// and the errors of the others.
+func concurrently(n int, f func() error) (int, []error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		ok     int
+		errs   []error
+		starts = make(chan struct{})
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-starts
+			err := f()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				ok++
+			}
+		}()
+	}
+	close(starts)
+	wg.Wait()
+	return ok, errs
+}
+
+func TestCreateTicketDoesNotOverspend(t *testing.T) {
+	s := newTestService(t)
+	s.SetTicketCost(10)
+	s.SetStartingBalance(30)
+	user, err := s.RegisterUser("alice", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draw, err := s.CreateDraw(SystemActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, errs := concurrently(20, func() error {
+		_, err := s.CreateTicket(user.ID, draw.ID, []int{1, 2, 3, 4, 5, 6})
+		return err
+	})
+	if ok != 3 {
+		t.Errorf("%d purchases succeeded, want 3", ok)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrInsufficientBalance) {
+			t.Errorf("purchase failed with %v, want insufficient balance", err)
+		}
+	}
+	if got, _ := s.GetUser(user.ID); got.Balance != 0 {
+		t.Errorf("balance = %d, want 0", got.Balance)
+	}
+	if n := len(s.GetUserTickets(user.ID)); n != 3 {
+		t.Errorf("%d tickets saved, want 3", n)
+	}
+}
+
+func TestCancelTicketRefundsOnce(t *testing.T) {
+	s := newTestService(t)
+	s.SetTicketCost(10)
+	s.SetStartingBalance(100)
+	user, err := s.RegisterUser("bob", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draw, err := s.CreateDraw(SystemActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticket, err := s.CreateTicket(user.ID, draw.ID, []int{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, errs := concurrently(20, func() error {
+		_, err := s.CancelTicket(user.ID, ticket.ID)
+		return err
+	})
+	if ok != 1 {
+		t.Errorf("%d cancellations succeeded, want 1", ok)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("cancellation failed with %v, want a conflict", err)
+		}
+	}
+	if got, _ := s.GetUser(user.ID); got.Balance != 100 {
+		t.Errorf("balance = %d, want 100", got.Balance)
+	}
+}
+
+func TestCancelDrawRefundsAllOrNothing(t *testing.T) {
+	s := newTestService(t)
+	s.SetTicketCost(10)
+	s.SetStartingBalance(100)
+	user, err := s.RegisterUser("carol", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draw, err := s.CreateDraw(SystemActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := s.CreateTicket(user.ID, draw.ID, []int{1, 2, 3, 4, 5, 6}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A ticket whose owner is gone cannot be refunded.
+	ghost := models.Ticket{ID: "tkt_ghost", UserID: "usr_ghost", DrawID: draw.ID, Numbers: []int{1, 2, 3, 4, 5, 6}, Price: 10, Status: "active"}
+	if err := s.tickets.Save(ghost); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CancelDraw(SystemActor, draw.ID); err == nil {
+		t.Fatal("CancelDraw succeeded with a refund that cannot be made")
+	}
+	if got, _ := s.GetDraw(draw.ID); got.Status != "pending" {
+		t.Errorf("draw status = %s after a failed cancellation, want pending", got.Status)
+	}
+	for _, ticket := range s.tickets.GetByDrawID(draw.ID) {
+		if ticket.IsCancelled() {
+			t.Errorf("ticket %s cancelled without a refund", ticket.ID)
+		}
+	}
+	if got, _ := s.GetUser(user.ID); got.Balance != 80 {
+		t.Errorf("balance = %d, want 80", got.Balance)
+	}
+
+	if err := s.tickets.UpdateAll([]models.Ticket{{ID: ghost.ID, UserID: user.ID, DrawID: draw.ID, Numbers: ghost.Numbers, Price: 10, Status: "active"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CancelDraw(SystemActor, draw.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetUser(user.ID); got.Balance != 110 {
+		t.Errorf("balance after cancelling = %d, want 110", got.Balance)
+	}
+	for _, ticket := range s.tickets.GetByDrawID(draw.ID) {
+		if !ticket.IsCancelled() {
+			t.Errorf("ticket %s still active in a cancelled draw", ticket.ID)
+		}
+	}
+}
+
+func TestReopenInterruptedDraws(t *testing.T) {
+	s := newTestService(t)
+	draw, err := s.CreateDraw(SystemActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As left by a server stopped during the reveal.
+	if _, err := s.closeSales(draw.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ExecuteDraw(SystemActor, draw.ID); !errors.Is(err, ErrConflict) {
+		t.Fatalf("ExecuteDraw of a drawing draw: err = %v, want a conflict", err)
+	}
+
+	s.ReopenInterruptedDraws(SystemActor)
+	if got, _ := s.GetDraw(draw.ID); got.Status != "pending" {
+		t.Fatalf("status after reopening = %s, want pending", got.Status)
+	}
+	executed, err := s.ExecuteDraw(SystemActor, draw.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if executed.Status != "completed" || len(executed.WinningNumbers) != 6 {
+		t.Fatalf("executed draw = %s with numbers %v", executed.Status, executed.WinningNumbers)
+	}
+}
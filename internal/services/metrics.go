@@ -0,0 +1,59 @@
+package services
+
+import (
+	"LotterySystem/internal/metrics"
+	"LotterySystem/internal/models"
+	"strconv"
+	"sync"
+)
+
+// Amounts are in the same units as balances.
+var (
+	ticketsSold      = metrics.NewCounter("lottery_tickets_sold_total", "Tickets sold.")
+	ticketsCancelled = metrics.NewCounter("lottery_tickets_cancelled_total", "Tickets cancelled and refunded.")
+	revenue          = metrics.NewCounter("lottery_revenue_total", "Amount charged for tickets, before refunds.")
+	refunds          = metrics.NewCounter("lottery_refunds_total", "Amount refunded for cancelled tickets.")
+	prizesAwarded    = metrics.NewCounterVec("lottery_prizes_awarded_total", "Prizes awarded, by tier (numbers matched) and type.", "tier", "type")
+	prizesPaid       = metrics.NewCounterVec("lottery_prizes_paid_total", "Amount credited for money prizes, by tier.", "tier")
+	drawsSettled     = metrics.NewCounter("lottery_draws_settled_total", "Draws whose results have been settled.")
+	eventsDropped    = metrics.NewCounter("lottery_events_dropped_total", "Events a subscriber missed because it was not keeping up.")
+
+	settlementSeconds = metrics.NewHistogram("lottery_draw_settlement_seconds",
+		"Time taken to settle a draw's tickets, after the numbers are drawn.",
+		[]float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300})
+)
+
+var registerGauges sync.Once
+
+// RegisterMetrics adds the gauges that read this service's data. Counters
+// are always collected; only one service per process can report gauges.
+func (s *LotteryService) RegisterMetrics() {
+	registerGauges.Do(func() {
+		metrics.NewGaugeFunc("lottery_open_draws", "Draws accepting tickets.", func() float64 {
+			open := 0
+			for _, d := range s.draws.List() {
+				if d.Status == "pending" {
+					open++
+				}
+			}
+			return float64(open)
+		})
+		metrics.NewGaugeFunc("lottery_jackpot", "Value of the prize for matching all six numbers.", func() float64 {
+			return float64(jackpot())
+		})
+	})
+}
+
+func jackpot() int {
+	value := 0
+	for _, p := range models.PrizeDefinitions[6] {
+		if p.Type == models.Money && p.Value > value {
+			value = p.Value
+		}
+	}
+	return value
+}
+
+func tier(prize models.Prize) string {
+	return strconv.Itoa(prize.MatchesCount)
+}
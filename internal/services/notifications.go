@@ -0,0 +1,152 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+type UserEventType string
+
+const (
+	UserEventTicketConfirmed UserEventType = "ticket.confirmed"
+	UserEventTicketResult    UserEventType = "ticket.result"
+	UserEventPrizeCredited   UserEventType = "prize.credited"
+	UserEventBalanceChanged  UserEventType = "balance.changed"
+	// Reserved for recurring ticket subscriptions, which do not exist yet.
+	UserEventSubscriptionSkipped UserEventType = "subscription.skipped"
+)
+
+const defaultReplaySize = 100
+
+// A user's stream is kept for streamIdle after its last event or
+// subscriber, so a client that reconnects within it can still replay;
+// idle streams are looked for every streamSweepInterval.
+const (
+	streamIdle          = time.Hour
+	streamSweepInterval = 10 * time.Minute
+)
+
+type UserEvent struct {
+	ID   uint64        `json:"id"`
+	Type UserEventType `json:"type"`
+	Time time.Time     `json:"time"`
+	Data interface{}   `json:"data,omitempty"`
+}
+
+// Notifier delivers personal events to a user's connected clients and keeps
+// the last few per user so a reconnecting client can catch up.
+type Notifier struct {
+	mu         sync.Mutex
+	replaySize int
+	users      map[string]*userStream
+	swept      time.Time
+}
+
+type userStream struct {
+	seq    uint64
+	replay []UserEvent
+	nextID int
+	subs   map[int]chan UserEvent
+	// active is when the stream last had an event or lost a subscriber.
+	active time.Time
+}
+
+func NewNotifier(replaySize int) *Notifier {
+	return &Notifier{replaySize: replaySize, users: make(map[string]*userStream), swept: time.Now()}
+}
+
+// stream returns userID's stream, creating it if need be. The caller holds
+// n.mu.
+func (n *Notifier) stream(userID string) *userStream {
+	now := time.Now()
+	if now.Sub(n.swept) >= streamSweepInterval {
+		n.evict(now.Add(-streamIdle))
+		n.swept = now
+	}
+	st, ok := n.users[userID]
+	if !ok {
+		st = &userStream{subs: make(map[int]chan UserEvent), active: now}
+		n.users[userID] = st
+	}
+	return st
+}
+
+// evict drops the streams nobody is subscribed to that have been idle since
+// before cutoff. A client that comes back after its stream is gone sees IDs
+// from before it, which Subscribe treats like a restart. The caller holds
+// n.mu.
+func (n *Notifier) evict(cutoff time.Time) {
+	for userID, st := range n.users {
+		if len(st.subs) == 0 && st.active.Before(cutoff) {
+			delete(n.users, userID)
+		}
+	}
+}
+
+// Notify records an event for userID and hands it to every subscriber. A
+// subscriber whose buffer is full is dropped, closing its channel; the client
+// is expected to reconnect and replay from the last ID it saw.
+func (n *Notifier) Notify(userID string, t UserEventType, data interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	st := n.stream(userID)
+	st.seq++
+	e := UserEvent{ID: st.seq, Type: t, Time: time.Now(), Data: data}
+	st.active = e.Time
+
+	st.replay = append(st.replay, e)
+	if len(st.replay) > n.replaySize {
+		st.replay = st.replay[len(st.replay)-n.replaySize:]
+	}
+
+	for id, ch := range st.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(st.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the buffered events after lastID, a channel of new ones
+// and a cancel function. truncated reports that events between lastID and the
+// oldest buffered one were lost.
+func (n *Notifier) Subscribe(userID string, lastID uint64, buffer int) (replay []UserEvent, events <-chan UserEvent, cancel func(), truncated bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	st := n.stream(userID)
+	for _, e := range st.replay {
+		if e.ID > lastID {
+			replay = append(replay, e)
+		}
+	}
+	if lastID > 0 && len(st.replay) > 0 && st.replay[0].ID > lastID+1 {
+		truncated = true
+	}
+	if lastID > st.seq {
+		// The client saw IDs from before a restart; everything is new to it.
+		replay = append([]UserEvent(nil), st.replay...)
+		truncated = true
+	}
+
+	id := st.nextID
+	st.nextID++
+	ch := make(chan UserEvent, buffer)
+	st.subs[id] = ch
+
+	var once sync.Once
+	return replay, ch, func() {
+		once.Do(func() {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			if c, ok := st.subs[id]; ok {
+				delete(st.subs, id)
+				close(c)
+			}
+			st.active = time.Now()
+		})
+	}, truncated
+}
@@ -0,0 +1,38 @@
+package services
+
+import "testing"
+
+func TestNotifierEvictsIdleStreams(t *testing.T) {
+	n := NewNotifier(defaultReplaySize)
+	n.Notify("usr_idle", UserEventBalanceChanged, nil)
+	n.Notify("usr_watched", UserEventBalanceChanged, nil)
+	_, _, cancel, _ := n.Subscribe("usr_watched", 0, 1)
+	defer cancel()
+
+	n.mu.Lock()
+	for _, st := range n.users {
+		st.active = st.active.Add(-2 * streamIdle)
+	}
+	n.swept = n.swept.Add(-streamSweepInterval)
+	n.mu.Unlock()
+	n.Notify("usr_new", UserEventBalanceChanged, nil)
+
+	n.mu.Lock()
+	_, idle := n.users["usr_idle"]
+	_, watched := n.users["usr_watched"]
+	n.mu.Unlock()
+	if idle {
+		t.Error("an idle stream nobody subscribes to was kept")
+	}
+	if !watched {
+		t.Error("a stream with a subscriber was evicted")
+	}
+
+	// A client that comes back after its stream is gone is told it missed
+	// events.
+	_, _, cancelIdle, truncated := n.Subscribe("usr_idle", 1, 1)
+	defer cancelIdle()
+	if !truncated {
+		t.Error("resubscribing to an evicted stream was not reported as truncated")
+	}
+}
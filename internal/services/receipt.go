@@ -0,0 +1,138 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/qrcode"
+	"LotterySystem/internal/utils"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// Receipt payloads look like "LT1.<serial>.<ticket id>.<signature>". The
+// signature is a truncated HMAC-SHA256 so the payload fits a small QR code.
+const (
+	receiptPrefix   = "LT1"
+	receiptSigBytes = 16
+)
+
+var errInvalidReceipt = NewValidationError("invalid receipt", nil)
+
+// SetReceiptKey sets the HMAC key used to sign receipts. Receipts signed with
+// a previous key stop verifying.
+func (s *LotteryService) SetReceiptKey(key []byte) {
+	s.receiptKey = key
+}
+
+func (s *LotteryService) GetTicketReceipt(ticketID string) (models.Receipt, error) {
+	ticket, err := s.tickets.GetByID(ticketID)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+
+	return models.Receipt{
+		TicketID: ticket.ID,
+		Serial:   ticket.Serial,
+		DrawID:   ticket.DrawID,
+		Numbers:  ticket.Numbers,
+		IssuedAt: ticket.CreatedAt,
+		Payload:  s.receiptPayload(ticket),
+	}, nil
+}
+
+func (s *LotteryService) GetTicketReceiptQR(ticketID string, scale int) ([]byte, error) {
+	receipt, err := s.GetTicketReceipt(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.Encode([]byte(receipt.Payload))
+	if err != nil {
+		return nil, err
+	}
+	return code.PNG(scale)
+}
+
+func (s *LotteryService) VerifyReceipt(payload string) (models.ReceiptVerification, error) {
+	parts := strings.Split(strings.TrimSpace(payload), ".")
+	if len(parts) != 4 || parts[0] != receiptPrefix {
+		return models.ReceiptVerification{}, errInvalidReceipt
+	}
+
+	serial, ticketID := parts[1], parts[2]
+	if !utils.ValidateSerial(serial) {
+		return models.ReceiptVerification{}, errInvalidReceipt
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return models.ReceiptVerification{}, errInvalidReceipt
+	}
+
+	ticket, err := s.tickets.GetByID(ticketID)
+	if err != nil || utils.NormalizeSerial(ticket.Serial) != utils.NormalizeSerial(serial) {
+		return models.ReceiptVerification{}, errInvalidReceipt
+	}
+
+	if !hmac.Equal(sig, s.receiptSignature(ticket)) {
+		return models.ReceiptVerification{}, errInvalidReceipt
+	}
+
+	result := models.ReceiptVerification{
+		Valid:        true,
+		Serial:       ticket.Serial,
+		DrawID:       ticket.DrawID,
+		DrawStatus:   "unknown",
+		TicketStatus: ticket.Status,
+		Numbers:      ticket.Numbers,
+		Matches:      ticket.Matches,
+	}
+	if result.TicketStatus == "" {
+		result.TicketStatus = "active"
+	}
+
+	if draw, err := s.draws.GetByID(ticket.DrawID); err == nil {
+		result.DrawStatus = draw.Status
+		result.WinningNumbers = draw.WinningNumbers
+	}
+
+	if ticket.PrizeID != "" {
+		if prize, err := s.prizes.GetByTicketID(ticket.ID); err == nil {
+			result.PrizeType = prize.Type
+			result.PrizeName = prize.Name
+			result.PrizeValue = prize.Value
+		}
+	}
+
+	return result, nil
+}
+
+func (s *LotteryService) receiptPayload(ticket models.Ticket) string {
+	return strings.Join([]string{
+		receiptPrefix,
+		utils.NormalizeSerial(ticket.Serial),
+		ticket.ID,
+		base64.RawURLEncoding.EncodeToString(s.receiptSignature(ticket)),
+	}, ".")
+}
+
+func (s *LotteryService) receiptSignature(ticket models.Ticket) []byte {
+	mac := hmac.New(sha256.New, s.receiptKey)
+	mac.Write([]byte(receiptPrefix))
+	mac.Write([]byte{0})
+	mac.Write([]byte(utils.NormalizeSerial(ticket.Serial)))
+	mac.Write([]byte{0})
+	mac.Write([]byte(ticket.ID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(ticket.DrawID))
+	mac.Write([]byte{0})
+	for _, n := range ticket.Numbers {
+		mac.Write([]byte(strconv.Itoa(n)))
+		mac.Write([]byte{','})
+	}
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(ticket.CreatedAt.UnixNano(), 10)))
+	return mac.Sum(nil)[:receiptSigBytes]
+}
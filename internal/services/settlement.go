@@ -0,0 +1,665 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"LotterySystem/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"time"
+)
+
+const (
+	// settlementMinBatch and settlementMaxBatches size the batches a draw's
+	// tickets are settled in. Each batch rewrites the tickets, prizes and
+	// users files once, so a large draw is split into at most
+	// settlementMaxBatches of them; a small one is one batch.
+	settlementMinBatch   = 1000
+	settlementMaxBatches = 10
+)
+
+// SetSettlements sets where the progress of each draw's settlement is kept.
+// Without it draws are still settled, but nothing records how far a
+// settlement got.
+func (s *LotteryService) SetSettlements(r *storage.SettlementRepository) {
+	s.settlements = r
+}
+
+func (s *LotteryService) putSettlement(st models.Settlement) error {
+	if s.settlements == nil {
+		return nil
+	}
+	return s.settlements.Put(st)
+}
+
+// settle works out every ticket's result from the winning numbers and
+// applies whatever is missing: the matches and prize on the ticket, the
+// prize itself and, for money, the credit. A ticket settled before is left
+// as it is, so running it again after a failure, or a crash, only finishes
+// the job.
+//
+// A pool of workers works out the results a batch at a time while the
+// batches before are applied in order, each with one write per file, after
+// which the progress is saved and published. A batch write that a record
+// refused is retried a record at a time, so a ticket that fails is logged
+// and counted and the rest are still settled. A data file that cannot be
+// written, the settlement's own included, stops the settlement as failed;
+// the saved progress shows where to carry on. The caller holds changeMu
+// shared.
+func (s *LotteryService) settle(actor string, draw models.Draw) models.Settlement {
+	s.settleMu.Lock()
+	defer s.settleMu.Unlock()
+
+	log := slog.With("draw_id", draw.ID)
+	now := time.Now()
+	st := models.Settlement{DrawID: draw.ID, Status: models.SettlementRunning, Run: 1, StartedAt: now, UpdatedAt: now}
+	if s.settlements != nil {
+		if prev, err := s.settlements.Get(draw.ID); err == nil {
+			// One that never finished is carried on, not started again.
+			st.Run = prev.Run
+			if prev.Status != models.SettlementRunning {
+				st.Run++
+			}
+		}
+	}
+
+	tickets := s.tickets.GetByDrawID(draw.ID)
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
+	awarded := map[string]models.Prize{}
+	for _, p := range s.prizes.List() {
+		awarded[p.TicketID] = p
+	}
+	s.markRecordedCredits(log, tickets, awarded)
+	st.Tickets = len(tickets)
+	if err := s.putSettlement(st); err != nil {
+		return s.abortSettlement(log, actor, draw, st, fmt.Errorf("progress not saved: %w", err))
+	}
+
+	size := settlementBatch(len(tickets))
+	results := make([]ticketResult, len(tickets))
+	for b, done := range resolveTickets(draw, tickets, awarded, results, size) {
+		<-done
+		if err := s.applySettlement(log, actor, draw, results[b*size:min((b+1)*size, len(results))], &st); err != nil {
+			return s.abortSettlement(log, actor, draw, st, err)
+		}
+		st.Processed = min((b+1)*size, len(results))
+		st.UpdatedAt = time.Now()
+		if err := s.putSettlement(st); err != nil {
+			return s.abortSettlement(log, actor, draw, st, fmt.Errorf("progress not saved: %w", err))
+		}
+		s.events.Publish(EventSettleProgress, draw.ID, map[string]interface{}{
+			"run":       st.Run,
+			"processed": st.Processed,
+			"tickets":   st.Tickets,
+			"winners":   st.Winners,
+			"failed":    st.Failed,
+		})
+		log.Debug("settlement progress", "run", st.Run, "processed", st.Processed, "tickets", st.Tickets)
+	}
+
+	finished := time.Now()
+	st.Status = models.SettlementCompleted
+	if st.Failed > 0 {
+		st.Status = models.SettlementFailed
+	}
+	st.UpdatedAt, st.FinishedAt = finished, &finished
+	if err := s.putSettlement(st); err != nil {
+		log.Error("settlement result not saved", "run", st.Run, "status", st.Status, "err", err)
+	}
+
+	log.Info("draw settled", "run", st.Run, "status", st.Status, "tickets", st.Tickets, "winners", st.Winners,
+		"awarded", st.Awarded, "credited", st.Credited, "failed", st.Failed, "took", finished.Sub(st.StartedAt))
+	s.record(actor, "draw.settle", draw.ID, nil, map[string]any{
+		"run":      st.Run,
+		"status":   st.Status,
+		"tickets":  st.Tickets,
+		"winners":  st.Winners,
+		"awarded":  st.Awarded,
+		"credited": st.Credited,
+		"failed":   st.Failed,
+	})
+
+	if st.Run == 1 {
+		s.events.Publish(EventDrawSettled, draw.ID, map[string]interface{}{
+			"winning_numbers": draw.WinningNumbers,
+			"tickets":         st.Tickets,
+			"winners":         st.Winners,
+		})
+	}
+	return st
+}
+
+// abortSettlement ends a settlement that could not write its data files
+// as failed, saving that if it still can, and returns it.
+func (s *LotteryService) abortSettlement(log *slog.Logger, actor string, draw models.Draw, st models.Settlement, err error) models.Settlement {
+	finished := time.Now()
+	st.Status = models.SettlementFailed
+	st.LastError = err.Error()
+	st.UpdatedAt, st.FinishedAt = finished, &finished
+	log.Error("settlement stopped", "run", st.Run, "processed", st.Processed, "tickets", st.Tickets, "err", err)
+	if perr := s.putSettlement(st); perr != nil {
+		log.Error("settlement result not saved", "run", st.Run, "status", st.Status, "err", perr)
+	}
+	s.record(actor, "draw.settle", draw.ID, nil, map[string]any{
+		"run":       st.Run,
+		"status":    st.Status,
+		"tickets":   st.Tickets,
+		"processed": st.Processed,
+		"error":     st.LastError,
+	})
+	return st
+}
+
+// markRecordedCredits marks credited the money prizes of tickets in
+// awarded that the audit log shows were credited but that are not marked,
+// so settling them again does not pay them twice. They are marked in
+// awarded even if the prizes file cannot be written.
+func (s *LotteryService) markRecordedCredits(log *slog.Logger, tickets []models.Ticket, awarded map[string]models.Prize) {
+	unmarked := unmarkedPrizes(tickets, awarded)
+	credits := s.recordedCredits(unmarked)
+	if len(credits) == 0 {
+		return
+	}
+	marked := make([]models.Prize, 0, len(credits))
+	for prizeID, at := range credits {
+		ticketID := unmarked[prizeID]
+		p := awarded[ticketID]
+		p.CreditedAt = &at
+		awarded[ticketID] = p
+		marked = append(marked, p)
+		log.Warn("prize was credited but not marked, marking it", "prize_id", p.ID, "user_id", p.UserID, "credited_at", at)
+	}
+	if err := s.prizes.UpdateAll(marked); err != nil {
+		log.Error("credited prizes not marked", "prizes", len(marked), "err", err)
+	}
+}
+
+// unmarkedPrizes returns the ticket of each money prize of tickets in
+// awarded that is not marked credited, by prize ID.
+func unmarkedPrizes(tickets []models.Ticket, awarded map[string]models.Prize) map[string]string {
+	unmarked := map[string]string{}
+	for _, t := range tickets {
+		if p, ok := awarded[t.ID]; ok && p.Type == models.Money && p.CreditedAt == nil {
+			unmarked[p.ID] = t.ID
+		}
+	}
+	return unmarked
+}
+
+// recordedCredits returns when the audit log records each of prizeIDs as
+// credited to its winner, for those it does.
+func (s *LotteryService) recordedCredits(prizeIDs map[string]string) map[string]time.Time {
+	if len(prizeIDs) == 0 || s.audit == nil {
+		return nil
+	}
+	page, err := s.audit.Query(models.AuditFilter{Action: "balance.credit"})
+	if err != nil {
+		slog.Error("audit log not read for prize credits", "err", err)
+		return nil
+	}
+	credits := map[string]time.Time{}
+	for _, e := range page.Items {
+		var after struct {
+			PrizeID string `json:"prize_id"`
+		}
+		if json.Unmarshal(e.After, &after) != nil {
+			continue
+		}
+		if _, ok := prizeIDs[after.PrizeID]; ok {
+			credits[after.PrizeID] = e.Time
+		}
+	}
+	return credits
+}
+
+// settlementBatch is how many of a draw's tickets are settled together.
+func settlementBatch(tickets int) int {
+	return max(settlementMinBatch, (tickets+settlementMaxBatches-1)/settlementMaxBatches)
+}
+
+// ticketResult is one ticket's result as worked out from the winning
+// numbers, and what applying it came to.
+type ticketResult struct {
+	ticket  models.Ticket
+	matches int
+	// due is whether the matches win a prize; won whether the ticket has
+	// one, awarded before or by this settlement.
+	due    bool
+	won    bool
+	prize  models.Prize
+	notify bool
+	err    error
+}
+
+func (r *ticketResult) fail(err error) {
+	r.err = errors.Join(r.err, err)
+}
+
+// resolveTickets works out the results of tickets into results, in batches
+// of size, on one worker per CPU. It returns a channel per batch that is
+// closed once the batch's results are in, so they can be applied in order
+// while later batches are still being worked out.
+func resolveTickets(draw models.Draw, tickets []models.Ticket, awarded map[string]models.Prize, results []ticketResult, size int) []chan struct{} {
+	ready := make([]chan struct{}, (len(tickets)+size-1)/size)
+	batches := make(chan int, len(ready))
+	for b := range ready {
+		ready[b] = make(chan struct{})
+		batches <- b
+	}
+	close(batches)
+
+	for range min(runtime.GOMAXPROCS(0), len(ready)) {
+		go func() {
+			for b := range batches {
+				for i := b * size; i < min((b+1)*size, len(tickets)); i++ {
+					t := tickets[i]
+					r := ticketResult{ticket: t, matches: utils.CountMatches(t.Numbers, draw.WinningNumbers)}
+					r.due = len(models.PrizeDefinitions[r.matches]) > 0
+					r.prize, r.won = awarded[t.ID]
+					results[i] = r
+				}
+				close(ready[b])
+			}
+		}()
+	}
+	return ready
+}
+
+// applySettlement applies the results of one batch: it awards the missing
+// prizes, credits the money ones not yet credited and updates the tickets
+// whose result changed, each with a single write, then records it all in
+// the audit log with one more. It returns the first failure to write a
+// data file, after which the settlement should not go on.
+func (s *LotteryService) applySettlement(log *slog.Logger, actor string, draw models.Draw, batch []ticketResult, st *models.Settlement) error {
+	var records []storage.AuditRecord
+
+	var fresh []models.Prize
+	var freshFor []int
+	for i, r := range batch {
+		if r.due && !r.won {
+			fresh = append(fresh, s.newPrize(r.ticket, r.matches))
+			freshFor = append(freshFor, i)
+		}
+	}
+	failed := inBatches(fresh, s.prizes.SaveAll)
+	var awardedEvents []interface{}
+	for k, prize := range fresh {
+		r := &batch[freshFor[k]]
+		if err := failed[k]; err != nil {
+			log.Error("prize not awarded", "ticket_id", r.ticket.ID, "matches", r.matches, "err", err)
+			r.fail(fmt.Errorf("award prize: %w", err))
+			continue
+		}
+		r.prize, r.won = prize, true
+		st.Awarded++
+		records = append(records, storage.AuditRecord{Actor: actor, Action: "prize.award", Target: prize.ID, After: map[string]any{
+			"ticket_id": prize.TicketID,
+			"user_id":   prize.UserID,
+			"type":      prize.Type,
+			"name":      prize.Name,
+			"value":     prize.Value,
+			"matches":   prize.MatchesCount,
+		}})
+		prizesAwarded.With(tier(prize), string(prize.Type)).Inc()
+		awardedEvents = append(awardedEvents, map[string]interface{}{
+			"prize_id":  prize.ID,
+			"ticket_id": prize.TicketID,
+			"type":      prize.Type,
+			"name":      prize.Name,
+			"value":     prize.Value,
+			"matches":   prize.MatchesCount,
+		})
+	}
+	if len(awardedEvents) > 0 {
+		s.events.PublishAll(EventPrizeAwarded, draw.ID, awardedEvents)
+	}
+
+	var owed []int
+	for i, r := range batch {
+		if r.won && r.prize.Type == models.Money && r.prize.CreditedAt == nil {
+			owed = append(owed, i)
+		}
+	}
+	records = append(records, s.creditPrizes(log, actor, batch, owed, st)...)
+
+	var changed []models.Ticket
+	var changedFor []int
+	for i := range batch {
+		r := &batch[i]
+		prizeID := ""
+		if r.won {
+			prizeID = r.prize.ID
+		}
+		if r.ticket.Matches == r.matches && r.ticket.PrizeID == prizeID {
+			// Tickets learn their result when the draw is first settled,
+			// whether or not it changed anything.
+			r.notify = st.Run == 1
+			continue
+		}
+		r.ticket.Matches, r.ticket.PrizeID = r.matches, prizeID
+		changed = append(changed, r.ticket)
+		changedFor = append(changedFor, i)
+	}
+	failed = inBatches(changed, s.tickets.UpdateAll)
+	for k, i := range changedFor {
+		r := &batch[i]
+		if err := failed[k]; err != nil {
+			log.Error("settled ticket not updated", "ticket_id", r.ticket.ID, "matches", r.ticket.Matches, "prize_id", r.ticket.PrizeID, "err", err)
+			r.fail(fmt.Errorf("update ticket: %w", err))
+			continue
+		}
+		st.Updated++
+		r.notify = true
+	}
+
+	s.recordAll(records)
+
+	var writeErr error
+	for i := range batch {
+		r := &batch[i]
+		if r.won {
+			st.Winners++
+		}
+		if r.err != nil {
+			st.Failed++
+			st.LastError = fmt.Sprintf("ticket %s: %v", r.ticket.ID, r.err)
+			if writeErr == nil && errors.Is(r.err, storage.ErrNotWritten) {
+				writeErr = fmt.Errorf("ticket %s: %w", r.ticket.ID, r.err)
+			}
+		}
+		switch {
+		case r.notify && r.won:
+			s.notifyResult(r.ticket, &r.prize)
+		case r.notify:
+			s.notifyResult(r.ticket, nil)
+		}
+	}
+	return writeErr
+}
+
+// inBatches writes items with one call to write and, if a record was
+// refused, writes each on its own so one bad record does not hold up the
+// rest. A file that could not be written fails them all. It returns the
+// errors of the items that could not be written, by index.
+func inBatches[T any](items []T, write func([]T) error) map[int]error {
+	if len(items) == 0 {
+		return nil
+	}
+	err := write(items)
+	if err == nil {
+		return nil
+	}
+	failed := map[int]error{}
+	if errors.Is(err, storage.ErrNotWritten) {
+		for i := range items {
+			failed[i] = err
+		}
+		return failed
+	}
+	for i := range items {
+		if err := write(items[i : i+1]); err != nil {
+			failed[i] = err
+		}
+	}
+	return failed
+}
+
+// creditPrizes credits the money prizes of the results at owed. They are
+// all marked credited with one write and the balances credited with
+// another; if either fails the marks are taken back and, unless a file
+// could not be written, each prize is credited on its own by creditPrize.
+// It returns the audit records of the batch credit.
+func (s *LotteryService) creditPrizes(log *slog.Logger, actor string, batch []ticketResult, owed []int, st *models.Settlement) []storage.AuditRecord {
+	if len(owed) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	marked := make([]models.Prize, len(owed))
+	deltas := map[string]int{}
+	for k, i := range owed {
+		marked[k] = batch[i].prize
+		marked[k].CreditedAt = &now
+		deltas[marked[k].UserID] += marked[k].Value
+	}
+	err := s.prizes.UpdateAll(marked)
+	if err == nil {
+		if err = s.users.AdjustBalances(deltas); err != nil {
+			unmarked := make([]models.Prize, len(owed))
+			for k, i := range owed {
+				unmarked[k] = batch[i].prize
+			}
+			if uerr := s.prizes.UpdateAll(unmarked); uerr != nil {
+				log.Error("prizes left marked credited", "prizes", len(unmarked), "err", uerr)
+			}
+		}
+	}
+	if errors.Is(err, storage.ErrNotWritten) {
+		for _, i := range owed {
+			batch[i].fail(fmt.Errorf("credit prize %s: %w", batch[i].prize.ID, err))
+		}
+		return nil
+	}
+	if err != nil {
+		log.Warn("prizes not credited together, crediting one at a time", "prizes", len(owed), "err", err)
+		for _, i := range owed {
+			r := &batch[i]
+			if err := s.creditPrize(actor, &r.prize); err != nil {
+				log.Error("prize not credited", "prize_id", r.prize.ID, "user_id", r.prize.UserID, "value", r.prize.Value, "err", err)
+				r.fail(fmt.Errorf("credit prize %s: %w", r.prize.ID, err))
+				continue
+			}
+			st.Credited++
+		}
+		return nil
+	}
+
+	// Each credit is recorded with the balance it took the user from and
+	// to, as if they had been applied one by one.
+	users := map[string]models.User{}
+	balance := map[string]int{}
+	for id, delta := range deltas {
+		if user, err := s.users.GetByID(id); err == nil {
+			users[id] = user
+			balance[id] = user.Balance - delta
+		}
+	}
+	records := make([]storage.AuditRecord, 0, len(owed))
+	for k, i := range owed {
+		prize := marked[k]
+		batch[i].prize = prize
+		before := balance[prize.UserID]
+		balance[prize.UserID] += prize.Value
+		records = append(records, storage.AuditRecord{Actor: actor, Action: "balance.credit", Target: prize.UserID,
+			Before: map[string]any{"balance": before},
+			After:  map[string]any{"balance": balance[prize.UserID], "prize_id": prize.ID, "reason": "prize"}})
+		st.Credited++
+		prizesPaid.With(tier(prize)).Add(float64(prize.Value))
+		s.notifier.Notify(prize.UserID, UserEventPrizeCredited, prize)
+	}
+	for id, user := range users {
+		s.notifyBalance(user, deltas[id], "prize")
+	}
+	return records
+}
+
+// creditPrize adds one money prize to its winner's balance. The prize is
+// marked credited first, so a crash in between can leave it marked and
+// unpaid, which the missing balance.credit in the audit log shows, but
+// never pays it twice. creditPrizes does the same for a batch.
+func (s *LotteryService) creditPrize(actor string, prize *models.Prize) error {
+	if _, err := s.users.GetByID(prize.UserID); err != nil {
+		return err
+	}
+	now := time.Now()
+	prize.CreditedAt = &now
+	if err := s.prizes.Update(*prize); err != nil {
+		prize.CreditedAt = nil
+		return err
+	}
+
+	if err := s.users.AdjustBalances(map[string]int{prize.UserID: prize.Value}); err != nil {
+		prize.CreditedAt = nil
+		if uerr := s.prizes.Update(*prize); uerr != nil {
+			slog.Error("prize left marked credited", "prize_id", prize.ID, "err", uerr)
+		}
+		return err
+	}
+	user, err := s.users.GetByID(prize.UserID)
+	if err != nil {
+		return err
+	}
+	s.record(actor, "balance.credit", user.ID,
+		map[string]any{"balance": user.Balance - prize.Value},
+		map[string]any{"balance": user.Balance, "prize_id": prize.ID, "reason": "prize"})
+	prizesPaid.With(tier(*prize)).Add(float64(prize.Value))
+	s.notifier.Notify(user.ID, UserEventPrizeCredited, *prize)
+	s.notifyBalance(user, prize.Value, "prize")
+	return nil
+}
+
+// ResettleDraw settles a completed draw again, applying only the prizes,
+// credits and ticket results that are missing. actor is who asked for it,
+// for the audit log.
+func (s *LotteryService) ResettleDraw(actor, drawID string) (models.Settlement, error) {
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
+	draw, err := s.draws.GetByID(drawID)
+	if err != nil {
+		return models.Settlement{}, err
+	}
+	if draw.Status != "completed" {
+		return models.Settlement{}, newError(ErrConflict, "only completed draws can be resettled")
+	}
+	s.record(actor, "draw.resettle", draw.ID, nil, nil)
+	return s.settle(actor, draw), nil
+}
+
+// ResumeSettlements finishes the settlements that were still running when
+// the server last stopped. It is called at startup, before requests are
+// taken.
+func (s *LotteryService) ResumeSettlements(actor string) {
+	if s.settlements == nil {
+		return
+	}
+	for _, st := range s.settlements.Running() {
+		slog.Warn("resuming interrupted settlement", "draw_id", st.DrawID, "run", st.Run, "processed", st.Processed, "tickets", st.Tickets)
+		if _, err := s.ResettleDraw(actor, st.DrawID); err != nil {
+			slog.Error("settlement not resumed", "draw_id", st.DrawID, "err", err)
+		}
+	}
+}
+
+// GetSettlement returns the latest settlement of a draw.
+func (s *LotteryService) GetSettlement(drawID string) (models.Settlement, error) {
+	if _, err := s.draws.GetByID(drawID); err != nil {
+		return models.Settlement{}, err
+	}
+	if s.settlements == nil {
+		return models.Settlement{}, newError(ErrNotFound, "settlements are not recorded")
+	}
+	st, err := s.settlements.Get(drawID)
+	if err != nil {
+		return models.Settlement{}, newError(ErrNotFound, "draw has not been settled")
+	}
+	return st, nil
+}
+
+// ReconcileDraw works out again what each ticket of a completed draw should
+// have won and compares it with the prizes awarded and credited. It changes
+// nothing; ResettleDraw applies what is missing.
+func (s *LotteryService) ReconcileDraw(drawID string) (models.Reconciliation, error) {
+	s.changeMu.RLock()
+	defer s.changeMu.RUnlock()
+
+	draw, err := s.draws.GetByID(drawID)
+	if err != nil {
+		return models.Reconciliation{}, err
+	}
+	if draw.Status != "completed" {
+		return models.Reconciliation{}, newError(ErrConflict, "only completed draws can be reconciled")
+	}
+
+	rec := models.Reconciliation{DrawID: draw.ID, Discrepancies: []models.Discrepancy{}}
+	if s.settlements != nil {
+		if st, err := s.settlements.Get(draw.ID); err == nil {
+			rec.Settlement = &st
+		}
+	}
+	awarded := map[string]models.Prize{}
+	for _, p := range s.prizes.List() {
+		awarded[p.TicketID] = p
+	}
+	add := func(t models.Ticket, prizeID, kind, format string, args ...any) {
+		rec.Discrepancies = append(rec.Discrepancies, models.Discrepancy{TicketID: t.ID, PrizeID: prizeID, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	tickets := s.tickets.GetByDrawID(draw.ID)
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
+	rec.Tickets = len(tickets)
+	unmarked := unmarkedPrizes(tickets, awarded)
+	credits := s.recordedCredits(unmarked)
+	for _, t := range tickets {
+		matches := utils.CountMatches(t.Numbers, draw.WinningNumbers)
+		defs := models.PrizeDefinitions[matches]
+		if t.Matches != matches {
+			add(t, "", models.DiscrepancyMatches, "matches %d numbers but records %d", matches, t.Matches)
+		}
+		if len(defs) > 0 {
+			rec.ExpectedWinners++
+		}
+
+		prize, won := awarded[t.ID]
+		switch {
+		case !won && len(defs) > 0:
+			add(t, "", models.DiscrepancyPrizeMissing, "matches %d numbers but has no prize", matches)
+			if len(defs) == 1 && defs[0].Type == models.Money {
+				rec.ExpectedPayout += defs[0].Value
+			}
+		case won && !isPrizeFor(prize, defs):
+			add(t, prize.ID, models.DiscrepancyPrizeWrong, "%s (%d matches) was awarded, but the ticket matches %d", prize.Name, prize.MatchesCount, matches)
+		case won && prize.Type == models.Money:
+			rec.ExpectedPayout += prize.Value
+		}
+		if !won {
+			if t.PrizeID != "" {
+				add(t, t.PrizeID, models.DiscrepancyPrizeLink, "prize_id is %q but no prize was awarded for it", t.PrizeID)
+			}
+			continue
+		}
+
+		rec.ActualWinners++
+		if t.PrizeID != prize.ID {
+			add(t, prize.ID, models.DiscrepancyPrizeLink, "prize_id is %q but prize %s was awarded for it", t.PrizeID, prize.ID)
+		}
+		if prize.Type != models.Money {
+			continue
+		}
+		rec.AwardedPayout += prize.Value
+		if at, ok := credits[prize.ID]; ok && prize.CreditedAt == nil {
+			add(t, prize.ID, models.DiscrepancyCreditMark, "%d was credited to %s at %s but the prize is not marked credited", prize.Value, prize.UserID, at.Format(time.RFC3339))
+			rec.CreditedPayout += prize.Value
+		} else if prize.CreditedAt == nil {
+			add(t, prize.ID, models.DiscrepancyCredit, "%d was not credited to %s", prize.Value, prize.UserID)
+		} else {
+			rec.CreditedPayout += prize.Value
+		}
+	}
+	rec.Balanced = len(rec.Discrepancies) == 0
+	return rec, nil
+}
+
+// isPrizeFor reports whether p is one of the prizes defs can award.
+func isPrizeFor(p models.Prize, defs []models.Prize) bool {
+	for _, d := range defs {
+		if p.Type == d.Type && p.Name == d.Name && p.Value == d.Value {
+			return true
+		}
+	}
+	return false
+}
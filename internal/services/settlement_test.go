@@ -0,0 +1,209 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"LotterySystem/internal/utils"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seedDraw fills s with users and a pending draw holding tickets spread
+// over them, each file in one write, and returns the draw's ID.
+func seedDraw(tb testing.TB, s *LotteryService, tickets, users int) string {
+	tb.Helper()
+	now := time.Now()
+
+	us := make([]models.User, users)
+	for i := range us {
+		us[i] = models.User{ID: s.generateID(utils.UserIDPrefix), Username: fmt.Sprintf("player%d", i), Role: models.RolePlayer, CreatedAt: now}
+	}
+	if err := s.users.SaveAll(us); err != nil {
+		tb.Fatal(err)
+	}
+
+	draw := models.Draw{ID: s.generateID(utils.DrawIDPrefix), WinningNumbers: []int{}, Status: "pending", DrawDate: now, CreatedAt: now}
+	if err := s.draws.Save(draw); err != nil {
+		tb.Fatal(err)
+	}
+
+	ts := make([]models.Ticket, tickets)
+	for i := range ts {
+		ts[i] = models.Ticket{
+			ID:        s.generateID(utils.TicketIDPrefix),
+			Serial:    utils.GenerateSerial(),
+			UserID:    us[i%users].ID,
+			DrawID:    draw.ID,
+			Numbers:   utils.GenerateWinningNumbers(),
+			Price:     models.LegacyTicketPrice,
+			Status:    "active",
+			CreatedAt: now,
+		}
+	}
+	if err := s.tickets.SaveAll(ts); err != nil {
+		tb.Fatal(err)
+	}
+	return draw.ID
+}
+
+// BenchmarkSettle measures executing and settling a draw of each size,
+// with the tickets spread over 1000 users. Each run starts from a freshly
+// seeded data directory; only ExecuteDraw is timed.
+func BenchmarkSettle(b *testing.B) {
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Cleanup(func() { slog.SetDefault(logger) })
+
+	for _, tickets := range []int{10_000, 100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("tickets=%d", tickets), func(b *testing.B) {
+			if testing.Short() && tickets > 100_000 {
+				b.Skip("skipped in short mode")
+			}
+			for range b.N {
+				b.StopTimer()
+				s := newTestService(b)
+				drawID := seedDraw(b, s, tickets, 1000)
+				b.StartTimer()
+
+				if _, err := s.ExecuteDraw(SystemActor, drawID); err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				st, err := s.GetSettlement(drawID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if st.Status != models.SettlementCompleted || st.Processed != tickets {
+					b.Fatalf("settlement %s after %d of %d tickets", st.Status, st.Processed, tickets)
+				}
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(tickets*b.N)/b.Elapsed().Seconds(), "tickets/s")
+		})
+	}
+}
+
+func TestSettlementStopsWhenTicketsAreNotWritten(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestServiceIn(dir)
+	drawID := seedDraw(t, s, 50, 5)
+
+	// A directory in the way of the tickets file fails every write to it.
+	tickets := filepath.Join(dir, storage.TicketsFile)
+	saved, err := os.ReadFile(tickets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(tickets); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(tickets, "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ExecuteDraw(SystemActor, drawID); err != nil {
+		t.Fatal(err)
+	}
+	st, err := s.GetSettlement(drawID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Status != models.SettlementFailed || st.Processed != 0 || !strings.Contains(st.LastError, "not written") {
+		t.Fatalf("settlement = %s after %d tickets (%q), want failed before any", st.Status, st.Processed, st.LastError)
+	}
+	for _, tk := range s.tickets.GetByDrawID(drawID) {
+		if tk.Status != "active" {
+			t.Fatalf("ticket %s is %q in memory but was never written", tk.ID, tk.Status)
+		}
+	}
+
+	if err := os.RemoveAll(tickets); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tickets, saved, 0644); err != nil {
+		t.Fatal(err)
+	}
+	st, err = s.ResettleDraw(SystemActor, drawID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Status != models.SettlementCompleted || st.Processed != 50 || st.Failed != 0 {
+		t.Fatalf("resettlement = %s after %d tickets, %d failed; want all settled", st.Status, st.Processed, st.Failed)
+	}
+
+	// Prizes awarded before the stop are picked up, not awarded again.
+	owed := map[string]int{}
+	for _, p := range s.prizes.List() {
+		if p.Type == models.Money {
+			owed[p.UserID] += p.Value
+		}
+	}
+	for _, u := range s.users.List() {
+		if u.Balance != owed[u.ID] {
+			t.Errorf("user %s has %d, want the %d of their prizes", u.ID, u.Balance, owed[u.ID])
+		}
+	}
+}
+
+func TestResettleDoesNotPayPrizesTheAuditLogShowsCredited(t *testing.T) {
+	s := newTestService(t)
+	drawID := seedDraw(t, s, 50, 5)
+	if _, err := s.ExecuteDraw(SystemActor, drawID); err != nil {
+		t.Fatal(err)
+	}
+
+	// A prize paid but left unmarked, as one credited before credits were
+	// marked would be.
+	var paid models.Prize
+	for _, p := range s.prizes.List() {
+		if p.Type == models.Money {
+			paid = p
+			break
+		}
+	}
+	if paid.ID == "" {
+		t.Fatal("no money prize was awarded")
+	}
+	paid.CreditedAt = nil
+	if err := s.prizes.Update(paid); err != nil {
+		t.Fatal(err)
+	}
+	balances := map[string]int{}
+	for _, u := range s.users.List() {
+		balances[u.ID] = u.Balance
+	}
+
+	rec, err := s.ReconcileDraw(drawID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Discrepancies) != 1 || rec.Discrepancies[0].Kind != models.DiscrepancyCreditMark || rec.Discrepancies[0].PrizeID != paid.ID {
+		t.Fatalf("discrepancies = %+v, want %s for prize %s", rec.Discrepancies, models.DiscrepancyCreditMark, paid.ID)
+	}
+	if rec.CreditedPayout != rec.AwardedPayout {
+		t.Errorf("credited payout = %d, want all %d awarded", rec.CreditedPayout, rec.AwardedPayout)
+	}
+
+	st, err := s.ResettleDraw(SystemActor, drawID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Credited != 0 {
+		t.Errorf("resettlement credited %d prizes, want none", st.Credited)
+	}
+	for _, u := range s.users.List() {
+		if u.Balance != balances[u.ID] {
+			t.Errorf("user %s has %d after resettling, want %d", u.ID, u.Balance, balances[u.ID])
+		}
+	}
+	if p, _ := s.prizes.GetByID(paid.ID); p.CreditedAt == nil {
+		t.Errorf("prize %s is still not marked credited", paid.ID)
+	}
+}
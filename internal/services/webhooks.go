@@ -0,0 +1,488 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"LotterySystem/internal/utils"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = 6 * time.Hour
+	webhookPollInterval = time.Second
+	webhookTimeout      = 10 * time.Second
+	webhookWorkers      = 4
+	// Delivered and dead-lettered deliveries are kept webhookRetention
+	// after their last attempt, checked every webhookPruneInterval.
+	webhookRetention     = 7 * 24 * time.Hour
+	webhookPruneInterval = time.Hour
+)
+
+// Webhook event types that can be subscribed to, besides "*".
+var webhookEvents = map[EventType]bool{
+	EventDrawOpened:     true,
+	EventSalesClosed:    true,
+	EventNumberDrawn:    true,
+	EventDrawSettled:    true,
+	EventSettleProgress: true,
+	EventDrawCancelled:  true,
+	EventPrizeAwarded:   true,
+}
+
+// WebhookDispatcher delivers draw events to registered webhook URLs. Every
+// matching event is first written to the delivery queue, so deliveries that
+// are still pending survive a restart. Failed attempts are retried with
+// exponential backoff; after webhookMaxAttempts the delivery is dead-lettered
+// and only an explicit redelivery will send it again. Deliveries that are
+// done with are pruned webhookRetention after their last attempt.
+//
+// Payloads are signed with the webhook's secret: the X-Lottery-Signature
+// header is "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)),
+// where timestamp is the X-Lottery-Timestamp header.
+type WebhookDispatcher struct {
+	webhooks   *storage.WebhookRepository
+	deliveries *storage.DeliveryRepository
+	events     *EventBus
+	client     *http.Client
+	ids        utils.IDGenerator
+	now        func() time.Time
+	checkHost  func(host string) error
+	running    atomic.Bool
+}
+
+func NewWebhookDispatcher(
+	webhooks *storage.WebhookRepository,
+	deliveries *storage.DeliveryRepository,
+	events *EventBus,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		events:     events,
+		client:     newWebhookClient(),
+		ids:        utils.NewULIDGenerator(),
+		now:        time.Now,
+		checkHost:  checkWebhookHost,
+	}
+}
+
+// newWebhookClient returns a client that refuses to connect to internal
+// addresses, so a host that resolved to a public address when the webhook
+// was created cannot later be pointed at the server's own network. Proxies
+// are not used, since the check only sees the address actually dialled.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || internalIP(ip) {
+				return fmt.Errorf("webhook target %s is not a public address", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: webhookTimeout, Transport: transport}
+}
+
+// checkWebhookHost rejects a webhook host that is, or resolves to, a
+// loopback, link-local, private or unspecified address.
+func checkWebhookHost(host string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("host %s could not be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !internalIP(addr.IP) {
+			continue
+		}
+		if addr.IP.String() == host {
+			return fmt.Errorf("must not point at an internal address (%s)", host)
+		}
+		return fmt.Errorf("must not point at an internal address (%s resolves to %s)", host, addr.IP)
+	}
+	return nil
+}
+
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// SetHTTPClient replaces the client used to send deliveries.
+func (d *WebhookDispatcher) SetHTTPClient(c *http.Client) {
+	d.client = c
+}
+
+// Run enqueues published events until ctx is cancelled, while a pool of
+// webhookWorkers goroutines sends the deliveries that are due, so a slow
+// receiver holds up neither the other webhooks nor the event subscription.
+// The subscription is a queue, so no event is missed; the events waiting
+// are enqueued together, such as all the prizes a settlement batch awards.
+// Events already published are still enqueued before it returns, so they
+// are delivered after the next start.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	events, unsubscribe := d.events.SubscribeQueue()
+	defer unsubscribe()
+
+	d.running.Store(true)
+	defer d.running.Store(false)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.deliver(ctx)
+	}()
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.enqueue(events.Take()...)
+			return
+		case <-events.Ready():
+			d.enqueue(events.Take()...)
+		}
+	}
+}
+
+// Running reports whether Run is working the delivery queue.
+func (d *WebhookDispatcher) Running() bool {
+	return d.running.Load()
+}
+
+func (d *WebhookDispatcher) StorageStatus() []storage.FileStatus {
+	return []storage.FileStatus{d.webhooks.Status(), d.deliveries.Status()}
+}
+
+func (d *WebhookDispatcher) CreateWebhook(rawURL string, events []string, secret string) (models.Webhook, error) {
+	fields := map[string]string{}
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		fields["url"] = "must be an absolute http or https URL"
+	} else if err := d.checkHost(u.Hostname()); err != nil {
+		fields["url"] = err.Error()
+	}
+	if len(events) == 0 {
+		fields["events"] = "is required"
+	}
+	for _, e := range events {
+		if e != "*" && !webhookEvents[EventType(e)] {
+			fields["events"] = fmt.Sprintf("unknown event type %q", e)
+			break
+		}
+	}
+	if len(fields) > 0 {
+		return models.Webhook{}, NewValidationError("invalid webhook", fields)
+	}
+
+	if secret == "" {
+		secret = hex.EncodeToString(randomKey())
+	}
+
+	webhook := models.Webhook{
+		ID:        d.ids.NewID(utils.WebhookIDPrefix),
+		URL:       rawURL,
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: d.now(),
+	}
+	if err := d.webhooks.Save(webhook); err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns the registered webhooks without their secrets.
+func (d *WebhookDispatcher) ListWebhooks() []models.Webhook {
+	webhooks := d.webhooks.List()
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks
+}
+
+func (d *WebhookDispatcher) GetWebhook(id string) (models.Webhook, error) {
+	webhook, err := d.webhooks.GetByID(id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (d *WebhookDispatcher) DeleteWebhook(id string) error {
+	return d.webhooks.Delete(id)
+}
+
+func (d *WebhookDispatcher) QueryDeliveries(webhookID, status string, opts models.ListOptions) (models.Page[models.WebhookDelivery], error) {
+	if _, err := d.webhooks.GetByID(webhookID); err != nil {
+		return models.Page[models.WebhookDelivery]{}, err
+	}
+	page, err := d.deliveries.Query(webhookID, status, opts)
+	return page, queryError(err)
+}
+
+// Redeliver queues a delivery for an immediate attempt and resets its
+// attempt count. It revives dead deliveries, resends delivered ones and
+// skips the backoff wait of pending ones.
+func (d *WebhookDispatcher) Redeliver(id string) (models.WebhookDelivery, error) {
+	delivery, err := d.deliveries.GetByID(id)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery.Status = "pending"
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = d.now()
+	delivery.DeliveredAt = nil
+	if err := d.deliveries.Update(delivery); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// enqueue queues a delivery of each event to every webhook subscribed to
+// it, all in one write.
+func (d *WebhookDispatcher) enqueue(events ...Event) {
+	now := d.now()
+	var deliveries []models.WebhookDelivery
+	for _, e := range events {
+		webhooks := d.webhooks.ListForEvent(string(e.Type))
+		if len(webhooks) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(e)
+		if err != nil {
+			slog.Error("webhook payload could not be encoded", "event", e.Type, "err", err)
+			continue
+		}
+		for _, w := range webhooks {
+			deliveries = append(deliveries, models.WebhookDelivery{
+				ID:            d.ids.NewID(utils.DeliveryIDPrefix),
+				WebhookID:     w.ID,
+				EventType:     string(e.Type),
+				Payload:       payload,
+				Status:        "pending",
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := d.deliveries.SaveAll(deliveries); err != nil {
+		slog.Error("webhook deliveries could not be queued", "events", len(events), "deliveries", len(deliveries), "err", err)
+	}
+}
+
+// prune removes the deliveries finished more than webhookRetention ago.
+func (d *WebhookDispatcher) prune() {
+	n, err := d.deliveries.Prune(d.now().Add(-webhookRetention))
+	if err != nil {
+		slog.Error("old webhook deliveries could not be pruned", "err", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("old webhook deliveries pruned", "deliveries", n)
+	}
+}
+
+// deliver polls the queue and hands the deliveries that are due to
+// webhookWorkers workers until ctx is cancelled, pruning the queue now and
+// then. A delivery is not handed out again while an attempt at it is still
+// running.
+func (d *WebhookDispatcher) deliver(ctx context.Context) {
+	var (
+		mu       sync.Mutex
+		inflight = map[string]bool{}
+		work     = make(chan string)
+		wg       sync.WaitGroup
+	)
+	for range webhookWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range work {
+				d.attemptIfDue(ctx, id)
+				mu.Lock()
+				delete(inflight, id)
+				mu.Unlock()
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(work)
+
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+	pruner := time.NewTicker(webhookPruneInterval)
+	defer pruner.Stop()
+	d.prune()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pruner.C:
+			d.prune()
+			continue
+		case <-ticker.C:
+		}
+		for _, delivery := range d.deliveries.Due(d.now()) {
+			mu.Lock()
+			busy := inflight[delivery.ID]
+			inflight[delivery.ID] = true
+			mu.Unlock()
+			if busy {
+				continue
+			}
+			select {
+			case work <- delivery.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// attemptIfDue attempts a delivery if it is still due. The queue is read
+// before the delivery is handed to a worker, so by then an earlier attempt
+// may have finished and moved it on.
+func (d *WebhookDispatcher) attemptIfDue(ctx context.Context, id string) {
+	delivery, err := d.deliveries.GetByID(id)
+	if err != nil || delivery.Status != "pending" || delivery.NextAttemptAt.After(d.now()) {
+		return
+	}
+	d.attempt(ctx, delivery)
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery models.WebhookDelivery) {
+	webhook, err := d.webhooks.GetByID(delivery.WebhookID)
+	if err != nil {
+		// The webhook was deleted; nothing left to deliver to.
+		delivery.Status = "dead"
+		delivery.LastError = "webhook deleted"
+		d.update(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	status, err := d.send(ctx, webhook, delivery)
+	if err != nil && ctx.Err() != nil {
+		// Cut off by shutdown; the attempt does not count.
+		return
+	}
+	delivery.LastStatusCode = status
+
+	switch {
+	case err == nil:
+		now := d.now()
+		delivery.Status = "delivered"
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = "dead"
+		delivery.LastError = err.Error()
+		slog.Error("webhook delivery given up", "delivery_id", delivery.ID, "webhook_id", webhook.ID,
+			"event", delivery.EventType, "attempts", delivery.Attempts, "err", err)
+	default:
+		delivery.NextAttemptAt = d.now().Add(webhookBackoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+		slog.Warn("webhook delivery failed", "delivery_id", delivery.ID, "webhook_id", webhook.ID,
+			"event", delivery.EventType, "attempts", delivery.Attempts, "retry_at", delivery.NextAttemptAt, "err", err)
+	}
+	d.update(delivery)
+}
+
+// update records the outcome of an attempt. If that fails the delivery is
+// still pending as far as the queue knows, and is attempted again.
+func (d *WebhookDispatcher) update(delivery models.WebhookDelivery) {
+	if err := d.deliveries.Update(delivery); err != nil {
+		slog.Error("webhook delivery could not be updated", "delivery_id", delivery.ID, "status", delivery.Status, "err", err)
+	}
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LotterySystem-Webhooks/1.0")
+	req.Header.Set("X-Lottery-Event", delivery.EventType)
+	req.Header.Set("X-Lottery-Delivery", delivery.ID)
+	req.Header.Set("X-Lottery-Timestamp", timestamp)
+	req.Header.Set("X-Lottery-Signature", SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the X-Lottery-Signature value for a payload.
+// Receivers recompute it to check a delivery came from this server.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature matches the payload.
+func VerifyWebhookSignature(secret, timestamp string, payload []byte, signature string) bool {
+	expected := SignWebhookPayload(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature)))
+}
+
+// webhookBackoff is the wait after the given number of failed attempts:
+// 30s, 1m, 2m, ... capped at webhookMaxBackoff.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/storage"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// receiver is a webhook endpoint that records what it is sent and answers
+// with status.
+type receiver struct {
+	*httptest.Server
+	status   atomic.Int32
+	mu       sync.Mutex
+	requests []received
+}
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T) *receiver {
+	t.Helper()
+	rc := &receiver{}
+	rc.status.Store(http.StatusOK)
+	rc.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.mu.Lock()
+		rc.requests = append(rc.requests, received{header: r.Header.Clone(), body: body})
+		rc.mu.Unlock()
+		w.WriteHeader(int(rc.status.Load()))
+	}))
+	t.Cleanup(rc.Close)
+	return rc
+}
+
+func (rc *receiver) received() []received {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]received(nil), rc.requests...)
+}
+
+// newTestDispatcher returns a dispatcher over a scratch data directory
+// that may deliver to rc, whose clock only moves when the test moves it.
+func newTestDispatcher(t *testing.T, rc *receiver) (*WebhookDispatcher, *time.Time) {
+	t.Helper()
+	dir := t.TempDir()
+	d := NewWebhookDispatcher(storage.NewWebhookRepository(dir), storage.NewDeliveryRepository(dir), NewEventBus())
+	d.SetHTTPClient(rc.Client())
+	d.checkHost = func(string) error { return nil }
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+// queued enqueues a draw.settled event and returns the one delivery it
+// queued.
+func queued(t *testing.T, d *WebhookDispatcher) models.WebhookDelivery {
+	t.Helper()
+	d.enqueue(Event{ID: 1, Type: EventDrawSettled, DrawID: "drw_1", Time: d.now()})
+	due := d.deliveries.Due(d.now())
+	if len(due) != 1 {
+		t.Fatalf("queued %d deliveries, want 1", len(due))
+	}
+	return due[0]
+}
+
+func TestWebhookDeliveryIsSigned(t *testing.T) {
+	rc := newReceiver(t)
+	d, _ := newTestDispatcher(t, rc)
+	webhook, err := d.CreateWebhook(rc.URL, []string{string(EventDrawSettled)}, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := queued(t, d)
+	d.attemptIfDue(context.Background(), delivery.ID)
+
+	reqs := rc.received()
+	if len(reqs) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(reqs))
+	}
+	h, body := reqs[0].header, reqs[0].body
+	if got := h.Get("X-Lottery-Event"); got != string(EventDrawSettled) {
+		t.Errorf("X-Lottery-Event = %q, want %q", got, EventDrawSettled)
+	}
+	if got := h.Get("X-Lottery-Delivery"); got != delivery.ID {
+		t.Errorf("X-Lottery-Delivery = %q, want %q", got, delivery.ID)
+	}
+	timestamp, signature := h.Get("X-Lottery-Timestamp"), h.Get("X-Lottery-Signature")
+	if !VerifyWebhookSignature(webhook.Secret, timestamp, body, signature) {
+		t.Errorf("signature %q does not verify", signature)
+	}
+	if VerifyWebhookSignature("other", timestamp, body, signature) {
+		t.Error("signature verifies with the wrong secret")
+	}
+	if VerifyWebhookSignature(webhook.Secret, timestamp, append(body, ' '), signature) {
+		t.Error("signature verifies for a different body")
+	}
+
+	got, err := d.deliveries.GetByID(delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "delivered" || got.Attempts != 1 || got.DeliveredAt == nil {
+		t.Errorf("delivery = %s after %d attempts (delivered at %v), want delivered after 1", got.Status, got.Attempts, got.DeliveredAt)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{10, 4*time.Hour + 16*time.Minute},
+		{11, webhookMaxBackoff},
+		{50, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookRetriesThenDeadLettersAndRedelivers(t *testing.T) {
+	rc := newReceiver(t)
+	rc.status.Store(http.StatusInternalServerError)
+	d, now := newTestDispatcher(t, rc)
+	if _, err := d.CreateWebhook(rc.URL, []string{"*"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	id := queued(t, d).ID
+
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		d.attemptIfDue(context.Background(), id)
+		delivery, err := d.deliveries.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivery.Attempts != attempt || delivery.LastStatusCode != http.StatusInternalServerError {
+			t.Fatalf("after attempt %d: attempts = %d, last status = %d", attempt, delivery.Attempts, delivery.LastStatusCode)
+		}
+		if attempt == webhookMaxAttempts {
+			if delivery.Status != "dead" {
+				t.Fatalf("after %d attempts status = %s, want dead", attempt, delivery.Status)
+			}
+			break
+		}
+		if delivery.Status != "pending" {
+			t.Fatalf("after attempt %d status = %s, want pending", attempt, delivery.Status)
+		}
+		if want := now.Add(webhookBackoff(attempt)); !delivery.NextAttemptAt.Equal(want) {
+			t.Fatalf("after attempt %d next attempt at %s, want %s", attempt, delivery.NextAttemptAt, want)
+		}
+
+		// Not due yet: a second attempt before the backoff has passed
+		// does nothing.
+		d.attemptIfDue(context.Background(), id)
+		if n := len(rc.received()); n != attempt {
+			t.Fatalf("attempted during backoff: receiver got %d requests, want %d", n, attempt)
+		}
+		*now = delivery.NextAttemptAt
+	}
+
+	// Dead deliveries are never due again on their own.
+	*now = now.Add(24 * time.Hour)
+	if due := d.deliveries.Due(d.now()); len(due) != 0 {
+		t.Fatalf("%d deliveries due after dead-lettering, want 0", len(due))
+	}
+
+	revived, err := d.Redeliver(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revived.Status != "pending" || revived.Attempts != 0 || !revived.NextAttemptAt.Equal(*now) {
+		t.Fatalf("redelivered = %s, %d attempts, next at %s; want pending, 0, %s", revived.Status, revived.Attempts, revived.NextAttemptAt, *now)
+	}
+
+	rc.status.Store(http.StatusNoContent)
+	d.attemptIfDue(context.Background(), id)
+	delivery, err := d.deliveries.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != "delivered" || delivery.Attempts != 1 {
+		t.Fatalf("after redelivery status = %s with %d attempts, want delivered with 1", delivery.Status, delivery.Attempts)
+	}
+	if n := len(rc.received()); n != webhookMaxAttempts+1 {
+		t.Fatalf("receiver got %d requests, want %d", n, webhookMaxAttempts+1)
+	}
+}
+
+func TestWebhookRunDeliversPublishedEvents(t *testing.T) {
+	rc := newReceiver(t)
+	d, _ := newTestDispatcher(t, rc)
+	d.now = time.Now
+	webhook, err := d.CreateWebhook(rc.URL, []string{string(EventDrawOpened)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.CreateWebhook(rc.URL, []string{string(EventDrawCancelled)}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	for !d.Running() {
+		time.Sleep(time.Millisecond)
+	}
+	d.events.Publish(EventDrawOpened, "drw_1", nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rc.received()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	reqs := rc.received()
+	if len(reqs) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(reqs))
+	}
+	h := reqs[0].header
+	if got := h.Get("X-Lottery-Event"); got != string(EventDrawOpened) {
+		t.Errorf("X-Lottery-Event = %q, want %q", got, EventDrawOpened)
+	}
+	if !VerifyWebhookSignature(webhook.Secret, h.Get("X-Lottery-Timestamp"), reqs[0].body, h.Get("X-Lottery-Signature")) {
+		t.Error("signature does not verify")
+	}
+	if d.Running() {
+		t.Error("Running after Run returned")
+	}
+}
+
+func TestWebhookRejectsInternalTargets(t *testing.T) {
+	dir := t.TempDir()
+	d := NewWebhookDispatcher(storage.NewWebhookRepository(dir), storage.NewDeliveryRepository(dir), NewEventBus())
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.10/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := d.CreateWebhook(target, []string{"*"}, "")
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("CreateWebhook(%s) error = %v, want a validation error", target, err)
+		}
+	}
+
+	// A webhook whose host later resolves to an internal address is
+	// still refused when it is dialled.
+	rc := newReceiver(t)
+	d.checkHost = func(string) error { return nil }
+	webhook, err := d.CreateWebhook(rc.URL, []string{"*"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.send(context.Background(), webhook, models.WebhookDelivery{ID: "dlv_1"}); err == nil {
+		t.Error("default client delivered to a loopback address")
+	}
+	if n := len(rc.received()); n != 0 {
+		t.Errorf("receiver got %d requests, want 0", n)
+	}
+}
+
+func TestWebhookRunQueuesEveryEvent(t *testing.T) {
+	rc := newReceiver(t)
+	d, _ := newTestDispatcher(t, rc)
+	d.now = time.Now
+	webhook, err := d.CreateWebhook(rc.URL, []string{string(EventPrizeAwarded)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stop the workers from sending, so only the queueing is measured.
+	d.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	for !d.Running() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Far more than a buffered subscription would hold.
+	const events = 5000
+	data := make([]interface{}, events)
+	for i := range data {
+		data[i] = map[string]int{"n": i}
+	}
+	d.events.PublishAll(EventPrizeAwarded, "drw_1", data)
+
+	deadline := time.Now().Add(5 * time.Second)
+	var queued int
+	for time.Now().Before(deadline) {
+		page, err := d.deliveries.Query(webhook.ID, "", models.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if queued = len(page.Items); queued == events {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if queued != events {
+		t.Fatalf("%d deliveries queued, want %d", queued, events)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWebhookPrunesFinishedDeliveries(t *testing.T) {
+	rc := newReceiver(t)
+	d, now := newTestDispatcher(t, rc)
+	old := now.Add(-webhookRetention - time.Hour)
+	recent := now.Add(-time.Hour)
+	deliveries := []models.WebhookDelivery{
+		{ID: "dlv_delivered_old", Status: "delivered", DeliveredAt: &old, NextAttemptAt: old},
+		{ID: "dlv_delivered_recent", Status: "delivered", DeliveredAt: &recent, NextAttemptAt: old},
+		{ID: "dlv_dead_old", Status: "dead", NextAttemptAt: old},
+		{ID: "dlv_dead_recent", Status: "dead", NextAttemptAt: recent},
+		{ID: "dlv_pending_old", Status: "pending", NextAttemptAt: old},
+	}
+	if err := d.deliveries.SaveAll(deliveries); err != nil {
+		t.Fatal(err)
+	}
+
+	d.prune()
+	for _, dl := range deliveries {
+		_, err := d.deliveries.GetByID(dl.ID)
+		kept := err == nil
+		if want := dl.ID != "dlv_delivered_old" && dl.ID != "dlv_dead_old"; kept != want {
+			t.Errorf("%s kept = %v, want %v", dl.ID, kept, want)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package storage
+
+import (
+	"LotterySystem/internal/models"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const auditFile = "audit.jsonl"
+
+// maxAuditLine bounds one entry when reading the log back.
+const maxAuditLine = 4 << 20
+
+var auditSortKeys = map[string]sortKey[models.AuditEntry]{
+	"seq": func(e models.AuditEntry) string { return intKey(e.Seq) },
+}
+
+// AuditLog is an append-only JSON Lines file of hash-chained entries.
+// Unlike the other repositories it is never rewritten: each entry is
+// appended and synced on its own.
+type AuditLog struct {
+	mu   sync.RWMutex
+	file dataFile
+	seq  int
+	head string
+}
+
+func NewAuditLog(dataDir string) *AuditLog {
+	l := &AuditLog{file: dataFile{path: filepath.Join(dataDir, auditFile), perm: 0600}}
+	l.load()
+	return l
+}
+
+// load finds where the chain ends so appends continue it. It trusts the
+// last entry; Verify is what checks the chain.
+func (l *AuditLog) load() {
+	err := l.scan(func(_ int, e models.AuditEntry) error {
+		l.seq = e.Seq
+		l.head = e.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		l.file.loadErr = err
+		slog.Error("storage: audit log not loaded", "file", l.file.path, "err", err)
+	}
+}
+
+// Append records an action. before and after are stored as JSON and may be
+// nil.
+func (l *AuditLog) Append(actor, action, target string, before, after any) (models.AuditEntry, error) {
+	entries, err := l.AppendAll([]AuditRecord{{Actor: actor, Action: action, Target: target, Before: before, After: after}})
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	return entries[0], nil
+}
+
+// AuditRecord is one action for AppendAll.
+type AuditRecord struct {
+	Actor, Action, Target string
+	Before, After         any
+}
+
+// AppendAll records actions in order with a single write and sync, for
+// changes that make many at once. Either all of them are recorded or none.
+func (l *AuditLog) AppendAll(records []AuditRecord) ([]models.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file.loadErr != nil {
+		// Appending to a log we could not read would start a second chain.
+		return nil, fmt.Errorf("audit log not loaded: %w", l.file.loadErr)
+	}
+
+	entries := make([]models.AuditEntry, 0, len(records))
+	var lines []byte
+	seq, head := l.seq, l.head
+	now := time.Now().UTC()
+	for _, r := range records {
+		e := models.AuditEntry{
+			Seq:      seq + 1,
+			Time:     now,
+			Actor:    r.Actor,
+			Action:   r.Action,
+			Target:   r.Target,
+			PrevHash: head,
+		}
+		var err error
+		if e.Before, err = rawJSON(r.Before); err != nil {
+			return nil, err
+		}
+		if e.After, err = rawJSON(r.After); err != nil {
+			return nil, err
+		}
+		if e.Hash, err = hashAuditEntry(e); err != nil {
+			return nil, err
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(append(lines, line...), '\n')
+		entries = append(entries, e)
+		seq, head = e.Seq, e.Hash
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	if err := l.appendLine(lines); err != nil {
+		l.file.writeErr = err
+		return nil, err
+	}
+	l.file.lastWrite = time.Now()
+	l.file.writeErr = nil
+	l.seq = seq
+	l.head = head
+	return entries, nil
+}
+
+func (l *AuditLog) appendLine(line []byte) error {
+	f, err := os.OpenFile(l.file.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, l.file.perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (l *AuditLog) Query(f models.AuditFilter) (models.Page[models.AuditEntry], error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries := []models.AuditEntry{}
+	err := l.scan(func(_ int, e models.AuditEntry) error {
+		if f.Actor != "" && e.Actor != f.Actor {
+			return nil
+		}
+		if f.Action != "" && e.Action != f.Action {
+			return nil
+		}
+		if f.Target != "" && e.Target != f.Target {
+			return nil
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return models.Page[models.AuditEntry]{}, err
+	}
+	return paginate(entries, f.ListOptions, "seq", auditSortKeys, func(e models.AuditEntry) string { return strconv.Itoa(e.Seq) })
+}
+
+// Verify walks the file and checks that every entry follows the one
+// before it and still hashes to its recorded hash.
+func (l *AuditLog) Verify() (models.AuditVerification, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	f, err := os.Open(l.file.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return models.AuditVerification{OK: true}, nil
+	} else if err != nil {
+		return models.AuditVerification{}, err
+	}
+	defer f.Close()
+	return VerifyAudit(f)
+}
+
+// VerifyAudit checks the chain of an audit log read from r, such as a copy
+// in a backup.
+func VerifyAudit(r io.Reader) (models.AuditVerification, error) {
+	var v models.AuditVerification
+	broken := func(line int, reason string) error {
+		v.BrokenAt = line
+		v.Reason = reason
+		return errBrokenChain
+	}
+
+	err := scanAudit(r, func(line int, e models.AuditEntry) error {
+		if e.Seq != v.Entries+1 {
+			return broken(line, fmt.Sprintf("sequence number %d, expected %d", e.Seq, v.Entries+1))
+		}
+		if e.PrevHash != v.Head {
+			return broken(line, "previous hash does not match the entry before it")
+		}
+		hash, err := hashAuditEntry(e)
+		if err != nil || hash != e.Hash {
+			return broken(line, "entry was modified after it was written")
+		}
+		v.Entries++
+		v.Head = e.Hash
+		return nil
+	})
+	var bad *badAuditLine
+	switch {
+	case errors.Is(err, errBrokenChain):
+		return v, nil
+	case errors.As(err, &bad):
+		broken(bad.line, "not a valid entry: "+bad.err.Error())
+		return v, nil
+	case err != nil:
+		return models.AuditVerification{}, err
+	}
+	v.OK = true
+	return v, nil
+}
+
+var errBrokenChain = errors.New("broken audit chain")
+
+type badAuditLine struct {
+	line int
+	err  error
+}
+
+func (e *badAuditLine) Error() string {
+	return fmt.Sprintf("audit log line %d: %v", e.line, e.err)
+}
+
+// scan calls fn for every entry in the file with its line number, counted
+// from 1. A line that is not a valid entry ends the scan with a
+// *badAuditLine error.
+func (l *AuditLog) scan(fn func(line int, e models.AuditEntry) error) error {
+	f, err := os.Open(l.file.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return scanAudit(f, fn)
+}
+
+func scanAudit(rd io.Reader, fn func(line int, e models.AuditEntry) error) error {
+	r := bufio.NewReaderSize(rd, 64<<10)
+	for line := 1; ; line++ {
+		data, err := readLine(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var e models.AuditEntry
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&e); err != nil {
+			return &badAuditLine{line: line, err: err}
+		}
+		if err := fn(line, e); err != nil {
+			return err
+		}
+	}
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxAuditLine {
+			return nil, fmt.Errorf("audit entry longer than %d bytes", maxAuditLine)
+		}
+		switch {
+		case err == nil:
+			return bytes.TrimSuffix(line, []byte("\n")), nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(line) > 0:
+			// A last line without a newline was cut short while writing.
+			return line, nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+func (l *AuditLog) Status() FileStatus {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.file.status(l.seq)
+}
+
+// hashAuditEntry is the hex SHA-256 of the entry's JSON with Hash left
+// empty. Field order is fixed by the struct, and Before and After are
+// compacted when encoded, so the result is the same when the entry is read
+// back.
+func hashAuditEntry(e models.AuditEntry) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func rawJSON(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -2,52 +2,75 @@ package storage
 
 import (
 	"LotterySystem/internal/models"
-	"encoding/json"
-	"errors"
-	"os"
+	"fmt"
+	"path/filepath"
 	"sync"
 )
 
-const drawFile = "data/draws.json"
+const drawFile = "draws.json"
 
 type DrawRepository struct {
-	mu sync.RWMutex
-	db map[string]models.Draw
+	mu   sync.RWMutex
+	db   map[string]models.Draw
+	file dataFile
 }
 
-func NewDrawRepository() *DrawRepository {
+func NewDrawRepository(dataDir string) *DrawRepository {
 	r := &DrawRepository{
-		db: make(map[string]models.Draw),
+		db:   make(map[string]models.Draw),
+		file: dataFile{path: filepath.Join(dataDir, drawFile), perm: 0644},
 	}
 	r.load()
 	return r
 }
 
 func (r *DrawRepository) load() {
-	data, err := os.ReadFile(drawFile)
-	if err == nil {
-		_ = json.Unmarshal(data, &r.db)
-	}
+	r.file.read(&r.db)
+}
+
+func (r *DrawRepository) save(op string, args ...any) error {
+	return r.file.write(r.db, op, args...)
 }
 
-func (r *DrawRepository) save() {
-	data, _ := json.MarshalIndent(r.db, "", "  ")
-	_ = os.WriteFile(drawFile, data, 0644)
+func (r *DrawRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.flush(r.db)
+}
+
+func (r *DrawRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.file.status(len(r.db))
 }
 
 func (r *DrawRepository) Save(d models.Draw) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if _, exists := r.db[d.ID]; exists {
+		return fmt.Errorf("draw %w", ErrAlreadyExists)
+	}
+	restore := remember(r.db, d.ID)
 	r.db[d.ID] = d
-	r.save()
+	if err := r.save("save", "draw_id", d.ID); err != nil {
+		restore()
+		return err
+	}
 	return nil
 }
 
 func (r *DrawRepository) Update(d models.Draw) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if _, ok := r.db[d.ID]; !ok {
+		return fmt.Errorf("draw %w", ErrNotFound)
+	}
+	restore := remember(r.db, d.ID)
 	r.db[d.ID] = d
-	r.save()
+	if err := r.save("update", "draw_id", d.ID); err != nil {
+		restore()
+		return err
+	}
 	return nil
 }
 
@@ -56,7 +79,7 @@ func (r *DrawRepository) GetByID(id string) (models.Draw, error) {
 	defer r.mu.RUnlock()
 	d, ok := r.db[id]
 	if !ok {
-		return models.Draw{}, errors.New("draw not found")
+		return models.Draw{}, fmt.Errorf("draw %w", ErrNotFound)
 	}
 	return d, nil
 }
@@ -69,7 +92,7 @@ func (r *DrawRepository) GetPending() (models.Draw, error) {
 			return d, nil
 		}
 	}
-	return models.Draw{}, errors.New("no pending draw")
+	return models.Draw{}, fmt.Errorf("pending draw %w", ErrNotFound)
 }
 
 func (r *DrawRepository) List() []models.Draw {
@@ -81,3 +104,43 @@ func (r *DrawRepository) List() []models.Draw {
 	}
 	return res
 }
+
+var drawSortKeys = map[string]sortKey[models.Draw]{
+	"created_at": func(d models.Draw) string { return timeKey(d.CreatedAt) },
+	"draw_date":  func(d models.Draw) string { return timeKey(d.DrawDate) },
+}
+
+func (r *DrawRepository) Query(f models.DrawFilter) (models.Page[models.Draw], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := []models.Draw{}
+	for _, d := range r.db {
+		if f.Status != "" && d.Status != f.Status {
+			continue
+		}
+		if !inRange(d.DrawDate, f.From, f.To) {
+			continue
+		}
+		res = append(res, d)
+	}
+	return paginate(res, f.ListOptions, "created_at", drawSortKeys, func(d models.Draw) string { return d.ID })
+}
+
+// Scan returns the IDs of matching draws ordered by draw date. From and To
+// bound the draw date, as in Query.
+func (r *DrawRepository) Scan(f models.ExportFilter) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []timedID
+	for _, d := range r.db {
+		if f.DrawID != "" && d.ID != f.DrawID {
+			continue
+		}
+		if !inRange(d.DrawDate, f.From, f.To) {
+			continue
+		}
+		entries = append(entries, timedID{at: d.DrawDate, id: d.ID})
+	}
+	return sortedIDs(entries)
+}
@@ -0,0 +1,13 @@
+package storage
+
+import "errors"
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrNegativeBalance = errors.New("balance would go negative")
+	ErrStatusChanged   = errors.New("status has changed")
+	// ErrNotWritten is wrapped by every failure to write a data file, so
+	// callers can tell the disk failing from a record being refused.
+	ErrNotWritten = errors.New("not written")
+)
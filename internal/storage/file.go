@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"LotterySystem/internal/metrics"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var writeSeconds = metrics.NewHistogramVec("lottery_storage_write_seconds",
+	"Time taken to write a repository's data file, by repository.", nil, "repository")
+
+// Flusher is implemented by every repository. Changes are written to disk
+// as they happen, so Flush only waits for a write in progress and rewrites
+// the file, reporting whether what is on disk is current. It is meant for
+// shutdown, once nothing else is writing.
+type Flusher interface {
+	Flush() error
+}
+
+// writeFile stores records as indented JSON, stamped with the file's
+// schema version. It writes a temporary file next to path and renames it
+// into place, so a crash or kill mid-write leaves the previous version
+// intact instead of a truncated file.
+func writeFile(path string, records any, perm os.FileMode) error {
+	defer writeSeconds.With(strings.TrimSuffix(filepath.Base(path), ".json")).Since(time.Now())
+
+	data, err := encodeFile(filepath.Base(path), records)
+	if err != nil {
+		return err
+	}
+	return replaceFile(path, data, perm)
+}
+
+// storedFile is the layout of a data file. Files written before schema
+// versions existed are the bare records, and are version 0.
+type storedFile struct {
+	SchemaVersion int `json:"schema_version"`
+	Records       any `json:"records"`
+}
+
+func encodeFile(name string, records any) ([]byte, error) {
+	return json.MarshalIndent(storedFile{SchemaVersion: schemaVersions[name], Records: records}, "", "  ")
+}
+
+// DecodeFile decodes the records in a data file's contents into v and
+// returns the schema version they were written with. An empty file has no
+// records.
+func DecodeFile(data []byte, v any) (int, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return 0, nil
+	}
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return 0, err
+	}
+	version, ok := top["schema_version"]
+	if !ok {
+		return 0, json.Unmarshal(data, v)
+	}
+	var n int
+	if err := json.Unmarshal(version, &n); err != nil {
+		return 0, fmt.Errorf("schema_version: %w", err)
+	}
+	records, ok := top["records"]
+	if !ok {
+		return n, errors.New("records are missing")
+	}
+	return n, json.Unmarshal(records, v)
+}
+
+// SchemaError is the load error of a file written with a schema version
+// other than the one this build reads. Such a file is never written over:
+// an older one needs migrating first, and a newer one would lose whatever
+// this build does not know about.
+type SchemaError struct {
+	File    string
+	Version int
+	Want    int
+}
+
+func (e *SchemaError) Error() string {
+	if e.Version > e.Want {
+		return fmt.Sprintf("%s has schema version %d, newer than the %d this build reads", e.File, e.Version, e.Want)
+	}
+	return fmt.Sprintf("%s has schema version %d, migrate it to %d first", e.File, e.Version, e.Want)
+}
+
+// replaceFile puts data at path through a synced temporary file.
+func replaceFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// dataFile is the JSON file behind a repository. It remembers how the last
+// load and write went so the health endpoints can report on it. Callers
+// hold the repository lock.
+type dataFile struct {
+	path      string
+	perm      os.FileMode
+	loadErr   error
+	lastWrite time.Time
+	writeErr  error
+}
+
+// FileStatus describes one repository's data file.
+type FileStatus struct {
+	File       string     `json:"file"`
+	Records    int        `json:"records"`
+	LoadError  string     `json:"load_error,omitempty"`
+	LastWrite  *time.Time `json:"last_write,omitempty"`
+	WriteError string     `json:"write_error,omitempty"`
+}
+
+// read decodes the file into v. A missing file is an empty repository; a
+// file that exists but cannot be read leaves the repository without that
+// data, so it is logged and reported until a write replaces the file. A
+// file of another schema version is loaded but not written over: every
+// write to it fails with its SchemaError.
+func (f *dataFile) read(v any) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err == nil {
+		var version int
+		version, err = DecodeFile(data, v)
+		name := filepath.Base(f.path)
+		if want := schemaVersions[name]; err == nil && version != want {
+			err = &SchemaError{File: name, Version: version, Want: want}
+		}
+	}
+	if err != nil {
+		f.loadErr = err
+		slog.Error("storage: data file not loaded", "file", f.path, "err", err)
+	}
+}
+
+// write stores v, logging and returning a failure. Callers undo the change
+// in memory when it fails, so what is served is always what is on disk.
+func (f *dataFile) write(v any, op string, args ...any) error {
+	if err := f.flush(v); err != nil {
+		args = append([]any{"file", f.path, "op", op, "err", err}, args...)
+		slog.Error("storage: data file not written", args...)
+		return fmt.Errorf("%s %w: %w", filepath.Base(f.path), ErrNotWritten, err)
+	}
+	return nil
+}
+
+// remember returns a function that puts the records with the given IDs
+// back as they are now, to undo a change whose write failed.
+func remember[T any](db map[string]T, ids ...string) func() {
+	old := make(map[string]T, len(ids))
+	for _, id := range ids {
+		if v, ok := db[id]; ok {
+			old[id] = v
+		}
+	}
+	return func() {
+		for _, id := range ids {
+			if v, ok := old[id]; ok {
+				db[id] = v
+			} else {
+				delete(db, id)
+			}
+		}
+	}
+}
+
+func (f *dataFile) flush(v any) error {
+	var serr *SchemaError
+	if errors.As(f.loadErr, &serr) {
+		return serr
+	}
+	if err := writeFile(f.path, v, f.perm); err != nil {
+		f.writeErr = err
+		return err
+	}
+	f.lastWrite = time.Now()
+	f.writeErr = nil
+	return nil
+}
+
+func (f *dataFile) status(records int) FileStatus {
+	st := FileStatus{File: filepath.Base(f.path), Records: records}
+	if f.loadErr != nil {
+		st.LoadError = f.loadErr.Error()
+	}
+	if !f.lastWrite.IsZero() {
+		t := f.lastWrite
+		st.LastWrite = &t
+	}
+	if f.writeErr != nil {
+		st.WriteError = f.writeErr.Error()
+	}
+	return st
+}
+
+// CheckWritable creates and removes a file in dir, to catch a data
+// directory that has become read-only or full before a real write fails.
+func CheckWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// DirSize adds up the size of the files in dir.
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"LotterySystem/internal/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const idempotencyDir = "idempotency"
+
+// IdempotencyRepository keeps the responses to requests with an
+// Idempotency-Key, so retries are still recognised after a restart. Every
+// keyed request stores one, so unlike the other repositories each response
+// is a file of its own in the idempotency directory, and a request writes
+// only its own. The bodies can hold session tokens, so the files are
+// private.
+type IdempotencyRepository struct {
+	mu sync.RWMutex
+	db map[string]models.IdempotentResponse
+	// dir is the directory, with how its last load and write went.
+	dir dataFile
+}
+
+func NewIdempotencyRepository(dataDir string) *IdempotencyRepository {
+	r := &IdempotencyRepository{
+		db:  make(map[string]models.IdempotentResponse),
+		dir: dataFile{path: filepath.Join(dataDir, idempotencyDir), perm: 0600},
+	}
+	r.load()
+	return r
+}
+
+// entryPath is the file of key, named by its hash since keys are chosen by
+// callers.
+func (r *IdempotencyRepository) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(r.dir.path, hex.EncodeToString(sum[:])+".json")
+}
+
+// load reads every response file. One that cannot be read is logged and
+// reported, and the rest are still loaded.
+func (r *IdempotencyRepository) load() {
+	entries, err := os.ReadDir(r.dir.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		r.dir.loadErr = err
+		slog.Error("storage: idempotency responses not loaded", "dir", r.dir.path, "err", err)
+		return
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".json") || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(r.dir.path, e.Name())
+		var resp models.IdempotentResponse
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(data, &resp)
+		}
+		if err != nil {
+			r.dir.loadErr = fmt.Errorf("%s: %w", e.Name(), err)
+			slog.Error("storage: idempotency response not loaded", "file", path, "err", err)
+			continue
+		}
+		r.db[resp.Key] = resp
+	}
+}
+
+// put writes resp to its file. The caller holds r.mu.
+func (r *IdempotencyRepository) put(resp models.IdempotentResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir.path, 0700); err != nil {
+		return err
+	}
+	return replaceFile(r.entryPath(resp.Key), data, r.dir.perm)
+}
+
+// wrote records how a write went, logging and returning a failure the way
+// dataFile.write does.
+func (r *IdempotencyRepository) wrote(err error, op string, args ...any) error {
+	if err != nil {
+		r.dir.writeErr = err
+		args = append([]any{"dir", r.dir.path, "op", op, "err", err}, args...)
+		slog.Error("storage: idempotency response not written", args...)
+		return fmt.Errorf("%s %w: %w", idempotencyDir, ErrNotWritten, err)
+	}
+	r.dir.lastWrite = time.Now()
+	r.dir.writeErr = nil
+	return nil
+}
+
+// Flush rewrites every response if the last write failed; otherwise they
+// are all on disk already.
+func (r *IdempotencyRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dir.writeErr == nil {
+		return nil
+	}
+	var errs []error
+	for _, resp := range r.db {
+		errs = append(errs, r.put(resp))
+	}
+	return r.wrote(errors.Join(errs...), "flush")
+}
+
+func (r *IdempotencyRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.dir.status(len(r.db))
+}
+
+// Put stores resp, replacing any response kept under its key.
+func (r *IdempotencyRepository) Put(resp models.IdempotentResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.wrote(r.put(resp), "put"); err != nil {
+		return err
+	}
+	r.db[resp.Key] = resp
+	return nil
+}
+
+// Delete drops the responses kept under keys. A response whose file could
+// not be removed is kept, so what is served is what is on disk.
+func (r *IdempotencyRepository) Delete(keys ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for _, k := range keys {
+		if err := os.Remove(r.entryPath(k)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		delete(r.db, k)
+	}
+	return r.wrote(errors.Join(errs...), "delete", "keys", len(keys))
+}
+
+func (r *IdempotencyRepository) List() []models.IdempotentResponse {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]models.IdempotentResponse, 0, len(r.db))
+	for _, resp := range r.db {
+		res = append(res, resp)
+	}
+	return res
+}
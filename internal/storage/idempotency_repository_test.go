@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"LotterySystem/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyRepositoryKeepsAFilePerResponse(t *testing.T) {
+	dir := t.TempDir()
+	r := NewIdempotencyRepository(dir)
+	expires := time.Now().Add(time.Hour).UTC().Round(0)
+	for _, key := range []string{"admin k1", "admin k2", "usr_1 ../k3"} {
+		if err := r.Put(models.IdempotentResponse{Key: key, Status: 201, Body: []byte(key), ExpiresAt: expires}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Delete("admin k2"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := os.ReadDir(filepath.Join(dir, idempotencyDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("%d response files, want 2", len(files))
+	}
+
+	reopened := NewIdempotencyRepository(dir)
+	got := map[string]string{}
+	for _, resp := range reopened.List() {
+		got[resp.Key] = string(resp.Body)
+	}
+	if len(got) != 2 || got["admin k1"] != "admin k1" || got["usr_1 ../k3"] != "usr_1 ../k3" {
+		t.Fatalf("reopened responses = %v, want admin k1 and usr_1 ../k3", got)
+	}
+	if st := reopened.Status(); st.LoadError != "" || st.Records != 2 {
+		t.Fatalf("status = %+v, want 2 records loaded", st)
+	}
+}
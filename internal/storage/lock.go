@@ -0,0 +1,65 @@
+//go:build unix
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// LockFile is the file in a data directory that the process using it
+// holds a lock on.
+const LockFile = "lottery.lock"
+
+// ErrLocked is returned by LockDataDir when another process has the data
+// directory open.
+var ErrLocked = errors.New("data directory is in use")
+
+// DirLock is a held lock on a data directory.
+type DirLock struct {
+	f *os.File
+}
+
+// LockDataDir takes the lock on dataDir that the server holds while it
+// runs, so two processes never write the same data files: each keeps its
+// records in memory and would overwrite the other's changes. It fails with
+// ErrLocked, naming the holder's process ID, if another process has it.
+// The lock goes with the process, so one that crashed leaves none behind.
+func LockDataDir(dataDir string) (*DirLock, error) {
+	path := filepath.Join(dataDir, LockFile)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("lock data directory: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%s: %w%s", dataDir, ErrLocked, holder(path))
+		}
+		return nil, fmt.Errorf("lock data directory: %w", err)
+	}
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &DirLock{f: f}, nil
+}
+
+// holder describes the process that wrote its ID to the lock file.
+func holder(path string) string {
+	data, err := os.ReadFile(path)
+	if pid := strings.TrimSpace(string(data)); err == nil && pid != "" {
+		return " by process " + pid
+	}
+	return ""
+}
+
+// Unlock releases the lock. The file is left in place: removing it could
+// let a process that has just opened it lock a file no one else sees.
+func (l *DirLock) Unlock() error {
+	return l.f.Close()
+}
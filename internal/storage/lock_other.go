@@ -0,0 +1,21 @@
+//go:build !unix
+
+package storage
+
+import "errors"
+
+const LockFile = "lottery.lock"
+
+var ErrLocked = errors.New("data directory is in use")
+
+type DirLock struct{}
+
+// LockDataDir does not lock anything on this platform; run only one
+// process against a data directory at a time.
+func LockDataDir(dataDir string) (*DirLock, error) {
+	return &DirLock{}, nil
+}
+
+func (l *DirLock) Unlock() error {
+	return nil
+}
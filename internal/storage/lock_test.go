@@ -0,0 +1,27 @@
+//go:build unix
+
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLockDataDir(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := LockDataDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LockDataDir(dir); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second lock = %v, want ErrLocked", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	again, err := LockDataDir(dir)
+	if err != nil {
+		t.Fatalf("lock after unlock = %v", err)
+	}
+	again.Unlock()
+}
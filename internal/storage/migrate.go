@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"LotterySystem/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// schemaVersions is the version each data file is written with. Raise a
+// file's version when a change to its model needs the stored records
+// rewritten, and add the migration that rewrites them to migrations.
+var schemaVersions = map[string]int{
+	userFile:       1,
+	drawFile:       1,
+	ticketFile:     1,
+	prizeFile:      2,
+	settlementFile: 1,
+	webhookFile:    1,
+	deliveryFile:   1,
+}
+
+// SchemaVersion is the version this build writes the named data file with.
+func SchemaVersion(name string) int {
+	return schemaVersions[name]
+}
+
+// storedTypes decodes each data file the way its repository does, so a
+// migrated file is checked against the model before it is written.
+var storedTypes = map[string]func() any{
+	userFile:       func() any { return &map[string]models.User{} },
+	drawFile:       func() any { return &map[string]models.Draw{} },
+	ticketFile:     func() any { return &map[string]models.Ticket{} },
+	prizeFile:      func() any { return &map[string]models.Prize{} },
+	settlementFile: func() any { return &map[string]models.Settlement{} },
+	webhookFile:    func() any { return &map[string]models.Webhook{} },
+	deliveryFile:   func() any { return &map[string]models.WebhookDelivery{} },
+}
+
+// migrationOrder is the order files are checked and written in.
+var migrationOrder = []string{userFile, drawFile, ticketFile, prizeFile, settlementFile, webhookFile, deliveryFile}
+
+// migration brings one file to version from the version before. apply
+// changes the records in place and returns how many it changed; it can
+// read the other files, which are at whatever version the migrations
+// before it in the list left them.
+type migration struct {
+	file    string
+	version int
+	about   string
+	apply   func(d migrationData) int
+}
+
+// migrationData holds every data file's records as raw fields, so a
+// migration can tell a field that is missing from one that is zero.
+type migrationData map[string]map[string]rawRecord
+
+type rawRecord map[string]json.RawMessage
+
+// empty reports whether field is missing, null, "", 0 or the zero time,
+// which builds that had the field but not the value wrote.
+func (r rawRecord) empty(field string) bool {
+	switch string(r[field]) {
+	case "", "null", `""`, "0", `"0001-01-01T00:00:00Z"`:
+		return true
+	}
+	return false
+}
+
+func (r rawRecord) set(field string, v any) {
+	data, _ := json.Marshal(v)
+	r[field] = data
+}
+
+func (r rawRecord) string(field string) string {
+	var s string
+	json.Unmarshal(r[field], &s)
+	return s
+}
+
+// FileVersion is the schema version a data file is at and the one this
+// build writes.
+type FileVersion struct {
+	File    string `json:"file"`
+	Version int    `json:"version"`
+	Current int    `json:"current"`
+}
+
+// MigrationStep is a migration that ran, or would run.
+type MigrationStep struct {
+	File    string `json:"file"`
+	Version int    `json:"version"`
+	About   string `json:"about"`
+	Changed int    `json:"changed"`
+}
+
+type MigrationReport struct {
+	DryRun bool            `json:"dry_run"`
+	Files  []FileVersion   `json:"files"`
+	Steps  []MigrationStep `json:"steps"`
+}
+
+// Pending reports whether any file is behind.
+func (r MigrationReport) Pending() bool {
+	for _, f := range r.Files {
+		if f.Version < f.Current {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaVersions returns the version of every data file in dataDir that
+// exists. It fails if one cannot be read or is newer than this build.
+func SchemaVersions(dataDir string) ([]FileVersion, error) {
+	_, versions, err := readForMigration(dataDir)
+	return versions, err
+}
+
+// CheckSchema fails with a *SchemaError if a data file in dataDir is at
+// another schema version than this build writes. A repository loads such a
+// file but never writes it, so its changes would only live in memory;
+// nothing should open the data directory until it is migrated.
+func CheckSchema(dataDir string) error {
+	versions, err := SchemaVersions(dataDir)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v.Version != v.Current {
+			return &SchemaError{File: v.File, Version: v.Version, Want: v.Current}
+		}
+	}
+	return nil
+}
+
+// Migrate brings every data file in dataDir to the current schema version,
+// running the migrations each needs in order. With dryRun it reports what
+// would change without writing anything. The server must not be running.
+func Migrate(dataDir string, dryRun bool) (MigrationReport, error) {
+	d, versions, err := readForMigration(dataDir)
+	report := MigrationReport{DryRun: dryRun, Files: versions, Steps: []MigrationStep{}}
+	if err != nil || !report.Pending() {
+		return report, err
+	}
+
+	from := map[string]int{}
+	for _, v := range versions {
+		from[v.File] = v.Version
+	}
+	for _, m := range migrations {
+		if _, ok := from[m.file]; !ok || m.version <= from[m.file] {
+			continue
+		}
+		report.Steps = append(report.Steps, MigrationStep{File: m.file, Version: m.version, About: m.about, Changed: m.apply(d)})
+	}
+
+	encoded := map[string][]byte{}
+	for _, v := range versions {
+		if v.Version == v.Current {
+			continue
+		}
+		raw, err := json.Marshal(d[v.File])
+		if err != nil {
+			return report, err
+		}
+		records := storedTypes[v.File]()
+		if err := json.Unmarshal(raw, records); err != nil {
+			return report, fmt.Errorf("%s after migrating: %w", v.File, err)
+		}
+		if encoded[v.File], err = encodeFile(v.File, records); err != nil {
+			return report, err
+		}
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for _, name := range migrationOrder {
+		data, ok := encoded[name]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dataDir, name)
+		fi, err := os.Stat(path)
+		if err != nil {
+			return report, err
+		}
+		if err := replaceFile(path, data, fi.Mode().Perm()); err != nil {
+			return report, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return report, nil
+}
+
+// readForMigration reads every data file that exists as raw records.
+func readForMigration(dataDir string) (migrationData, []FileVersion, error) {
+	d := migrationData{}
+	versions := []FileVersion{}
+	for _, name := range migrationOrder {
+		data, err := os.ReadFile(filepath.Join(dataDir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			d[name] = map[string]rawRecord{}
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		records := map[string]rawRecord{}
+		version, err := DecodeFile(data, &records)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if current := schemaVersions[name]; version > current {
+			return nil, nil, &SchemaError{File: name, Version: version, Want: current}
+		}
+		d[name] = records
+		versions = append(versions, FileVersion{File: name, Version: version, Current: schemaVersions[name]})
+	}
+	return d, versions, nil
+}
+
+// ReadFiles copies the data files in dataDir as they are on disk, in
+// whatever schema version, for a backup taken while nothing has them open.
+// The settlements are included when there are some, as Snapshot includes
+// them.
+func ReadFiles(dataDir string) ([]SnapshotFile, error) {
+	var files []SnapshotFile
+	for _, name := range []string{userFile, drawFile, ticketFile, prizeFile, settlementFile} {
+		data, err := os.ReadFile(filepath.Join(dataDir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			if name == settlementFile {
+				continue
+			}
+			data = []byte("{}")
+		} else if err != nil {
+			return nil, err
+		}
+		var records map[string]json.RawMessage
+		if _, err := DecodeFile(data, &records); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		files = append(files, SnapshotFile{Name: name, Data: data, Records: len(records)})
+	}
+
+	audit, err := NewAuditLog(dataDir).snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return append(files, audit), nil
+}
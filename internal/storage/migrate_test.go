@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"LotterySystem/internal/utils"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// copyFixture copies the data files in testdata/name to a scratch data
+// directory and returns it.
+func copyFixture(t *testing.T, name string) string {
+	t.Helper()
+	dir := t.TempDir()
+	entries, err := os.ReadDir(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", name, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readDir returns the contents of every file in dir by name.
+func readDir(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	files := map[string][]byte{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[e.Name()] = data
+	}
+	return files
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		dryRun   bool
+		versions map[string]int
+		steps    []MigrationStep
+		// unchanged are files that must not be rewritten.
+		unchanged []string
+		check     func(t *testing.T, dir string)
+	}{
+		{
+			name:     "v0",
+			fixture:  "v0",
+			versions: map[string]int{userFile: 0, drawFile: 0, ticketFile: 0, prizeFile: 0},
+			steps: []MigrationStep{
+				{File: drawFile, Version: 1, Changed: 1},
+				{File: ticketFile, Version: 1, Changed: 2},
+				{File: prizeFile, Version: 1, Changed: 1},
+				{File: prizeFile, Version: 2, Changed: 1},
+			},
+			check: func(t *testing.T, dir string) {
+				draw, err := NewDrawRepository(dir).GetByID("d2")
+				if err != nil || draw.WinningNumbers == nil {
+					t.Errorf("draw d2 = %+v, %v; want an empty list of winning numbers", draw, err)
+				}
+
+				tickets := NewTicketRepository(dir)
+				t1, _ := tickets.GetByID("t1")
+				t2, _ := tickets.GetByID("t2")
+				if t1.Status != "active" || t2.Status != "cancelled" {
+					t.Errorf("ticket statuses = %q, %q; want active, cancelled", t1.Status, t2.Status)
+				}
+				for _, tk := range []string{t1.Serial, t2.Serial} {
+					if !utils.ValidateSerial(tk) {
+						t.Errorf("backfilled serial %q is not valid", tk)
+					}
+				}
+				if t1.Price != 100 || t2.Price != 100 {
+					t.Errorf("ticket prices = %d, %d; want the legacy price", t1.Price, t2.Price)
+				}
+
+				prize, err := NewPrizeRepository(dir).GetByID("p1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if prize.DrawID != "d1" {
+					t.Errorf("prize draw = %q, want d1 from its ticket", prize.DrawID)
+				}
+				drawn := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+				if !prize.CreatedAt.Equal(drawn) {
+					t.Errorf("prize created at %v, want the date of draw d1 (%v)", prize.CreatedAt, drawn)
+				}
+				if prize.CreditedAt == nil || !prize.CreditedAt.Equal(drawn) {
+					t.Errorf("prize credited at %v, want when it was awarded (%v)", prize.CreditedAt, drawn)
+				}
+			},
+		},
+		{
+			name:      "v1",
+			fixture:   "v1",
+			versions:  map[string]int{userFile: 1, drawFile: 1, ticketFile: 1, prizeFile: 1},
+			steps:     []MigrationStep{{File: prizeFile, Version: 2, Changed: 1}},
+			unchanged: []string{userFile, drawFile, ticketFile},
+			check: func(t *testing.T, dir string) {
+				prizes := NewPrizeRepository(dir)
+				money, _ := prizes.GetByID("p1")
+				gift, _ := prizes.GetByID("p2")
+				if money.CreditedAt == nil {
+					t.Error("money prize not marked credited")
+				}
+				if gift.CreditedAt != nil {
+					t.Errorf("gift prize marked credited at %v", gift.CreditedAt)
+				}
+			},
+		},
+		{
+			name:      "v0 dry run",
+			fixture:   "v0",
+			dryRun:    true,
+			versions:  map[string]int{userFile: 0, drawFile: 0, ticketFile: 0, prizeFile: 0},
+			steps:     []MigrationStep{{File: drawFile, Version: 1, Changed: 1}, {File: ticketFile, Version: 1, Changed: 2}, {File: prizeFile, Version: 1, Changed: 1}, {File: prizeFile, Version: 2, Changed: 1}},
+			unchanged: []string{userFile, drawFile, ticketFile, prizeFile},
+		},
+		{
+			name:      "v1 dry run",
+			fixture:   "v1",
+			dryRun:    true,
+			versions:  map[string]int{userFile: 1, drawFile: 1, ticketFile: 1, prizeFile: 1},
+			steps:     []MigrationStep{{File: prizeFile, Version: 2, Changed: 1}},
+			unchanged: []string{userFile, drawFile, ticketFile, prizeFile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := copyFixture(t, tt.fixture)
+			before := readDir(t, dir)
+
+			report, err := Migrate(dir, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.DryRun != tt.dryRun {
+				t.Errorf("DryRun = %v, want %v", report.DryRun, tt.dryRun)
+			}
+			versions := map[string]int{}
+			for _, f := range report.Files {
+				versions[f.File] = f.Version
+				if f.Current != SchemaVersion(f.File) {
+					t.Errorf("%s current = %d, want %d", f.File, f.Current, SchemaVersion(f.File))
+				}
+			}
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("versions = %v, want %v", versions, tt.versions)
+			}
+			for i := range report.Steps {
+				report.Steps[i].About = ""
+			}
+			if !reflect.DeepEqual(report.Steps, tt.steps) {
+				t.Errorf("steps = %+v, want %+v", report.Steps, tt.steps)
+			}
+
+			after := readDir(t, dir)
+			for _, name := range tt.unchanged {
+				if !bytes.Equal(before[name], after[name]) {
+					t.Errorf("%s was rewritten", name)
+				}
+			}
+			if len(after) != len(before) {
+				t.Errorf("data directory has %d files after migrating, want %d", len(after), len(before))
+			}
+
+			again, err := SchemaVersions(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range again {
+				want := f.Current
+				if tt.dryRun {
+					want = tt.versions[f.File]
+				}
+				if f.Version != want {
+					t.Errorf("%s at version %d after migrating, want %d", f.File, f.Version, want)
+				}
+			}
+			if tt.dryRun {
+				return
+			}
+
+			if report, err := Migrate(dir, false); err != nil || len(report.Steps) != 0 || report.Pending() {
+				t.Errorf("second migration = %+v, %v; want nothing to do", report, err)
+			}
+			for _, st := range []FileStatus{NewUserRepository(dir).Status(), NewDrawRepository(dir).Status(), NewTicketRepository(dir).Status(), NewPrizeRepository(dir).Status()} {
+				if st.LoadError != "" {
+					t.Errorf("%s: %s", st.File, st.LoadError)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, dir)
+			}
+		})
+	}
+}
+
+func TestMigrateRefusesNewerFiles(t *testing.T) {
+	dir := copyFixture(t, "v1")
+	newer := []byte(`{"schema_version": 99, "records": {}}`)
+	if err := os.WriteFile(filepath.Join(dir, ticketFile), newer, 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := readDir(t, dir)
+
+	_, err := Migrate(dir, false)
+	var serr *SchemaError
+	if !errors.As(err, &serr) || serr.File != ticketFile || serr.Version != 99 {
+		t.Fatalf("Migrate error = %v, want a schema error for %s", err, ticketFile)
+	}
+	after := readDir(t, dir)
+	for name := range before {
+		if !bytes.Equal(before[name], after[name]) {
+			t.Errorf("%s was rewritten", name)
+		}
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	dir := copyFixture(t, "v1")
+	var serr *SchemaError
+	if err := CheckSchema(dir); !errors.As(err, &serr) || serr.File != prizeFile || serr.Version != 1 {
+		t.Fatalf("CheckSchema before migrating = %v, want a schema error for %s", err, prizeFile)
+	}
+	if _, err := Migrate(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSchema(dir); err != nil {
+		t.Fatalf("CheckSchema after migrating = %v", err)
+	}
+	if err := CheckSchema(t.TempDir()); err != nil {
+		t.Fatalf("CheckSchema of an empty data directory = %v", err)
+	}
+}
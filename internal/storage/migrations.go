@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	"LotterySystem/internal/models"
+	"LotterySystem/internal/utils"
+)
+
+// migrations are run in this order. Each file's first migration takes the
+// bare records written before schema versions existed to version 1; files
+// that have none are only stamped.
+var migrations = []migration{
+	{
+		file:    drawFile,
+		version: 1,
+		about:   "give draws without winning numbers an empty list",
+		apply: func(d migrationData) int {
+			changed := 0
+			for _, draw := range d[drawFile] {
+				if draw.empty("winning_numbers") {
+					draw.set("winning_numbers", []int{})
+					changed++
+				}
+			}
+			return changed
+		},
+	},
+	{
+		file:    ticketFile,
+		version: 1,
+		about:   "record the status, price and serial of tickets bought before they were stored",
+		apply: func(d migrationData) int {
+			changed := 0
+			for _, t := range d[ticketFile] {
+				fixed := false
+				if t.empty("status") {
+					status := "active"
+					if !t.empty("cancelled_at") {
+						status = "cancelled"
+					}
+					t.set("status", status)
+					fixed = true
+				}
+				if t.empty("price") {
+					t.set("price", models.LegacyTicketPrice)
+					fixed = true
+				}
+				if t.empty("serial") {
+					t.set("serial", utils.GenerateSerial())
+					fixed = true
+				}
+				if fixed {
+					changed++
+				}
+			}
+			return changed
+		},
+	},
+	{
+		file:    prizeFile,
+		version: 1,
+		about:   "take the draw of prizes awarded before it was stored from their ticket",
+		apply: func(d migrationData) int {
+			changed := 0
+			for _, p := range d[prizeFile] {
+				if !p.empty("draw_id") {
+					continue
+				}
+				if t, ok := d[ticketFile][p.string("ticket_id")]; ok && !t.empty("draw_id") {
+					p["draw_id"] = t["draw_id"]
+					changed++
+				}
+			}
+			return changed
+		},
+	},
+	{
+		file:    prizeFile,
+		version: 2,
+		about:   "date prizes awarded before it was stored by their draw, and mark money prizes awarded before credits were recorded as credited then",
+		apply: func(d migrationData) int {
+			now := time.Now().UTC()
+			changed := 0
+			for _, p := range d[prizeFile] {
+				fixed := false
+				if p.empty("created_at") {
+					p.set("created_at", prizeDrawn(d, p, now))
+					fixed = true
+				}
+				if p.string("type") == string(models.Money) && p.empty("credited_at") {
+					p["credited_at"] = p["created_at"]
+					fixed = true
+				}
+				if fixed {
+					changed++
+				}
+			}
+			return changed
+		},
+	},
+}
+
+// prizeDrawn returns the draw date of the draw a prize was awarded in,
+// going by its ticket if it has no draw, or else now.
+func prizeDrawn(d migrationData, p rawRecord, now time.Time) time.Time {
+	drawID := p.string("draw_id")
+	if drawID == "" {
+		drawID = d[ticketFile][p.string("ticket_id")].string("draw_id")
+	}
+	var drawn time.Time
+	if draw, ok := d[drawFile][drawID]; ok && !draw.empty("draw_date") {
+		json.Unmarshal(draw["draw_date"], &drawn)
+	}
+	if drawn.IsZero() {
+		return now
+	}
+	return drawn
+}
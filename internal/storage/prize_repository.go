@@ -2,44 +2,121 @@ package storage
 
 import (
 	"LotterySystem/internal/models"
-	"encoding/json"
-	"errors"
-	"os"
+	"fmt"
+	"path/filepath"
 	"sync"
 )
 
-const prizeFile = "data/prizes.json"
+const prizeFile = "prizes.json"
 
 type PrizeRepository struct {
-	mu sync.RWMutex
-	db map[string]models.Prize
+	mu   sync.RWMutex
+	db   map[string]models.Prize
+	file dataFile
 }
 
-func NewPrizeRepository() *PrizeRepository {
+func NewPrizeRepository(dataDir string) *PrizeRepository {
 	r := &PrizeRepository{
-		db: make(map[string]models.Prize),
+		db:   make(map[string]models.Prize),
+		file: dataFile{path: filepath.Join(dataDir, prizeFile), perm: 0644},
 	}
 	r.load()
 	return r
 }
 
 func (r *PrizeRepository) load() {
-	data, err := os.ReadFile(prizeFile)
-	if err == nil {
-		_ = json.Unmarshal(data, &r.db)
-	}
+	r.file.read(&r.db)
+}
+
+func (r *PrizeRepository) save(op string, args ...any) error {
+	return r.file.write(r.db, op, args...)
 }
 
-func (r *PrizeRepository) save() {
-	data, _ := json.MarshalIndent(r.db, "", "  ")
-	_ = os.WriteFile(prizeFile, data, 0644)
+func (r *PrizeRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.flush(r.db)
+}
+
+func (r *PrizeRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.file.status(len(r.db))
 }
 
 func (r *PrizeRepository) Save(p models.Prize) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if _, exists := r.db[p.ID]; exists {
+		return fmt.Errorf("prize %w", ErrAlreadyExists)
+	}
+	restore := remember(r.db, p.ID)
+	r.db[p.ID] = p
+	if err := r.save("save", "prize_id", p.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// SaveAll stores prizes in one step: if any ID is already taken none of
+// them are saved.
+func (r *PrizeRepository) SaveAll(prizes []models.Prize) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, len(prizes))
+	for i, p := range prizes {
+		if _, exists := r.db[p.ID]; exists {
+			return fmt.Errorf("prize %w", ErrAlreadyExists)
+		}
+		ids[i] = p.ID
+	}
+	restore := remember(r.db, ids...)
+	for _, p := range prizes {
+		r.db[p.ID] = p
+	}
+	if err := r.save("save all", "count", len(prizes)); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *PrizeRepository) Update(p models.Prize) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.db[p.ID]; !ok {
+		return fmt.Errorf("prize %w", ErrNotFound)
+	}
+	restore := remember(r.db, p.ID)
 	r.db[p.ID] = p
-	r.save()
+	if err := r.save("update", "prize_id", p.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// UpdateAll replaces prizes in one step: if any is missing none of them
+// are changed.
+func (r *PrizeRepository) UpdateAll(prizes []models.Prize) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, len(prizes))
+	for i, p := range prizes {
+		if _, ok := r.db[p.ID]; !ok {
+			return fmt.Errorf("prize %s %w", p.ID, ErrNotFound)
+		}
+		ids[i] = p.ID
+	}
+	restore := remember(r.db, ids...)
+	for _, p := range prizes {
+		r.db[p.ID] = p
+	}
+	if err := r.save("update all", "count", len(prizes)); err != nil {
+		restore()
+		return err
+	}
 	return nil
 }
 
@@ -49,7 +126,7 @@ func (r *PrizeRepository) GetByID(id string) (models.Prize, error) {
 
 	prize, exists := r.db[id]
 	if !exists {
-		return models.Prize{}, errors.New("prize not found")
+		return models.Prize{}, fmt.Errorf("prize %w", ErrNotFound)
 	}
 	return prize, nil
 }
@@ -63,7 +140,7 @@ func (r *PrizeRepository) GetByTicketID(ticketID string) (models.Prize, error) {
 			return prize, nil
 		}
 	}
-	return models.Prize{}, errors.New("prize not found for ticket")
+	return models.Prize{}, fmt.Errorf("prize %w", ErrNotFound)
 }
 
 func (r *PrizeRepository) List() []models.Prize {
@@ -76,3 +153,48 @@ func (r *PrizeRepository) List() []models.Prize {
 	}
 	return prizes
 }
+
+var prizeSortKeys = map[string]sortKey[models.Prize]{
+	"created_at": func(p models.Prize) string { return timeKey(p.CreatedAt) },
+	"value":      func(p models.Prize) string { return intKey(p.Value) },
+}
+
+func (r *PrizeRepository) Query(f models.PrizeFilter) (models.Page[models.Prize], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prizes := []models.Prize{}
+	for _, p := range r.db {
+		if f.Type != "" && p.Type != f.Type {
+			continue
+		}
+		if f.DrawID != "" && p.DrawID != f.DrawID {
+			continue
+		}
+		if f.UserID != "" && p.UserID != f.UserID {
+			continue
+		}
+		if p.MatchesCount < f.MinMatches {
+			continue
+		}
+		prizes = append(prizes, p)
+	}
+	return paginate(prizes, f.ListOptions, "created_at", prizeSortKeys, func(p models.Prize) string { return p.ID })
+}
+
+// Scan returns the IDs of matching prizes in the order they were awarded.
+func (r *PrizeRepository) Scan(f models.ExportFilter) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []timedID
+	for _, p := range r.db {
+		if f.DrawID != "" && p.DrawID != f.DrawID {
+			continue
+		}
+		if !inRange(p.CreatedAt, f.From, f.To) {
+			continue
+		}
+		entries = append(entries, timedID{at: p.CreatedAt, id: p.ID})
+	}
+	return sortedIDs(entries)
+}
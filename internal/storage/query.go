@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"LotterySystem/internal/models"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort field")
+)
+
+// sortKey turns a record into a string that orders the same way as the
+// field it was taken from, so paging only needs string comparisons.
+type sortKey[T any] func(T) string
+
+func timeKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000")
+}
+
+func intKey(n int) string {
+	// Offset so negative values still sort before positive ones.
+	return fmt.Sprintf("%020d", int64(n)+1<<62)
+}
+
+// paginate orders items by the requested key, breaking ties by ID so the
+// order is stable, and returns the page following opts.Cursor.
+func paginate[T any](items []T, opts models.ListOptions, defaultSort string, keys map[string]sortKey[T], id func(T) string) (models.Page[T], error) {
+	field := opts.Sort
+	if field == "" {
+		field = defaultSort
+	}
+	key, ok := keys[field]
+	if !ok {
+		return models.Page[T]{}, fmt.Errorf("%w: %q", ErrInvalidSort, field)
+	}
+
+	type entry struct {
+		key, id string
+		item    T
+	}
+	entries := make([]entry, len(items))
+	for i, it := range items {
+		entries[i] = entry{key: key(it), id: id(it), item: it}
+	}
+
+	less := func(ak, aid, bk, bid string) bool {
+		if ak != bk {
+			return (ak < bk) != opts.Desc
+		}
+		return (aid < bid) != opts.Desc
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return less(entries[i].key, entries[i].id, entries[j].key, entries[j].id)
+	})
+
+	start := 0
+	if opts.Cursor != "" {
+		ck, cid, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return models.Page[T]{}, err
+		}
+		start = sort.Search(len(entries), func(i int) bool {
+			return less(ck, cid, entries[i].key, entries[i].id)
+		})
+	}
+
+	end := len(entries)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+
+	page := models.Page[T]{Items: make([]T, 0, end-start)}
+	for _, e := range entries[start:end] {
+		page.Items = append(page.Items, e.item)
+	}
+	if end < len(entries) {
+		last := entries[end-1]
+		page.NextCursor = encodeCursor(last.key, last.id)
+	}
+	return page, nil
+}
+
+func encodeCursor(key, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key + "\x00" + id))
+}
+
+func decodeCursor(cursor string) (string, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	key, id, ok := strings.Cut(string(raw), "\x00")
+	if !ok {
+		return "", "", ErrInvalidCursor
+	}
+	return key, id, nil
+}
+
+func inRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.After(to) {
+		return false
+	}
+	return true
+}
+
+// timedID is what Scan methods sort on, so an export holds only the IDs of
+// the records it is going to write rather than the records themselves.
+type timedID struct {
+	at time.Time
+	id string
+}
+
+func sortedIDs(entries []timedID) []string {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].at.Equal(entries[j].at) {
+			return entries[i].at.Before(entries[j].at)
+		}
+		return entries[i].id < entries[j].id
+	})
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.id
+	}
+	return ids
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"LotterySystem/internal/models"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+const settlementFile = "settlements.json"
+
+// SettlementRepository holds the latest settlement of each draw, keyed by
+// draw ID.
+type SettlementRepository struct {
+	mu   sync.RWMutex
+	db   map[string]models.Settlement
+	file dataFile
+}
+
+func NewSettlementRepository(dataDir string) *SettlementRepository {
+	r := &SettlementRepository{
+		db:   make(map[string]models.Settlement),
+		file: dataFile{path: filepath.Join(dataDir, settlementFile), perm: 0644},
+	}
+	r.load()
+	return r
+}
+
+func (r *SettlementRepository) load() {
+	r.file.read(&r.db)
+}
+
+func (r *SettlementRepository) save(op string, args ...any) error {
+	return r.file.write(r.db, op, args...)
+}
+
+func (r *SettlementRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.flush(r.db)
+}
+
+func (r *SettlementRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.file.status(len(r.db))
+}
+
+// Put saves st, replacing the draw's previous settlement.
+func (r *SettlementRepository) Put(st models.Settlement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	restore := remember(r.db, st.DrawID)
+	r.db[st.DrawID] = st
+	if err := r.save("put", "draw_id", st.DrawID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *SettlementRepository) Get(drawID string) (models.Settlement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	st, ok := r.db[drawID]
+	if !ok {
+		return models.Settlement{}, fmt.Errorf("settlement %w", ErrNotFound)
+	}
+	return st, nil
+}
+
+func (r *SettlementRepository) List() []models.Settlement {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]models.Settlement, 0, len(r.db))
+	for _, st := range r.db {
+		res = append(res, st)
+	}
+	return res
+}
+
+// Running returns the settlements that never finished, e.g. because the
+// server stopped partway through.
+func (r *SettlementRepository) Running() []models.Settlement {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var running []models.Settlement
+	for _, st := range r.db {
+		if st.Status == models.SettlementRunning {
+			running = append(running, st)
+		}
+	}
+	return running
+}
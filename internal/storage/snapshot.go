@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// The files a snapshot of the service is made of.
+const (
+	UsersFile       = userFile
+	DrawsFile       = drawFile
+	TicketsFile     = ticketFile
+	PrizesFile      = prizeFile
+	AuditFile       = auditFile
+	SettlementsFile = settlementFile
+)
+
+// SnapshotFile is one data file as it stood when a snapshot was taken.
+type SnapshotFile struct {
+	Name    string
+	Data    []byte
+	Records int
+}
+
+// Snapshotter is a repository that can be part of a snapshot.
+type Snapshotter interface {
+	rlock()
+	runlock()
+	snapshot() (SnapshotFile, error)
+}
+
+// Snapshot copies the data files of rs at a single point: all of their
+// locks are held together while they are copied, so no change can be in
+// one copy and missing from another.
+func Snapshot(rs ...Snapshotter) ([]SnapshotFile, error) {
+	for _, r := range rs {
+		r.rlock()
+	}
+	defer func() {
+		for _, r := range rs {
+			r.runlock()
+		}
+	}()
+
+	files := make([]SnapshotFile, 0, len(rs))
+	for _, r := range rs {
+		f, err := r.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (f *dataFile) snapshot(v any, records int) (SnapshotFile, error) {
+	if f.loadErr != nil {
+		// The repository is missing whatever failed to load; a copy of it
+		// would quietly lose that data.
+		return SnapshotFile{}, fmt.Errorf("%s was not loaded: %w", filepath.Base(f.path), f.loadErr)
+	}
+	data, err := encodeFile(filepath.Base(f.path), v)
+	if err != nil {
+		return SnapshotFile{}, err
+	}
+	return SnapshotFile{Name: filepath.Base(f.path), Data: data, Records: records}, nil
+}
+
+func (r *UserRepository) rlock()   { r.mu.RLock() }
+func (r *UserRepository) runlock() { r.mu.RUnlock() }
+func (r *UserRepository) snapshot() (SnapshotFile, error) {
+	return r.file.snapshot(r.db, len(r.db))
+}
+
+func (r *DrawRepository) rlock()   { r.mu.RLock() }
+func (r *DrawRepository) runlock() { r.mu.RUnlock() }
+func (r *DrawRepository) snapshot() (SnapshotFile, error) {
+	return r.file.snapshot(r.db, len(r.db))
+}
+
+func (r *TicketRepository) rlock()   { r.mu.RLock() }
+func (r *TicketRepository) runlock() { r.mu.RUnlock() }
+func (r *TicketRepository) snapshot() (SnapshotFile, error) {
+	return r.file.snapshot(r.db, len(r.db))
+}
+
+func (r *PrizeRepository) rlock()   { r.mu.RLock() }
+func (r *PrizeRepository) runlock() { r.mu.RUnlock() }
+func (r *PrizeRepository) snapshot() (SnapshotFile, error) {
+	return r.file.snapshot(r.db, len(r.db))
+}
+
+func (r *SettlementRepository) rlock()   { r.mu.RLock() }
+func (r *SettlementRepository) runlock() { r.mu.RUnlock() }
+func (r *SettlementRepository) snapshot() (SnapshotFile, error) {
+	return r.file.snapshot(r.db, len(r.db))
+}
+
+// The audit log is copied as it is on disk, since every entry is written
+// before Append returns.
+func (l *AuditLog) rlock()   { l.mu.RLock() }
+func (l *AuditLog) runlock() { l.mu.RUnlock() }
+func (l *AuditLog) snapshot() (SnapshotFile, error) {
+	if l.file.loadErr != nil {
+		return SnapshotFile{}, fmt.Errorf("%s was not loaded: %w", auditFile, l.file.loadErr)
+	}
+	data, err := os.ReadFile(l.file.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return SnapshotFile{}, err
+	}
+	return SnapshotFile{Name: auditFile, Data: data, Records: l.seq}, nil
+}
+
+// filePerms are the modes data files are written with. Restore refuses any
+// other file.
+var filePerms = map[string]os.FileMode{
+	userFile:       0644,
+	drawFile:       0644,
+	ticketFile:     0644,
+	prizeFile:      0644,
+	settlementFile: 0644,
+	auditFile:      0600,
+}
+
+// RestoreFiles replaces data files in dataDir with files. The files it
+// replaces are first copied to keepDir, so a restore can be undone by
+// hand. The webhook deliveries queued and the idempotent responses kept
+// since are for changes the restore takes back, so they are cleared too:
+// the deliveries are moved to keepDir and the responses, which can hold
+// session tokens, are deleted. The server must not be running, since it
+// would keep serving, and then writing back, what it loaded at startup.
+func RestoreFiles(dataDir string, files []SnapshotFile, keepDir string) error {
+	for _, f := range files {
+		if _, ok := filePerms[f.Name]; !ok {
+			return fmt.Errorf("%q is not a data file", f.Name)
+		}
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(keepDir, 0700); err != nil {
+		return err
+	}
+	for _, f := range files {
+		old, err := os.ReadFile(filepath.Join(dataDir, f.Name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := replaceFile(filepath.Join(keepDir, f.Name), old, filePerms[f.Name]); err != nil {
+			return err
+		}
+	}
+
+	deliveries, err := os.ReadFile(filepath.Join(dataDir, deliveryFile))
+	if err == nil {
+		err = replaceFile(filepath.Join(keepDir, deliveryFile), deliveries, 0644)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dataDir, deliveryFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(dataDir, idempotencyDir)); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := replaceFile(filepath.Join(dataDir, f.Name), f.Data, filePerms[f.Name]); err != nil {
+			return fmt.Errorf("%s: %w (the previous files are in %s)", f.Name, err, keepDir)
+		}
+	}
+	return nil
+}
@@ -2,44 +2,83 @@ package storage
 
 import (
 	"LotterySystem/internal/models"
-	"encoding/json"
-	"errors"
-	"os"
+	"fmt"
+	"path/filepath"
 	"sync"
 )
 
-const ticketFile = "data/tickets.json"
+const ticketFile = "tickets.json"
 
 type TicketRepository struct {
-	mu sync.RWMutex
-	db map[string]models.Ticket
+	mu   sync.RWMutex
+	db   map[string]models.Ticket
+	file dataFile
 }
 
-func NewTicketRepository() *TicketRepository {
+func NewTicketRepository(dataDir string) *TicketRepository {
 	r := &TicketRepository{
-		db: make(map[string]models.Ticket),
+		db:   make(map[string]models.Ticket),
+		file: dataFile{path: filepath.Join(dataDir, ticketFile), perm: 0644},
 	}
 	r.load()
 	return r
 }
 
 func (r *TicketRepository) load() {
-	data, err := os.ReadFile(ticketFile)
-	if err == nil {
-		_ = json.Unmarshal(data, &r.db)
-	}
+	r.file.read(&r.db)
+}
+
+func (r *TicketRepository) save(op string, args ...any) error {
+	return r.file.write(r.db, op, args...)
 }
 
-func (r *TicketRepository) save() {
-	data, _ := json.MarshalIndent(r.db, "", "  ")
-	_ = os.WriteFile(ticketFile, data, 0644)
+func (r *TicketRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.flush(r.db)
+}
+
+func (r *TicketRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.file.status(len(r.db))
 }
 
 func (r *TicketRepository) Save(t models.Ticket) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if _, exists := r.db[t.ID]; exists {
+		return fmt.Errorf("ticket %w", ErrAlreadyExists)
+	}
+	restore := remember(r.db, t.ID)
 	r.db[t.ID] = t
-	r.save()
+	if err := r.save("save", "ticket_id", t.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// SaveAll stores tickets in one step: if any ID is already taken none of
+// them are saved.
+func (r *TicketRepository) SaveAll(tickets []models.Ticket) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, len(tickets))
+	for i, t := range tickets {
+		if _, exists := r.db[t.ID]; exists {
+			return fmt.Errorf("ticket %w", ErrAlreadyExists)
+		}
+		ids[i] = t.ID
+	}
+	restore := remember(r.db, ids...)
+	for _, t := range tickets {
+		r.db[t.ID] = t
+	}
+	if err := r.save("save all", "count", len(tickets)); err != nil {
+		restore()
+		return err
+	}
 	return nil
 }
 
@@ -48,10 +87,60 @@ func (r *TicketRepository) Update(t models.Ticket) error {
 	defer r.mu.Unlock()
 
 	if _, exists := r.db[t.ID]; !exists {
-		return errors.New("ticket not found")
+		return fmt.Errorf("ticket %w", ErrNotFound)
 	}
+	restore := remember(r.db, t.ID)
 	r.db[t.ID] = t
-	r.save()
+	if err := r.save("update", "ticket_id", t.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// UpdateIfStatus replaces a ticket only while its stored status is still
+// status, so two callers that both read the old ticket cannot both move it
+// on.
+func (r *TicketRepository) UpdateIfStatus(t models.Ticket, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.db[t.ID]
+	if !exists {
+		return fmt.Errorf("ticket %w", ErrNotFound)
+	}
+	if current.Status != status {
+		return fmt.Errorf("ticket %s: %w", t.ID, ErrStatusChanged)
+	}
+	restore := remember(r.db, t.ID)
+	r.db[t.ID] = t
+	if err := r.save("update", "ticket_id", t.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// UpdateAll replaces tickets in one step: if any is missing none of them
+// are changed.
+func (r *TicketRepository) UpdateAll(tickets []models.Ticket) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, len(tickets))
+	for i, t := range tickets {
+		if _, exists := r.db[t.ID]; !exists {
+			return fmt.Errorf("ticket %s %w", t.ID, ErrNotFound)
+		}
+		ids[i] = t.ID
+	}
+	restore := remember(r.db, ids...)
+	for _, t := range tickets {
+		r.db[t.ID] = t
+	}
+	if err := r.save("update all", "count", len(tickets)); err != nil {
+		restore()
+		return err
+	}
 	return nil
 }
 
@@ -61,7 +150,7 @@ func (r *TicketRepository) GetByID(id string) (models.Ticket, error) {
 
 	ticket, exists := r.db[id]
 	if !exists {
-		return models.Ticket{}, errors.New("ticket not found")
+		return models.Ticket{}, fmt.Errorf("ticket %w", ErrNotFound)
 	}
 	return ticket, nil
 }
@@ -96,9 +185,91 @@ func (r *TicketRepository) GetByDrawID(drawID string) []models.Ticket {
 
 	result := []models.Ticket{}
 	for _, t := range r.db {
-		if t.DrawID == drawID {
+		if t.DrawID == drawID && !t.IsCancelled() {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func (r *TicketRepository) GetCancelled() []models.Ticket {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []models.Ticket{}
+	for _, t := range r.db {
+		if t.IsCancelled() {
 			result = append(result, t)
 		}
 	}
 	return result
 }
+
+func (r *TicketRepository) GetBySerial(serial string) (models.Ticket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.db {
+		if t.Serial == serial {
+			return t, nil
+		}
+	}
+	return models.Ticket{}, fmt.Errorf("ticket %w", ErrNotFound)
+}
+
+var ticketSortKeys = map[string]sortKey[models.Ticket]{
+	"created_at": func(t models.Ticket) string { return timeKey(t.CreatedAt) },
+	"matches":    func(t models.Ticket) string { return intKey(t.Matches) },
+}
+
+// Query includes cancelled tickets unless a status filter excludes them.
+// Tickets stored before statuses existed count as "active".
+func (r *TicketRepository) Query(f models.TicketFilter) (models.Page[models.Ticket], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []models.Ticket{}
+	for _, t := range r.db {
+		if f.UserID != "" && t.UserID != f.UserID {
+			continue
+		}
+		if f.DrawID != "" && t.DrawID != f.DrawID {
+			continue
+		}
+		if f.Status != "" && ticketStatus(t) != f.Status {
+			continue
+		}
+		if t.Matches < f.MinMatches {
+			continue
+		}
+		if !inRange(t.CreatedAt, f.From, f.To) {
+			continue
+		}
+		result = append(result, t)
+	}
+	return paginate(result, f.ListOptions, "created_at", ticketSortKeys, func(t models.Ticket) string { return t.ID })
+}
+
+func ticketStatus(t models.Ticket) string {
+	if t.Status == "" {
+		return "active"
+	}
+	return t.Status
+}
+
+// Scan returns the IDs of matching tickets in purchase order.
+func (r *TicketRepository) Scan(f models.ExportFilter) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []timedID
+	for _, t := range r.db {
+		if f.DrawID != "" && t.DrawID != f.DrawID {
+			continue
+		}
+		if !inRange(t.CreatedAt, f.From, f.To) {
+			continue
+		}
+		entries = append(entries, timedID{at: t.CreatedAt, id: t.ID})
+	}
+	return sortedIDs(entries)
+}
@@ -2,44 +2,89 @@ package storage
 
 import (
 	"LotterySystem/internal/models"
-	"encoding/json"
-	"errors"
-	"os"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
-const userFile = "data/users.json"
+const userFile = "users.json"
 
 type UserRepository struct {
-	mu sync.RWMutex
-	db map[string]models.User
+	mu   sync.RWMutex
+	db   map[string]models.User
+	file dataFile
 }
 
-func NewUserRepository() *UserRepository {
+func NewUserRepository(dataDir string) *UserRepository {
 	r := &UserRepository{
-		db: make(map[string]models.User),
+		db:   make(map[string]models.User),
+		file: dataFile{path: filepath.Join(dataDir, userFile), perm: 0644},
 	}
 	r.load()
 	return r
 }
 
 func (r *UserRepository) load() {
-	data, err := os.ReadFile(userFile)
-	if err == nil {
-		_ = json.Unmarshal(data, &r.db)
-	}
+	r.file.read(&r.db)
 }
 
-func (r *UserRepository) save() {
-	data, _ := json.MarshalIndent(r.db, "", "  ")
-	_ = os.WriteFile(userFile, data, 0644)
+func (r *UserRepository) save(op string, args ...any) error {
+	return r.file.write(r.db, op, args...)
+}
+
+func (r *UserRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.flush(r.db)
+}
+
+func (r *UserRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.file.status(len(r.db))
 }
 
 func (r *UserRepository) Save(u models.User) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if _, exists := r.db[u.ID]; exists {
+		return fmt.Errorf("user %w", ErrAlreadyExists)
+	}
+	restore := remember(r.db, u.ID)
 	r.db[u.ID] = u
-	r.save()
+	if err := r.save("save", "user_id", u.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// SaveAll stores users in one step: if any ID or username is already taken
+// none of them are saved.
+func (r *UserRepository) SaveAll(users []models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	taken := make(map[string]bool, len(r.db))
+	for _, u := range r.db {
+		taken[u.Username] = true
+	}
+	ids := make([]string, len(users))
+	for i, u := range users {
+		if _, exists := r.db[u.ID]; exists || taken[u.Username] {
+			return fmt.Errorf("user %s %w", u.Username, ErrAlreadyExists)
+		}
+		taken[u.Username] = true
+		ids[i] = u.ID
+	}
+	restore := remember(r.db, ids...)
+	for _, u := range users {
+		r.db[u.ID] = u
+	}
+	if err := r.save("save all", "count", len(users)); err != nil {
+		restore()
+		return err
+	}
 	return nil
 }
 
@@ -47,10 +92,43 @@ func (r *UserRepository) Update(u models.User) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if _, ok := r.db[u.ID]; !ok {
-		return errors.New("user not found")
+		return fmt.Errorf("user %w", ErrNotFound)
 	}
+	restore := remember(r.db, u.ID)
 	r.db[u.ID] = u
-	r.save()
+	if err := r.save("update", "user_id", u.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// AdjustBalances adds each delta to the user's balance in one step. If a
+// user is missing or a balance would go negative nothing is changed.
+func (r *UserRepository) AdjustBalances(deltas map[string]int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(deltas))
+	for id, delta := range deltas {
+		u, ok := r.db[id]
+		if !ok {
+			return fmt.Errorf("user %w", ErrNotFound)
+		}
+		if u.Balance+delta < 0 {
+			return fmt.Errorf("user %s: %w", id, ErrNegativeBalance)
+		}
+		ids = append(ids, id)
+	}
+	restore := remember(r.db, ids...)
+	for id, delta := range deltas {
+		u := r.db[id]
+		u.Balance += delta
+		r.db[id] = u
+	}
+	if err := r.save("adjust balances", "users", len(deltas)); err != nil {
+		restore()
+		return err
+	}
 	return nil
 }
 
@@ -59,7 +137,7 @@ func (r *UserRepository) GetByID(id string) (models.User, error) {
 	defer r.mu.RUnlock()
 	u, ok := r.db[id]
 	if !ok {
-		return models.User{}, errors.New("user not found")
+		return models.User{}, fmt.Errorf("user %w", ErrNotFound)
 	}
 	return u, nil
 }
@@ -72,7 +150,7 @@ func (r *UserRepository) GetByUsername(username string) (models.User, error) {
 			return u, nil
 		}
 	}
-	return models.User{}, errors.New("user not found")
+	return models.User{}, fmt.Errorf("user %w", ErrNotFound)
 }
 
 func (r *UserRepository) List() []models.User {
@@ -84,3 +162,34 @@ func (r *UserRepository) List() []models.User {
 	}
 	return res
 }
+
+var userSortKeys = map[string]sortKey[models.User]{
+	"created_at": func(u models.User) string { return timeKey(u.CreatedAt) },
+	"username":   func(u models.User) string { return strings.ToLower(u.Username) },
+	"balance":    func(u models.User) string { return intKey(u.Balance) },
+}
+
+func (r *UserRepository) Query(f models.UserFilter) (models.Page[models.User], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name := strings.ToLower(f.Username)
+	res := []models.User{}
+	for _, u := range r.db {
+		if name != "" && !strings.Contains(strings.ToLower(u.Username), name) {
+			continue
+		}
+		if f.Role != "" && userRole(u) != f.Role {
+			continue
+		}
+		res = append(res, u)
+	}
+	return paginate(res, f.ListOptions, "created_at", userSortKeys, func(u models.User) string { return u.ID })
+}
+
+func userRole(u models.User) string {
+	if u.Role == "" {
+		return models.RolePlayer
+	}
+	return u.Role
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"LotterySystem/internal/models"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	webhookFile  = "webhooks.json"
+	deliveryFile = "webhook_deliveries.json"
+)
+
+type WebhookRepository struct {
+	mu   sync.RWMutex
+	db   map[string]models.Webhook
+	file dataFile
+}
+
+func NewWebhookRepository(dataDir string) *WebhookRepository {
+	r := &WebhookRepository{
+		db:   make(map[string]models.Webhook),
+		file: dataFile{path: filepath.Join(dataDir, webhookFile), perm: 0600},
+	}
+	r.load()
+	return r
+}
+
+func (r *WebhookRepository) load() {
+	r.file.read(&r.db)
+}
+
+func (r *WebhookRepository) save(op string, args ...any) error {
+	return r.file.write(r.db, op, args...)
+}
+
+func (r *WebhookRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.flush(r.db)
+}
+
+func (r *WebhookRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.file.status(len(r.db))
+}
+
+func (r *WebhookRepository) Save(w models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.db[w.ID]; exists {
+		return fmt.Errorf("webhook %w", ErrAlreadyExists)
+	}
+	restore := remember(r.db, w.ID)
+	r.db[w.ID] = w
+	if err := r.save("save", "webhook_id", w.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.db[id]; !ok {
+		return fmt.Errorf("webhook %w", ErrNotFound)
+	}
+	restore := remember(r.db, id)
+	delete(r.db, id)
+	if err := r.save("delete", "webhook_id", id); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(id string) (models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.db[id]
+	if !ok {
+		return models.Webhook{}, fmt.Errorf("webhook %w", ErrNotFound)
+	}
+	return w, nil
+}
+
+func (r *WebhookRepository) List() []models.Webhook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := []models.Webhook{}
+	for _, w := range r.db {
+		res = append(res, w)
+	}
+	return res
+}
+
+func (r *WebhookRepository) ListForEvent(eventType string) []models.Webhook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := []models.Webhook{}
+	for _, w := range r.db {
+		if w.Active && w.Wants(eventType) {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+// DeliveryRepository is the persistent webhook delivery queue and log.
+type DeliveryRepository struct {
+	mu   sync.RWMutex
+	db   map[string]models.WebhookDelivery
+	file dataFile
+}
+
+func NewDeliveryRepository(dataDir string) *DeliveryRepository {
+	r := &DeliveryRepository{
+		db:   make(map[string]models.WebhookDelivery),
+		file: dataFile{path: filepath.Join(dataDir, deliveryFile), perm: 0644},
+	}
+	r.load()
+	return r
+}
+
+func (r *DeliveryRepository) load() {
+	r.file.read(&r.db)
+}
+
+func (r *DeliveryRepository) save(op string, args ...any) error {
+	return r.file.write(r.db, op, args...)
+}
+
+func (r *DeliveryRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.flush(r.db)
+}
+
+func (r *DeliveryRepository) Status() FileStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.file.status(len(r.db))
+}
+
+func (r *DeliveryRepository) Save(d models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.db[d.ID]; exists {
+		return fmt.Errorf("delivery %w", ErrAlreadyExists)
+	}
+	restore := remember(r.db, d.ID)
+	r.db[d.ID] = d
+	if err := r.save("save", "delivery_id", d.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// SaveAll stores deliveries in one write: if any ID is already taken none
+// of them are saved.
+func (r *DeliveryRepository) SaveAll(deliveries []models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, len(deliveries))
+	for i, d := range deliveries {
+		if _, exists := r.db[d.ID]; exists {
+			return fmt.Errorf("delivery %w", ErrAlreadyExists)
+		}
+		ids[i] = d.ID
+	}
+	restore := remember(r.db, ids...)
+	for _, d := range deliveries {
+		r.db[d.ID] = d
+	}
+	if err := r.save("save all", "count", len(deliveries)); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *DeliveryRepository) Update(d models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.db[d.ID]; !ok {
+		return fmt.Errorf("delivery %w", ErrNotFound)
+	}
+	restore := remember(r.db, d.ID)
+	r.db[d.ID] = d
+	if err := r.save("update", "delivery_id", d.ID); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *DeliveryRepository) GetByID(id string) (models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.db[id]
+	if !ok {
+		return models.WebhookDelivery{}, fmt.Errorf("delivery %w", ErrNotFound)
+	}
+	return d, nil
+}
+
+// Due returns pending deliveries whose next attempt is at or before now.
+func (r *DeliveryRepository) Due(now time.Time) []models.WebhookDelivery {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := []models.WebhookDelivery{}
+	for _, d := range r.db {
+		if d.Status == "pending" && !d.NextAttemptAt.After(now) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// Prune removes the deliveries that were delivered, or dead-lettered,
+// before cutoff, in one write, and returns how many it removed. A dead
+// delivery's last attempt was when its next attempt was due.
+func (r *DeliveryRepository) Prune(cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []string
+	for id, d := range r.db {
+		switch {
+		case d.Status == "delivered" && d.DeliveredAt != nil && d.DeliveredAt.Before(cutoff),
+			d.Status == "dead" && d.NextAttemptAt.Before(cutoff):
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	restore := remember(r.db, ids...)
+	for _, id := range ids {
+		delete(r.db, id)
+	}
+	if err := r.save("prune", "count", len(ids)); err != nil {
+		restore()
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+var deliverySortKeys = map[string]sortKey[models.WebhookDelivery]{
+	"created_at": func(d models.WebhookDelivery) string { return timeKey(d.CreatedAt) },
+}
+
+func (r *DeliveryRepository) Query(webhookID, status string, opts models.ListOptions) (models.Page[models.WebhookDelivery], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := []models.WebhookDelivery{}
+	for _, d := range r.db {
+		if webhookID != "" && d.WebhookID != webhookID {
+			continue
+		}
+		if status != "" && d.Status != status {
+			continue
+		}
+		res = append(res, d)
+	}
+	return paginate(res, opts, "created_at", deliverySortKeys, func(d models.WebhookDelivery) string { return d.ID })
+}
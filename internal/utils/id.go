@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	UserIDPrefix   = "usr"
+	DrawIDPrefix   = "drw"
+	TicketIDPrefix = "tkt"
+	PrizeIDPrefix  = "prz"
+
+	WebhookIDPrefix  = "whk"
+	DeliveryIDPrefix = "dlv"
+)
+
+// IDGenerator hands out unique record IDs of the form "<prefix>_<id>".
+type IDGenerator interface {
+	NewID(prefix string) string
+}
+
+// ULIDGenerator produces lexically sortable IDs: a 48-bit millisecond
+// timestamp followed by 80 random bits, both Crockford base32 encoded.
+// IDs generated within the same millisecond increment the random part, so
+// they stay unique and ordered even on a coarse clock.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	lastMs  uint64
+	lastRnd [10]byte
+}
+
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now}
+}
+
+func (g *ULIDGenerator) NewID(prefix string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		if !incrementBytes(g.lastRnd[:]) {
+			// Random part overflowed; borrow the next millisecond.
+			ms++
+			g.fillRandom()
+		}
+	} else {
+		g.fillRandom()
+	}
+	g.lastMs = ms
+
+	var raw [16]byte
+	for i := 0; i < 6; i++ {
+		raw[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(raw[6:], g.lastRnd[:])
+
+	return prefix + "_" + encodeULID(raw)
+}
+
+func (g *ULIDGenerator) fillRandom() {
+	if _, err := rand.Read(g.lastRnd[:]); err != nil {
+		panic(err)
+	}
+}
+
+// SequentialIDGenerator returns predictable IDs ("tkt_000001", ...) for tests
+// and fixtures. Counters are kept per prefix.
+type SequentialIDGenerator struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+func NewSequentialIDGenerator() *SequentialIDGenerator {
+	return &SequentialIDGenerator{counters: make(map[string]int)}
+}
+
+func (g *SequentialIDGenerator) NewID(prefix string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.counters[prefix]++
+	return fmt.Sprintf("%s_%06d", prefix, g.counters[prefix])
+}
+
+func incrementBytes(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeULID(raw [16]byte) string {
+	// 128 bits encode to 26 characters; the first carries only 3 bits.
+	out := make([]byte, 26)
+	var acc uint
+	bits := 2
+	pos := 0
+	// Prepend two zero bits so the 130-bit stream splits evenly into 5s.
+	for _, b := range raw {
+		acc = acc<<8 | uint(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = serialAlphabet[(acc>>bits)&0x1F]
+			pos++
+		}
+	}
+	return string(out)
+}
@@ -1,18 +1,27 @@
 package utils
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
+	"math/big"
 )
 
-var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+// RandomIntn returns a uniform random int in [0, n) from crypto/rand, so
+// it is safe for concurrent use and serials, draws and prizes cannot be
+// predicted from earlier ones.
+func RandomIntn(n int) int {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		panic(err)
+	}
+	return int(v.Int64())
+}
 
 func GenerateWinningNumbers() []int {
 	numbers := make(map[int]bool)
 	result := make([]int, 0, 6)
 
 	for len(result) < 6 {
-		num := rng.Intn(49) + 1 // 1-49
+		num := RandomIntn(49) + 1 // 1-49
 		if !numbers[num] {
 			numbers[num] = true
 			result = append(result, num)
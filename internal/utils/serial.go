@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+)
+
+// Crockford base32 avoids I, L, O and U so serials can be read aloud.
+const serialAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+const serialLength = 12
+
+// GenerateSerial returns a ticket serial like "7K3M-9QXD-2HPA-R" whose last
+// character is a Luhn mod 32 check digit over the rest.
+func GenerateSerial() string {
+	body := make([]byte, serialLength)
+	for i := range body {
+		body[i] = serialAlphabet[RandomIntn(len(serialAlphabet))]
+	}
+	return FormatSerial(string(body) + string(serialCheckDigit(string(body))))
+}
+
+// NormalizeSerial strips separators, upper-cases and maps the characters
+// commonly confused with digits. It does not validate the check digit.
+func NormalizeSerial(serial string) string {
+	var b strings.Builder
+	for _, ch := range strings.ToUpper(serial) {
+		switch ch {
+		case '-', ' ':
+			continue
+		case 'O':
+			ch = '0'
+		case 'I', 'L':
+			ch = '1'
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+func FormatSerial(serial string) string {
+	serial = NormalizeSerial(serial)
+	var b strings.Builder
+	for i := 0; i < len(serial); i += 4 {
+		if i > 0 {
+			b.WriteByte('-')
+		}
+		end := i + 4
+		if end > len(serial) {
+			end = len(serial)
+		}
+		b.WriteString(serial[i:end])
+	}
+	return b.String()
+}
+
+func ValidateSerial(serial string) bool {
+	serial = NormalizeSerial(serial)
+	if len(serial) != serialLength+1 {
+		return false
+	}
+	for _, ch := range serial {
+		if !strings.ContainsRune(serialAlphabet, ch) {
+			return false
+		}
+	}
+	return serialCheckDigit(serial[:serialLength]) == serial[serialLength]
+}
+
+func serialCheckDigit(body string) byte {
+	n := len(serialAlphabet)
+	factor := 2
+	sum := 0
+	for i := len(body) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(serialAlphabet, body[i])
+		addend = addend/n + addend%n
+		sum += addend
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+	}
+	return serialAlphabet[(n-sum%n)%n]
+}
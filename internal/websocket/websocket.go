@@ -0,0 +1,243 @@
+// Package websocket is a small server-side RFC 6455 implementation covering
+// what the notification channel needs: text messages, ping/pong and close.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	OpText   = 0x1
+	OpBinary = 0x2
+	OpClose  = 0x8
+	OpPing   = 0x9
+	OpPong   = 0xA
+)
+
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	ClosePolicy        = 1008
+	CloseTooBig        = 1009
+	CloseTryAgainLater = 1013
+)
+
+const maxMessageSize = 64 << 10
+
+var (
+	ErrNotWebSocket = errors.New("websocket: not a websocket handshake")
+	ErrClosed       = errors.New("websocket: connection closed")
+	ErrTooBig       = errors.New("websocket: message too big")
+)
+
+type Conn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// Upgrade completes the opening handshake and takes over the connection.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, ErrNotWebSocket
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, ErrNotWebSocket
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("websocket: response does not support hijacking")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// Drop the deadlines the HTTP server set for the request; Conn sets its
+	// own on each read and write.
+	conn.SetDeadline(time.Time{})
+
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	accept := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + accept + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Conn{conn: conn, rw: rw}, nil
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Conn) WriteText(data []byte, timeout time.Duration) error {
+	return c.writeFrame(OpText, data, timeout)
+}
+
+func (c *Conn) Ping(timeout time.Duration) error {
+	return c.writeFrame(OpPing, nil, timeout)
+}
+
+// Close sends a close frame with the given code and closes the connection.
+func (c *Conn) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	c.writeFrame(OpClose, payload, time.Second)
+
+	c.writeMu.Lock()
+	c.closed = true
+	c.writeMu.Unlock()
+	return c.conn.Close()
+}
+
+// ReadMessage returns the next data message. Pings are answered and pongs
+// are swallowed; a close frame from the peer returns ErrClosed.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var (
+		message []byte
+		opcode  int
+	)
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case OpPing:
+			c.writeFrame(OpPong, payload, time.Second)
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			c.writeFrame(OpClose, payload, time.Second)
+			return 0, nil, ErrClosed
+		case 0:
+			// Continuation of a fragmented message.
+		default:
+			opcode = op
+			message = message[:0]
+		}
+
+		if len(message)+len(payload) > maxMessageSize {
+			return 0, nil, ErrTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	op := int(head[0] & 0x0F)
+	masked := head[1]&0x80 != 0
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, ErrTooBig
+	}
+
+	// Clients must mask every frame.
+	if !masked {
+		return false, 0, nil, errors.New("websocket: unmasked client frame")
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+func (c *Conn) writeFrame(op int, payload []byte, timeout time.Duration) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	header := []byte{0x80 | byte(op)}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The sample handshake from RFC 6455, section 1.3.
+const (
+	sampleKey    = "dGhlIHNhbXBsZSBub25jZQ=="
+	sampleAccept = "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+)
+
+type read struct {
+	op   int
+	data []byte
+	err  error
+}
+
+// serve starts a server that upgrades every request and reports each
+// message it reads, echoing text messages back.
+func serve(t *testing.T) (*httptest.Server, <-chan read) {
+	t.Helper()
+	reads := make(chan read, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer c.Close(CloseNormal, "")
+		for {
+			op, data, err := c.ReadMessage()
+			reads <- read{op, append([]byte(nil), data...), err}
+			if err != nil {
+				return
+			}
+			c.WriteText(data, time.Second)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, reads
+}
+
+// dial opens a connection to srv and completes the handshake, failing the
+// test unless the server accepts it.
+func dial(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+sampleKey+"\r\nSec-WebSocket-Version: 13\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != sampleAccept {
+		t.Fatalf("Sec-WebSocket-Accept = %q, want %q", got, sampleAccept)
+	}
+	return conn, br
+}
+
+// frame encodes a client frame, masked with mask unless it is nil.
+func frame(fin bool, op int, payload []byte, mask []byte) []byte {
+	b := byte(op)
+	if fin {
+		b |= 0x80
+	}
+	out := []byte{b}
+	var m byte
+	if mask != nil {
+		m = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		out = append(out, m|byte(n))
+	case n <= 0xFFFF:
+		out = append(out, m|126)
+		out = binary.BigEndian.AppendUint16(out, uint16(n))
+	default:
+		out = append(out, m|127)
+		out = binary.BigEndian.AppendUint64(out, uint64(n))
+	}
+	if mask == nil {
+		return append(out, payload...)
+	}
+	out = append(out, mask...)
+	for i, c := range payload {
+		out = append(out, c^mask[i%4])
+	}
+	return out
+}
+
+var mask = []byte{0x37, 0xfa, 0x21, 0x3d}
+
+// readServerFrame reads one frame the server sent, which is never masked.
+func readServerFrame(t *testing.T, br *bufio.Reader) (bool, int, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("server frame is masked")
+	}
+	n := int(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(br, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(br, ext[:])
+		n = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0]&0x80 != 0, int(head[0] & 0x0F), payload
+}
+
+func next(t *testing.T, reads <-chan read) read {
+	t.Helper()
+	select {
+	case r := <-reads:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server read nothing")
+		return read{}
+	}
+}
+
+func TestUpgradeRejectsOtherRequests(t *testing.T) {
+	srv, _ := serve(t)
+	for name, header := range map[string]http.Header{
+		"plain GET":   {},
+		"no key":      {"Upgrade": {"websocket"}, "Connection": {"Upgrade"}, "Sec-Websocket-Version": {"13"}},
+		"old version": {"Upgrade": {"websocket"}, "Connection": {"Upgrade"}, "Sec-Websocket-Version": {"8"}, "Sec-Websocket-Key": {sampleKey}},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header = header
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestMaskedTextMessage(t *testing.T) {
+	srv, reads := serve(t)
+	conn, br := dial(t, srv)
+
+	// The single-frame masked "Hello" from RFC 6455, section 5.7.
+	conn.Write([]byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58})
+	if r := next(t, reads); r.err != nil || r.op != OpText || string(r.data) != "Hello" {
+		t.Fatalf("ReadMessage = %d %q %v, want text Hello", r.op, r.data, r.err)
+	}
+	if fin, op, payload := readServerFrame(t, br); !fin || op != OpText || string(payload) != "Hello" {
+		t.Fatalf("echo = fin %v op %d %q", fin, op, payload)
+	}
+
+	// A 16-bit length.
+	long := strings.Repeat("x", 300)
+	conn.Write(frame(true, OpText, []byte(long), mask))
+	if r := next(t, reads); r.err != nil || string(r.data) != long {
+		t.Fatalf("ReadMessage of a %d byte message = %d bytes, %v", len(long), len(r.data), r.err)
+	}
+	if _, _, payload := readServerFrame(t, br); string(payload) != long {
+		t.Fatalf("echo of a %d byte message = %d bytes", len(long), len(payload))
+	}
+}
+
+func TestUnmaskedFrameIsRefused(t *testing.T) {
+	srv, reads := serve(t)
+	conn, _ := dial(t, srv)
+	conn.Write(frame(true, OpText, []byte("Hello"), nil))
+	if r := next(t, reads); r.err == nil {
+		t.Fatalf("an unmasked frame was read as %q", r.data)
+	}
+}
+
+func TestFragmentedMessage(t *testing.T) {
+	srv, reads := serve(t)
+	conn, br := dial(t, srv)
+
+	// "Hel" and "lo", with a ping between the fragments, as RFC 6455
+	// allows control frames to be.
+	conn.Write(frame(false, OpText, []byte("Hel"), mask))
+	conn.Write(frame(true, OpPing, []byte("p"), mask))
+	conn.Write(frame(true, 0, []byte("lo"), mask))
+
+	if fin, op, payload := readServerFrame(t, br); !fin || op != OpPong || string(payload) != "p" {
+		t.Fatalf("reply to a ping = fin %v op %d %q, want a pong", fin, op, payload)
+	}
+	if r := next(t, reads); r.err != nil || r.op != OpText || string(r.data) != "Hello" {
+		t.Fatalf("ReadMessage = %d %q %v, want text Hello", r.op, r.data, r.err)
+	}
+
+	// The next message starts afresh.
+	conn.Write(frame(true, OpText, []byte("again"), mask))
+	if r := next(t, reads); string(r.data) != "again" {
+		t.Fatalf("ReadMessage after a fragmented message = %q, want again", r.data)
+	}
+}
+
+func TestTooBigMessage(t *testing.T) {
+	srv, reads := serve(t)
+	conn, _ := dial(t, srv)
+	half := make([]byte, maxMessageSize/2+1)
+	conn.Write(frame(false, OpText, half, mask))
+	conn.Write(frame(true, 0, half, mask))
+	if r := next(t, reads); !errors.Is(r.err, ErrTooBig) {
+		t.Fatalf("ReadMessage of a message over the limit: err = %v, want ErrTooBig", r.err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	srv, reads := serve(t)
+	conn, br := dial(t, srv)
+
+	payload := binary.BigEndian.AppendUint16(nil, CloseGoingAway)
+	conn.Write(frame(true, OpClose, append(payload, "bye"...), mask))
+	if r := next(t, reads); !errors.Is(r.err, ErrClosed) {
+		t.Fatalf("ReadMessage after a close frame: err = %v, want ErrClosed", r.err)
+	}
+
+	// The close is echoed, then the server's own Close sends another and
+	// hangs up.
+	_, op, echoed := readServerFrame(t, br)
+	if op != OpClose || binary.BigEndian.Uint16(echoed) != CloseGoingAway || string(echoed[2:]) != "bye" {
+		t.Fatalf("reply to a close = op %d %v, want the close echoed", op, echoed)
+	}
+	_, op, closing := readServerFrame(t, br)
+	if op != OpClose || binary.BigEndian.Uint16(closing) != CloseNormal {
+		t.Fatalf("server close = op %d %v, want close %d", op, closing, CloseNormal)
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		t.Fatalf("read after the close = %v, want EOF", err)
+	}
+}